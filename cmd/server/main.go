@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+
 	"github.com/cunex-club/quickattend-backend/internal/config"
 	"github.com/cunex-club/quickattend-backend/internal/database"
 	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
 	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
 	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/router"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/job"
 	"github.com/cunex-club/quickattend-backend/internal/infrastructure/logger"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/notify"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/storage"
 	"github.com/cunex-club/quickattend-backend/internal/repository"
 	"github.com/cunex-club/quickattend-backend/internal/service"
 	"github.com/gofiber/fiber/v2"
@@ -28,13 +33,29 @@ func main() {
 	}
 	log.Info().Msg("Successfully connected to the database")
 
+	blob, err := storage.NewBlobStore(cfg.PhotoConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Photo storage setup failed")
+	}
+
+	notifier, err := notify.New(cfg.NotificationConfig, cfg.LLEConfig, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Notification channel setup failed")
+	}
+
 	repos := repository.NewRepository(db)
-	services := service.NewService(repos, cfg, &log.Logger)
+	services := service.NewService(repos, cfg, &log.Logger, blob, notifier)
 	handlers := handler.NewHandler(&services, &log.Logger)
 
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	job.Every(jobCtx, cfg.RetentionConfig.JobInterval, "retention-purge", &log.Logger, services.Retention.PurgeExpiredRetentionService)
+	job.Every(jobCtx, cfg.NotificationConfig.JobInterval, "notifications", &log.Logger, services.Notification.RunNotificationsService)
+	job.Every(jobCtx, cfg.IdempotencyPurgeInterval, "idempotency-purge", &log.Logger, services.Idempotency.PurgeExpiredIdempotencyKeysService)
+
 	app := fiber.New()
 
-	mw := middleware.NewMiddleware(cfg)
+	mw := middleware.NewMiddleware(cfg, repos)
 	app.Use(
 		mw.Recover(),
 		mw.RequestID(),
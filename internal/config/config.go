@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v10"
 	"github.com/rs/zerolog/log"
 )
@@ -9,8 +11,21 @@ type Config struct {
 	AppEnv    string `env:"APP_ENV" envDefault:"development"`
 	JWTSecret string `env:"JWT_SECRET,required"`
 
-	DatabaseConfig DatabaseConfig
-	LLEConfig      LLEConfig
+	DatabaseConfig     DatabaseConfig
+	LLEConfig          LLEConfig
+	ScanConfig         ScanConfig
+	PhotoConfig        PhotoConfig
+	RetentionConfig    RetentionConfig
+	NotificationConfig NotificationConfig
+	AnnouncementConfig AnnouncementConfig
+	TranscriptConfig   TranscriptConfig
+
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	// how often expired idempotency keys are deleted
+	IdempotencyPurgeInterval time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" envDefault:"1h"`
+
+	// used to build absolute links such as calendar feed URLs, e.g. https://quickattend.example.com
+	PublicURL string `env:"PUBLIC_URL" envDefault:""`
 }
 
 type DatabaseConfig struct {
@@ -27,6 +42,80 @@ type LLEConfig struct {
 	ClientSecret string `env:"LLEClientSecret,required"`
 }
 
+type ScanConfig struct {
+	QRCodeTTL      time.Duration `env:"QR_CODE_TTL" envDefault:"5m"`
+	MaxClockSkew   time.Duration `env:"SCAN_MAX_CLOCK_SKEW" envDefault:"5m"`
+	MaxBatchLength int           `env:"SCAN_MAX_BATCH_LENGTH" envDefault:"500"`
+	// offline scans captured longer ago than this are not accepted when synced
+	MaxOfflineAge time.Duration `env:"SCAN_MAX_OFFLINE_AGE" envDefault:"24h"`
+	// scans are accepted from this long before the event starts until this long after it ends
+	WindowGrace time.Duration `env:"SCAN_WINDOW_GRACE" envDefault:"1h"`
+}
+
+type PhotoConfig struct {
+	// "local" keeps photos under LocalDir, "s3" stores them in an S3-compatible bucket
+	Storage  string `env:"PHOTO_STORAGE" envDefault:"local"`
+	LocalDir string `env:"PHOTO_LOCAL_DIR" envDefault:"./data/photos"`
+
+	S3Endpoint  string `env:"PHOTO_S3_ENDPOINT"`
+	S3Region    string `env:"PHOTO_S3_REGION" envDefault:"us-east-1"`
+	S3Bucket    string `env:"PHOTO_S3_BUCKET"`
+	S3AccessKey string `env:"PHOTO_S3_ACCESS_KEY"`
+	S3SecretKey string `env:"PHOTO_S3_SECRET_KEY"`
+
+	MaxUploadBytes int           `env:"PHOTO_MAX_UPLOAD_BYTES" envDefault:"4194304"`
+	MaxDimension   int           `env:"PHOTO_MAX_DIMENSION" envDefault:"512"`
+	URLTTL         time.Duration `env:"PHOTO_URL_TTL" envDefault:"2m"`
+	CacheSize      int           `env:"PHOTO_CACHE_SIZE" envDefault:"256"`
+}
+
+type RetentionConfig struct {
+	// how often the job looks for events whose retention period has ended
+	JobInterval time.Duration `env:"RETENTION_JOB_INTERVAL" envDefault:"1h"`
+	BatchSize   int           `env:"RETENTION_BATCH_SIZE" envDefault:"50"`
+}
+
+type NotificationConfig struct {
+	// comma separated channels to deliver through: "cunex", "email" and "line",
+	// or "log" to only log notifications during development
+	Channels string `env:"NOTIFY_CHANNELS" envDefault:"log"`
+	// used for users who have not chosen their own channels
+	DefaultChannels string `env:"NOTIFY_DEFAULT_CHANNELS" envDefault:"cunex"`
+
+	// how often the job schedules reminders and delivers pending notifications
+	JobInterval time.Duration `env:"NOTIFY_JOB_INTERVAL" envDefault:"1m"`
+	BatchSize   int           `env:"NOTIFY_BATCH_SIZE" envDefault:"100"`
+	MaxAttempts int           `env:"NOTIFY_MAX_ATTEMPTS" envDefault:"5"`
+	// events that ended longer ago than this get no evaluation notice
+	EvaluationWindow time.Duration `env:"NOTIFY_EVALUATION_WINDOW" envDefault:"24h"`
+
+	CUNEXPushURL string `env:"NOTIFY_CUNEX_PUSH_URL"`
+
+	SMTPHost     string `env:"NOTIFY_SMTP_HOST"`
+	SMTPPort     int    `env:"NOTIFY_SMTP_PORT" envDefault:"587"`
+	SMTPUsername string `env:"NOTIFY_SMTP_USERNAME"`
+	SMTPPassword string `env:"NOTIFY_SMTP_PASSWORD"`
+	SMTPFrom     string `env:"NOTIFY_SMTP_FROM"`
+
+	// the LINE Messaging API, users are reached through the official account they added
+	LinePushURL            string `env:"NOTIFY_LINE_PUSH_URL" envDefault:"https://api.line.me/v2/bot/message/push"`
+	LineChannelAccessToken string `env:"NOTIFY_LINE_CHANNEL_ACCESS_TOKEN"`
+}
+
+type AnnouncementConfig struct {
+	// at most RateLimit announcements per event within any RateWindow
+	RateLimit  int           `env:"ANNOUNCE_RATE_LIMIT" envDefault:"5"`
+	RateWindow time.Duration `env:"ANNOUNCE_RATE_WINDOW" envDefault:"1h"`
+}
+
+type TranscriptConfig struct {
+	// kept apart from JWT_SECRET, so a leaked or rotated session key neither
+	// forges nor voids exported transcripts
+	SigningKey string `env:"TRANSCRIPT_SIGNING_KEY,required"`
+	// how long after export a faculty can verify a transcript
+	SignatureTTL time.Duration `env:"TRANSCRIPT_SIGNATURE_TTL" envDefault:"4320h"`
+}
+
 func Load() *Config {
 	cfg := &Config{}
 	if err := env.Parse(cfg); err != nil {
@@ -21,6 +21,7 @@ func Connect(config config.DatabaseConfig) (*gorm.DB, error) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{TablePrefix: config.Schema + "."},
+		TranslateError: true,
 	})
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to connect to database")
@@ -0,0 +1,7 @@
+package response
+
+type CreateAnnouncementReq struct {
+	// REGISTERED, CHECKED_IN or STAFF
+	Audience string `json:"audience"`
+	Message  string `json:"message"`
+}
@@ -0,0 +1,7 @@
+package response
+
+// null privacy_notice removes the notice, null retention_days keeps data indefinitely
+type UpdateEventPrivacyReq struct {
+	PrivacyNotice *string `json:"privacy_notice"`
+	RetentionDays *uint32 `json:"retention_days"`
+}
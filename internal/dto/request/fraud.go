@@ -0,0 +1,13 @@
+package response
+
+// a null threshold resets the rule to its default
+type FraudRuleReq struct {
+	Rule      string   `json:"rule"`
+	Enabled   bool     `json:"enabled"`
+	Threshold *float64 `json:"threshold"`
+}
+
+// rules left out keep their current setting
+type UpdateFraudRulesReq struct {
+	Rules []FraudRuleReq `json:"rules"`
+}
@@ -0,0 +1,12 @@
+package response
+
+// Replaces the user's notification preferences. Email and LineUserID are kept
+// when null and removed when "". LineUserID is write-only.
+type UpdateNotificationPreferencesReq struct {
+	Channels          []string `json:"channels"`
+	Email             *string  `json:"email"`
+	LineUserID        *string  `json:"line_user_id"`
+	EventReminders    bool     `json:"event_reminders"`
+	EvaluationNotices bool     `json:"evaluation_notices"`
+	StaffNotices      bool     `json:"staff_notices"`
+}
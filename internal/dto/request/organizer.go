@@ -0,0 +1,30 @@
+package response
+
+// role is MANAGER or STAFF, the creator becomes the owner
+type OrganizerMemberReq struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type CreateOrganizerReq struct {
+	Name        string               `json:"name"`
+	Description *string              `json:"description"`
+	Members     []OrganizerMemberReq `json:"members"`
+}
+
+// omitted members are left unchanged, otherwise they replace everyone but the owners
+type UpdateOrganizerReq struct {
+	Name        string                `json:"name"`
+	Description *string               `json:"description"`
+	Members     *[]OrganizerMemberReq `json:"members"`
+}
+
+// null organizer_id detaches the event from its organizer
+type SetEventOrganizerReq struct {
+	OrganizerID *string `json:"organizer_id"`
+}
+
+type SubmitEvaluationReq struct {
+	Rating  int     `json:"rating"`
+	Comment *string `json:"comment"`
+}
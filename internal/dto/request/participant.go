@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+// Comment is the reason for adding the participant by hand and is required
+type ManualCheckinReq struct {
+	RefID               string `json:"ref_id"`
+	Comment             string `json:"comment"`
+	AcceptPrivacyNotice bool   `json:"accept_privacy_notice"`
+}
+
+// checkin_timestamp is required, a participant who was not there is revoked instead
+type CorrectCheckinReq struct {
+	CheckinTimestamp *time.Time `json:"checkin_timestamp"`
+	Comment          string     `json:"comment"`
+}
+
+type RevokeCheckinReq struct {
+	Comment string `json:"comment"`
+}
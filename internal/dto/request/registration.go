@@ -0,0 +1,6 @@
+package response
+
+// the body is optional, it is only needed to accept the event's privacy notice
+type RegisterReq struct {
+	AcceptPrivacyNotice bool `json:"accept_privacy_notice"`
+}
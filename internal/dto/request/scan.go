@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+// x is longitude and y is latitude, matching the order stored in the point column
+type ScanLocation struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// AcceptPrivacyNotice records that the participant accepted the event's notice at the scanner
+type ScanReq struct {
+	Code                string       `json:"code"`
+	ScannedLocation     ScanLocation `json:"scanned_location"`
+	AcceptPrivacyNotice bool         `json:"accept_privacy_notice"`
+}
+
+type BatchScanItem struct {
+	IdempotencyKey      string       `json:"idempotency_key"`
+	Code                string       `json:"code"`
+	ScannedTimestamp    time.Time    `json:"scanned_timestamp"`
+	ScannedLocation     ScanLocation `json:"scanned_location"`
+	AcceptPrivacyNotice bool         `json:"accept_privacy_notice"`
+}
+
+type BatchScanReq struct {
+	Scans []BatchScanItem `json:"scans"`
+}
@@ -0,0 +1,16 @@
+package response
+
+// center and radius_meters are the geofence, both are required to enable self check-in
+type UpdateSelfCheckinReq struct {
+	Enabled         bool          `json:"enabled"`
+	Center          *ScanLocation `json:"center"`
+	RadiusMeters    *uint32       `json:"radius_meters"`
+	RotationSeconds *uint32       `json:"rotation_seconds"`
+}
+
+// Location is the participant's device location, checked against the geofence
+type SelfCheckinReq struct {
+	Code                string       `json:"code"`
+	Location            ScanLocation `json:"location"`
+	AcceptPrivacyNotice bool         `json:"accept_privacy_notice"`
+}
@@ -0,0 +1,54 @@
+package response
+
+import "time"
+
+type SeriesStaffReq struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// StartTime and EndTime are the first occurrence, later ones keep the same duration
+type CreateSeriesReq struct {
+	Name           string           `json:"name"`
+	Organizer      string           `json:"organizer"`
+	Description    *string          `json:"description"`
+	Location       string           `json:"location"`
+	StartTime      time.Time        `json:"start_time"`
+	EndTime        time.Time        `json:"end_time"`
+	RRule          string           `json:"rrule"`
+	AttendenceType string           `json:"attendance_type"`
+	AllowAllToScan bool             `json:"allow_all_to_scan"`
+	EvaluationForm *string          `json:"evaluation_form"`
+	RevealedFields []string         `json:"revealed_fields"`
+	Capacity       *uint32          `json:"capacity"`
+	Whitelist      []uint64         `json:"whitelist"`
+	Faculties      []int            `json:"faculties"`
+	Staff          []SeriesStaffReq `json:"staff"`
+}
+
+// omitted fields are left unchanged
+type UpdateSeriesReq struct {
+	Name           *string           `json:"name"`
+	Organizer      *string           `json:"organizer"`
+	Description    *string           `json:"description"`
+	Location       *string           `json:"location"`
+	EvaluationForm *string           `json:"evaluation_form"`
+	Capacity       *uint32           `json:"capacity"`
+	AttendenceType *string           `json:"attendance_type"`
+	AllowAllToScan *bool             `json:"allow_all_to_scan"`
+	RevealedFields *[]string         `json:"revealed_fields"`
+	Whitelist      *[]uint64         `json:"whitelist"`
+	Faculties      *[]int            `json:"faculties"`
+	Staff          *[]SeriesStaffReq `json:"staff"`
+}
+
+// omitted fields are left unchanged
+type UpdateOccurrenceReq struct {
+	Name           *string    `json:"name"`
+	Description    *string    `json:"description"`
+	Location       *string    `json:"location"`
+	StartTime      *time.Time `json:"start_time"`
+	EndTime        *time.Time `json:"end_time"`
+	EvaluationForm *string    `json:"evaluation_form"`
+	Capacity       *uint32    `json:"capacity"`
+}
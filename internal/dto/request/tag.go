@@ -0,0 +1,11 @@
+package response
+
+type TagReq struct {
+	Slug   string `json:"slug"`
+	NameTH string `json:"name_th"`
+	NameEN string `json:"name_en"`
+}
+
+type SetEventTagsReq struct {
+	Tags []string `json:"tags"`
+}
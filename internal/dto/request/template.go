@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+// omitted options default to true, so everything is copied unless turned off
+type CopyOptionsReq struct {
+	Agenda    *bool `json:"agenda"`
+	Whitelist *bool `json:"whitelist"`
+	Faculties *bool `json:"faculties"`
+	Staff     *bool `json:"staff"`
+	Tags      *bool `json:"tags"`
+}
+
+// StartTime is the start of the new event, the agenda is shifted along with it
+type DuplicateEventReq struct {
+	StartTime time.Time      `json:"start_time"`
+	Name      *string        `json:"name"`
+	Copy      CopyOptionsReq `json:"copy"`
+}
+
+type CreateTemplateReq struct {
+	Name    string         `json:"name"`
+	EventID string         `json:"event_id"`
+	Copy    CopyOptionsReq `json:"copy"`
+}
+
+type CreateEventFromTemplateReq struct {
+	StartTime time.Time `json:"start_time"`
+	Name      *string   `json:"name"`
+}
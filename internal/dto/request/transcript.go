@@ -0,0 +1,11 @@
+package response
+
+// null for both removes the event's activity hours
+type UpdateEventActivityReq struct {
+	ActivityHours    *float64 `json:"activity_hours"`
+	ActivityCategory *string  `json:"activity_category"`
+}
+
+type VerifyTranscriptReq struct {
+	Signature string `json:"signature"`
+}
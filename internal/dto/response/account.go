@@ -0,0 +1,125 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// a copy of everything QuickAttend stores about the user
+type DataExportRes struct {
+	ExportedAt              time.Time                             `json:"exported_at"`
+	Profile                 DataExportProfileRes                  `json:"profile"`
+	EventRoles              []DataExportEventRoleRes              `json:"event_roles"`
+	SeriesRoles             []DataExportSeriesRoleRes             `json:"series_roles"`
+	OrganizerRoles          []DataExportOrganizerRoleRes          `json:"organizer_roles"`
+	Registrations           []DataExportRegistrationRes           `json:"registrations"`
+	Attendance              []DataExportAttendanceRes             `json:"attendance"`
+	Evaluations             []DataExportEvaluationRes             `json:"evaluations"`
+	Ratings                 []DataExportRatingRes                 `json:"ratings"`
+	Templates               []DataExportTemplateRes               `json:"templates"`
+	Photo                   *DataExportPhotoRes                   `json:"photo"`
+	CalendarSubscription    *DataExportCalendarTokenRes           `json:"calendar_subscription"`
+	NotificationPreferences *DataExportNotificationPreferencesRes `json:"notification_preferences"`
+}
+
+type DataExportProfileRes struct {
+	ID          string `json:"id"`
+	RefID       string `json:"ref_id"`
+	TitleTH     string `json:"title_th"`
+	FirstnameTH string `json:"firstname_th"`
+	SurnameTH   string `json:"surname_th"`
+	TitleEN     string `json:"title_en"`
+	FirstnameEN string `json:"firstname_en"`
+	SurnameEN   string `json:"surname_en"`
+	IsAdmin     bool   `json:"is_admin"`
+}
+
+type DataExportEventRoleRes struct {
+	EventID   string `json:"event_id"`
+	EventName string `json:"event_name"`
+	Role      string `json:"role"`
+}
+
+type DataExportSeriesRoleRes struct {
+	SeriesID   string `json:"series_id"`
+	SeriesName string `json:"series_name"`
+	Role       string `json:"role"`
+}
+
+type DataExportOrganizerRoleRes struct {
+	OrganizerID   string `json:"organizer_id"`
+	OrganizerName string `json:"organizer_name"`
+	Role          string `json:"role"`
+}
+
+type DataExportRegistrationRes struct {
+	EventID      string    `json:"event_id"`
+	EventName    string    `json:"event_name"`
+	Status       string    `json:"status"`
+	RegisteredAt time.Time `json:"registered_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type DataExportAttendanceRes struct {
+	EventID          string     `json:"event_id"`
+	EventName        string     `json:"event_name"`
+	EventStartTime   time.Time  `json:"event_start_time"`
+	CheckinTimestamp *time.Time `json:"checkin_timestamp"`
+	ScannedTimestamp time.Time  `json:"scanned_timestamp"`
+	// null for a manual check-in
+	ScannedLocation *ScanLocationRes `json:"scanned_location"`
+	Method          string           `json:"method"`
+	Organization    string           `json:"organization"`
+	Comment         *string          `json:"comment"`
+}
+
+// x is longitude and y is latitude, matching the order stored in the point column
+type ScanLocationRes struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// evaluation forms are hosted outside QuickAttend, this lists the ones the user was given
+type DataExportEvaluationRes struct {
+	EventID        string `json:"event_id"`
+	EventName      string `json:"event_name"`
+	EvaluationForm string `json:"evaluation_form"`
+}
+
+// ratings the user gave events they attended
+type DataExportRatingRes struct {
+	EventID     string    `json:"event_id"`
+	EventName   string    `json:"event_name"`
+	Rating      uint8     `json:"rating"`
+	Comment     *string   `json:"comment"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+type DataExportTemplateRes struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type DataExportPhotoRes struct {
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DataExportCalendarTokenRes struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// the LINE token is a credential, so only whether one is connected is exported
+type DataExportNotificationPreferencesRes struct {
+	Channels          []string  `json:"channels"`
+	Email             *string   `json:"email"`
+	LineConnected     bool      `json:"line_connected"`
+	EventReminders    bool      `json:"event_reminders"`
+	EvaluationNotices bool      `json:"evaluation_notices"`
+	StaffNotices      bool      `json:"staff_notices"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
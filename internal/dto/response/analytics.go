@@ -0,0 +1,44 @@
+package response
+
+import "time"
+
+type AnalyticsCountRes struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+type AnalyticsBucketRes struct {
+	Start    time.Time `json:"start"`
+	Checkins int64     `json:"checkins"`
+}
+
+type AnalyticsPeakRes struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Checkins int64     `json:"checkins"`
+}
+
+// a null scanner_id groups the self check-ins
+type AnalyticsScannerRes struct {
+	ScannerID *string   `json:"scanner_id"`
+	Checkins  int64     `json:"checkins"`
+	FirstScan time.Time `json:"first_scan"`
+	LastScan  time.Time `json:"last_scan"`
+	PerMinute float64   `json:"per_minute"`
+}
+
+// Registrations is null when nobody registered, Peak is null before the first check-in.
+// The timeline covers the event give or take TimelineMarginMinutes, the check-ins
+// outside it are only counted in OutsideTimeline.
+type EventAnalyticsRes struct {
+	TotalCheckins         int64                 `json:"total_checkins"`
+	Methods               []AnalyticsCountRes   `json:"methods"`
+	BucketMinutes         int                   `json:"bucket_minutes"`
+	TimelineMarginMinutes int                   `json:"timeline_margin_minutes"`
+	Timeline              []AnalyticsBucketRes  `json:"timeline"`
+	OutsideTimeline       int64                 `json:"outside_timeline"`
+	Peak                  *AnalyticsPeakRes     `json:"peak"`
+	Organizations         []AnalyticsCountRes   `json:"organizations"`
+	Scanners              []AnalyticsScannerRes `json:"scanners"`
+	Registrations         *RegistrationStatsRes `json:"registrations"`
+}
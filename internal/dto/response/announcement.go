@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type AnnouncementRes struct {
+	ID        string    `json:"id"`
+	Audience  string    `json:"audience"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Recipients is how many users were queued a notification
+type CreateAnnouncementRes struct {
+	AnnouncementRes
+	Recipients int64 `json:"recipients"`
+}
@@ -0,0 +1,22 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Before and After hold only the changed fields, ActorID is null for scheduled jobs
+type AuditLogRes struct {
+	ID         string          `json:"id"`
+	ActorID    *string         `json:"actor_id"`
+	RequestID  *string         `json:"request_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   *string         `json:"target_id"`
+	EventID    *string         `json:"event_id"`
+	SeriesID   *string         `json:"series_id"`
+	Reason     *string         `json:"reason"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -13,4 +13,5 @@ type GetAuthUserRes struct {
 	FirstnameEN string `json:"firstname_en"`
 	SurnameEN   string `json:"surname_en"`
 	TitleEN     string `json:"title_en"`
+	IsAdmin     bool   `json:"is_admin"`
 }
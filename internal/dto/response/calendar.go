@@ -0,0 +1,7 @@
+package response
+
+// the token is only shown here, FeedURL is relative unless PUBLIC_URL is configured
+type CalendarTokenRes struct {
+	Token   string `json:"token"`
+	FeedURL string `json:"feed_url"`
+}
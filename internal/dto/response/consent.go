@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+type ConsentRes struct {
+	EventID     string    `json:"event_id"`
+	NoticeHash  string    `json:"notice_hash"`
+	AcceptedVia string    `json:"accepted_via"`
+	AcceptedAt  time.Time `json:"accepted_at"`
+}
+
+// PurgeAfter is when the retention job anonymises the event's participants
+type EventPrivacyRes struct {
+	PrivacyNotice *string    `json:"privacy_notice"`
+	RetentionDays *uint32    `json:"retention_days"`
+	PurgeAfter    *time.Time `json:"purge_after"`
+}
+
+// EventID is null once the event itself has been deleted
+type RetentionPurgeRes struct {
+	ID            string    `json:"id"`
+	EventID       *string   `json:"event_id"`
+	EventName     string    `json:"event_name"`
+	EventEndTime  time.Time `json:"event_end_time"`
+	RetentionDays uint32    `json:"retention_days"`
+	Participants  int64     `json:"participants"`
+	Registrations int64     `json:"registrations"`
+	Consents      int64     `json:"consents"`
+	PurgedAt      time.Time `json:"purged_at"`
+}
@@ -18,9 +18,30 @@ type GetOneEventRes struct {
 	EndTime         time.Time           `json:"end_time"`
 	Location        string              `json:"location"`
 	TotalRegistered uint16              `json:"total_registered"`
+	TotalAttended   uint16              `json:"total_attended"`
 	EvaluationForm  *string             `json:"evaluation_form"`
 	Role            *string             `json:"role"`
+	Capacity        *uint32             `json:"capacity"`
+	RemainingSeats  *int64              `json:"remaining_seats"`
+	Registration    *string             `json:"registration_status"`
+	SeriesID        *string             `json:"series_id"`
 	Agenda          []GetOneEventAgenda `json:"agenda"`
+	Tags            []TagRes            `json:"tags"`
+
+	// newest first, staff see every announcement and others those addressed to them
+	Announcements []AnnouncementRes `json:"announcements"`
+
+	// PrivacyNoticeAccepted is null when the event has no notice
+	PrivacyNotice         *string    `json:"privacy_notice"`
+	PrivacyNoticeAccepted *bool      `json:"privacy_notice_accepted"`
+	RetentionDays         *uint32    `json:"retention_days"`
+	RetentionPurgedAt     *time.Time `json:"retention_purged_at"`
+	SelfCheckinEnabled    bool       `json:"self_checkin_enabled"`
+	ActivityHours         *float64   `json:"activity_hours"`
+	ActivityCategory      *string    `json:"activity_category"`
+
+	// only shown to the event's organizers
+	Attendance *RegistrationStatsRes `json:"attendance,omitempty"`
 }
 
 type GetEventsRes struct {
@@ -33,4 +54,25 @@ type GetEventsRes struct {
 	Location       string    `json:"location"`
 	Role           *string   `json:"role,omitempty"`
 	EvaluationForm *string   `json:"evaluation_form"`
+
+	// only set in the attended section, an event can be registered for without being attended yet
+	RegistrationStatus *string `json:"registration_status,omitempty"`
+	Attended           *bool   `json:"attended,omitempty"`
+
+	// only set when searching, highlights are [start, end) rune offsets per field
+	Relevance  *float64            `json:"relevance,omitempty"`
+	Highlights map[string][][2]int `json:"highlights,omitempty"`
+}
+
+type GetEventsSectionRes struct {
+	Events     []GetEventsRes `json:"events"`
+	HasNext    bool           `json:"hasNext"`
+	Total      *int64         `json:"total,omitempty"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+}
+
+type GetHomeEventsRes struct {
+	Managed  GetEventsSectionRes `json:"managed"`
+	Attended GetEventsSectionRes `json:"attended"`
+	Discover GetEventsSectionRes `json:"discover"`
 }
@@ -0,0 +1,25 @@
+package response
+
+import "time"
+
+type FraudRuleRes struct {
+	Rule      string  `json:"rule"`
+	Enabled   bool    `json:"enabled"`
+	Threshold float64 `json:"threshold"`
+	Unit      string  `json:"unit"`
+}
+
+// CheckinID is the flagged check-in, as listed by GET /events/:id/participants
+type FraudFlagRes struct {
+	Rule             string    `json:"rule"`
+	CheckinID        string    `json:"checkin_id"`
+	ParticipantID    string    `json:"participant_id"`
+	ScannerID        *string   `json:"scanner_id"`
+	ScannedTimestamp time.Time `json:"scanned_timestamp"`
+	Detail           string    `json:"detail"`
+}
+
+type FraudReportRes struct {
+	Rules []FraudRuleRes `json:"rules"`
+	Flags []FraudFlagRes `json:"flags"`
+}
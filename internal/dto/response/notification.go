@@ -0,0 +1,15 @@
+package response
+
+import "time"
+
+// UpdatedAt is null while the user still has the defaults
+type NotificationPreferencesRes struct {
+	Channels          []string   `json:"channels"`
+	Email             *string    `json:"email"`
+	LineConnected     bool       `json:"line_connected"`
+	EventReminders    bool       `json:"event_reminders"`
+	EvaluationNotices bool       `json:"evaluation_notices"`
+	StaffNotices      bool       `json:"staff_notices"`
+	AvailableChannels []string   `json:"available_channels"`
+	UpdatedAt         *time.Time `json:"updated_at"`
+}
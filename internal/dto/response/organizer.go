@@ -0,0 +1,62 @@
+package response
+
+import "time"
+
+type OrganizerMemberRes struct {
+	UserID      string `json:"user_id"`
+	Role        string `json:"role"`
+	RefID       string `json:"ref_id"`
+	FirstnameTH string `json:"firstname_th"`
+	SurnameTH   string `json:"surname_th"`
+	FirstnameEN string `json:"firstname_en"`
+	SurnameEN   string `json:"surname_en"`
+}
+
+// Role is the requesting user's role, Members is only set for a single organizer
+type OrganizerRes struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Description *string              `json:"description"`
+	Role        *string              `json:"role"`
+	Members     []OrganizerMemberRes `json:"members,omitempty"`
+}
+
+type OrganizerFrequencyRes struct {
+	EventsAttended int64 `json:"events_attended"`
+	Attendees      int64 `json:"attendees"`
+}
+
+type OrganizerReportEventRes struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Registered    int64     `json:"registered"`
+	Checkins      int64     `json:"checkins"`
+	RatingAverage *float64  `json:"rating_average"`
+	Ratings       int64     `json:"ratings"`
+}
+
+// RepeatRate is the share of unique attendees who came to more than one event,
+// null when nobody attended. RatingAverage is null when nobody rated an event.
+type OrganizerReportRes struct {
+	From            *time.Time                `json:"from"`
+	To              *time.Time                `json:"to"`
+	EventCount      int64                     `json:"event_count"`
+	Checkins        int64                     `json:"checkins"`
+	UniqueAttendees int64                     `json:"unique_attendees"`
+	RepeatAttendees int64                     `json:"repeat_attendees"`
+	RepeatRate      *float64                  `json:"repeat_rate"`
+	Frequency       []OrganizerFrequencyRes   `json:"frequency"`
+	Faculties       []AnalyticsCountRes       `json:"faculties"`
+	RatingAverage   *float64                  `json:"rating_average"`
+	Ratings         int64                     `json:"ratings"`
+	Events          []OrganizerReportEventRes `json:"events"`
+}
+
+type EvaluationRes struct {
+	EventID     string    `json:"event_id"`
+	Rating      uint8     `json:"rating"`
+	Comment     *string   `json:"comment"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
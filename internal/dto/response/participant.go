@@ -0,0 +1,25 @@
+package response
+
+import "time"
+
+// Identity fields of a participant. Each one is only set when the event
+// reveals it, or when an owner or manager explicitly overrides that.
+type RevealedParticipantRes struct {
+	RefID        *string `json:"ref_id,omitempty"`
+	NameTH       *string `json:"name_th,omitempty"`
+	NameEN       *string `json:"name_en,omitempty"`
+	Organization *string `json:"organization,omitempty"`
+	// short-lived signed link to the participant's photo
+	PhotoURL *string `json:"photo_url,omitempty"`
+}
+
+// Method is MANUAL for check-ins typed in by staff, Comment then holds their reason
+type EventParticipantRes struct {
+	ID               string     `json:"id"`
+	ParticipantID    string     `json:"participant_id"`
+	CheckinTimestamp *time.Time `json:"checkin_timestamp"`
+	ScannedTimestamp time.Time  `json:"scanned_timestamp"`
+	Method           string     `json:"method"`
+	Comment          *string    `json:"comment"`
+	RevealedParticipantRes
+}
@@ -0,0 +1,11 @@
+package response
+
+import "time"
+
+// URL is signed and expires after PHOTO_URL_TTL
+type PhotoRes struct {
+	URL       string    `json:"url"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
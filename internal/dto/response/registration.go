@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+type RegistrationRes struct {
+	EventID      string    `json:"event_id"`
+	Status       string    `json:"status"`
+	RegisteredAt time.Time `json:"registered_at"`
+
+	// only set while waitlisted, 1 is next in line
+	WaitlistPosition *int64 `json:"waitlist_position,omitempty"`
+}
+
+type RegistrationStatsRes struct {
+	Registered uint32 `json:"registered"`
+	Attended   uint32 `json:"attended"`
+	NoShow     uint32 `json:"no_show"`
+	Waitlisted uint32 `json:"waitlisted"`
+	WalkIns    uint32 `json:"walk_ins"`
+
+	// share of registered users who did not attend, null when nobody registered
+	NoShowRatio *float64 `json:"no_show_ratio"`
+}
@@ -0,0 +1,40 @@
+package response
+
+import "time"
+
+type GetQRCodeRes struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ScanRes struct {
+	ID               string     `json:"id"`
+	ParticipantID    string     `json:"participant_id"`
+	CheckinTimestamp *time.Time `json:"checkin_timestamp"`
+	ScannedTimestamp time.Time  `json:"scanned_timestamp"`
+	Method           string     `json:"method"`
+	Comment          *string    `json:"comment"`
+	RevealedParticipantRes
+}
+
+type BatchScanItemRes struct {
+	IdempotencyKey string   `json:"idempotency_key"`
+	Status         string   `json:"status"`
+	Message        string   `json:"message,omitempty"`
+	Resolution     string   `json:"resolution,omitempty"`
+	Scan           *ScanRes `json:"scan,omitempty"`
+}
+
+type BatchScanSummary struct {
+	Accepted         int `json:"accepted"`
+	AlreadySynced    int `json:"already_synced"`
+	Duplicate        int `json:"duplicate"`
+	Ineligible       int `json:"ineligible"`
+	InvalidSignature int `json:"invalid_signature"`
+	Invalid          int `json:"invalid"`
+}
+
+type BatchScanRes struct {
+	Summary BatchScanSummary   `json:"summary"`
+	Results []BatchScanItemRes `json:"results"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+type SelfCheckinSettingsRes struct {
+	Enabled         bool             `json:"enabled"`
+	Center          *ScanLocationRes `json:"center"`
+	RadiusMeters    *uint32          `json:"radius_meters"`
+	RotationSeconds uint32           `json:"rotation_seconds"`
+}
+
+// the display should fetch a new code at rotates_at, the code itself is accepted until expires_at
+type EventQRCodeRes struct {
+	Code      string    `json:"code"`
+	RotatesAt time.Time `json:"rotates_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
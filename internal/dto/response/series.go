@@ -0,0 +1,35 @@
+package response
+
+import "time"
+
+type SeriesOccurrenceRes struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Location  string    `json:"location"`
+	Detached  bool      `json:"detached"`
+}
+
+type SeriesRes struct {
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Organizer      string                `json:"organizer"`
+	Description    *string               `json:"description"`
+	Location       string                `json:"location"`
+	RRule          string                `json:"rrule"`
+	AttendenceType string                `json:"attendance_type"`
+	AllowAllToScan bool                  `json:"allow_all_to_scan"`
+	EvaluationForm *string               `json:"evaluation_form"`
+	RevealedFields []string              `json:"revealed_fields"`
+	Capacity       *uint32               `json:"capacity"`
+	Occurrences    []SeriesOccurrenceRes `json:"occurrences"`
+}
+
+// identity fields are only set when the series reveals them
+type SeriesAttendanceRes struct {
+	UserID string `json:"user_id"`
+	RevealedParticipantRes
+	Attended int64 `json:"attended"`
+	Held     int64 `json:"held"`
+}
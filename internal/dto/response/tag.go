@@ -0,0 +1,8 @@
+package response
+
+type TagRes struct {
+	ID     string `json:"id"`
+	Slug   string `json:"slug"`
+	NameTH string `json:"name_th"`
+	NameEN string `json:"name_en"`
+}
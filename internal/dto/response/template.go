@@ -0,0 +1,19 @@
+package response
+
+import "time"
+
+type TemplateRes struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	EventName       string    `json:"event_name"`
+	Organizer       string    `json:"organizer"`
+	Location        string    `json:"location"`
+	DurationMinutes int64     `json:"duration_minutes"`
+	AgendaCount     int       `json:"agenda_count"`
+	WhitelistCount  int       `json:"whitelist_count"`
+	FacultyCount    int       `json:"faculty_count"`
+	StaffCount      int       `json:"staff_count"`
+	TagCount        int       `json:"tag_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
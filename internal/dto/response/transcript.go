@@ -0,0 +1,50 @@
+package response
+
+import "time"
+
+type EventActivityRes struct {
+	EventID          string   `json:"event_id"`
+	ActivityHours    *float64 `json:"activity_hours"`
+	ActivityCategory *string  `json:"activity_category"`
+}
+
+// the student's activity hours from every confirmed check-in
+type TranscriptRes struct {
+	UserID      string                  `json:"user_id"`
+	RefID       string                  `json:"ref_id"`
+	TitleTH     string                  `json:"title_th"`
+	FirstnameTH string                  `json:"firstname_th"`
+	SurnameTH   string                  `json:"surname_th"`
+	TitleEN     string                  `json:"title_en"`
+	FirstnameEN string                  `json:"firstname_en"`
+	SurnameEN   string                  `json:"surname_en"`
+	TotalHours  float64                 `json:"total_hours"`
+	Categories  []TranscriptCategoryRes `json:"categories"`
+	Events      []TranscriptEventRes    `json:"events"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
+
+type TranscriptCategoryRes struct {
+	Category string  `json:"category"`
+	Hours    float64 `json:"hours"`
+	Events   int     `json:"events"`
+}
+
+type TranscriptEventRes struct {
+	EventID          string    `json:"event_id"`
+	EventName        string    `json:"event_name"`
+	Organizer        string    `json:"organizer"`
+	StartTime        time.Time `json:"start_time"`
+	EndTime          time.Time `json:"end_time"`
+	CheckinTimestamp time.Time `json:"checkin_timestamp"`
+	ActivityHours    float64   `json:"activity_hours"`
+	ActivityCategory string    `json:"activity_category"`
+}
+
+// Signature is a token over the transcript that POST /transcripts/verify
+// accepts until expires_at, so a faculty can confirm the submitted copy was not edited
+type TranscriptExportRes struct {
+	Transcript TranscriptRes `json:"transcript"`
+	Signature  string        `json:"signature"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+}
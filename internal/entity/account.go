@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// what AuthRequired checks on every request, tokens outlive changes to the user
+type UserAccess struct {
+	Anonymised bool `gorm:"column:anonymised"`
+	IsAdmin    bool `gorm:"column:is_admin"`
+}
+
+// Everything stored about one user, for GET /me/data-export. Events are
+// described by name so the export is readable on its own.
+type UserDataExport struct {
+	Profile                User
+	EventRoles             []GetExportEventRole
+	SeriesRoles            []GetExportSeriesRole
+	Registrations          []GetExportRegistration
+	Attendance             []GetExportAttendance
+	OrganizerRoles         []GetExportOrganizerRole
+	Ratings                []GetExportRating
+	Templates              []EventTemplate
+	Photo                  *UserPhoto
+	CalendarToken          *CalendarToken
+	NotificationPreference *NotificationPreference
+}
+
+type GetExportEventRole struct {
+	EventID   datatypes.UUID `gorm:"column:event_id"`
+	EventName string         `gorm:"column:event_name"`
+	Role      string         `gorm:"column:role"`
+}
+
+type GetExportSeriesRole struct {
+	SeriesID   datatypes.UUID `gorm:"column:series_id"`
+	SeriesName string         `gorm:"column:series_name"`
+	Role       string         `gorm:"column:role"`
+}
+
+type GetExportRegistration struct {
+	EventID      datatypes.UUID `gorm:"column:event_id"`
+	EventName    string         `gorm:"column:event_name"`
+	Status       string         `gorm:"column:status"`
+	RegisteredAt time.Time      `gorm:"column:registered_at"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at"`
+}
+
+// a check-in, with the evaluation form the participant was asked to fill in
+type GetExportAttendance struct {
+	EventID          datatypes.UUID `gorm:"column:event_id"`
+	EventName        string         `gorm:"column:event_name"`
+	EventStartTime   time.Time      `gorm:"column:event_start_time"`
+	CheckinTimestamp *time.Time     `gorm:"column:checkin_timestamp"`
+	ScannedTimestamp time.Time      `gorm:"column:scanned_timestamp"`
+	ScannedLocation  *Point         `gorm:"column:scanned_location"`
+	Method           string         `gorm:"column:method"`
+	Organization     string         `gorm:"column:organization"`
+	Comment          *string        `gorm:"column:comment"`
+	EvaluationForm   *string        `gorm:"column:evaluation_form"`
+}
+
+type GetExportOrganizerRole struct {
+	OrganizerID   datatypes.UUID `gorm:"column:organizer_id"`
+	OrganizerName string         `gorm:"column:organizer_name"`
+	Role          string         `gorm:"column:role"`
+}
+
+type GetExportRating struct {
+	EventID     datatypes.UUID `gorm:"column:event_id"`
+	EventName   string         `gorm:"column:event_name"`
+	Rating      uint8          `gorm:"column:rating"`
+	Comment     *string        `gorm:"column:comment"`
+	SubmittedAt time.Time      `gorm:"column:submitted_at"`
+}
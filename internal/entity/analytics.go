@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// check-ins in one bucket of the timeline, WindowCheckins also counts the
+// buckets that follow it within the peak window
+type AnalyticsBucket struct {
+	BucketStart    time.Time `gorm:"column:bucket_start"`
+	Checkins       int64     `gorm:"column:checkins"`
+	WindowCheckins int64     `gorm:"column:window_checkins"`
+}
+
+type AnalyticsCount struct {
+	Key   string `gorm:"column:key"`
+	Count int64  `gorm:"column:count"`
+}
+
+// a nil ScannerID groups the self check-ins
+type AnalyticsScanner struct {
+	ScannerID *datatypes.UUID `gorm:"column:scanner_id"`
+	Checkins  int64           `gorm:"column:checkins"`
+	FirstScan time.Time       `gorm:"column:first_scan"`
+	LastScan  time.Time       `gorm:"column:last_scan"`
+	PerMinute float64         `gorm:"column:per_minute"`
+}
+
+// OutsideTimeline counts the check-ins too far from the event to be in the Timeline
+type EventAnalytics struct {
+	Methods         []AnalyticsCount
+	Timeline        []AnalyticsBucket
+	OutsideTimeline int64
+	Peak            *AnalyticsBucket
+	Organizations   []AnalyticsCount
+	Scanners        []AnalyticsScanner
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type announcement_audience string
+
+const (
+	AUDIENCE_REGISTERED announcement_audience = "REGISTERED"
+	AUDIENCE_CHECKED_IN announcement_audience = "CHECKED_IN"
+	AUDIENCE_STAFF      announcement_audience = "STAFF"
+)
+
+func (aa *announcement_audience) Scan(value any) error {
+	*aa = announcement_audience(value.(string))
+	return nil
+}
+
+func (aa announcement_audience) Value() (driver.Value, error) {
+	return string(aa), nil
+}
+
+// ====================================================
+
+// A message from the event's organizers to one audience. It reaches the
+// audience through their notification channels and the event's feed.
+type EventAnnouncement struct {
+	ID        datatypes.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID   datatypes.UUID        `gorm:"type:uuid;not null;index:idx_event_announcements_event_id_created_at,priority:1" json:"event_id"`
+	AuthorID  *datatypes.UUID       `gorm:"type:uuid" json:"author_id"`
+	Audience  announcement_audience `gorm:"type:announcement_audience;not null" json:"audience"`
+	Message   string                `gorm:"type:text;not null" json:"message"`
+	CreatedAt time.Time             `gorm:"type:timestamptz;not null;default:now();index:idx_event_announcements_event_id_created_at,priority:2" json:"created_at"`
+
+	Event  Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Author *User `gorm:"foreignKey:AuthorID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
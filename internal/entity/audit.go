@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// One privileged change. Rows are only ever inserted, and they have no foreign
+// keys so the trail outlives the events and users it mentions. Before and After
+// hold only the fields that changed; Before is null for a creation.
+type AuditLog struct {
+	ID         datatypes.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ActorID    *datatypes.UUID `gorm:"type:uuid;index:idx_audit_logs_actor_id_created_at,priority:1" json:"actor_id"`
+	RequestID  *string         `gorm:"type:text" json:"request_id"`
+	Action     string          `gorm:"type:text;not null" json:"action"`
+	TargetType string          `gorm:"type:text;not null" json:"target_type"`
+	TargetID   *string         `gorm:"type:text" json:"target_id"`
+	// why staff made a manual change, such as a manual check-in or a revocation
+	Reason    *string         `gorm:"type:text" json:"reason"`
+	EventID   *datatypes.UUID `gorm:"type:uuid;index:idx_audit_logs_event_id_created_at,priority:1" json:"event_id"`
+	SeriesID  *datatypes.UUID `gorm:"type:uuid;index:idx_audit_logs_series_id_created_at,priority:1" json:"series_id"`
+	Before    datatypes.JSON  `gorm:"type:jsonb" json:"before"`
+	After     datatypes.JSON  `gorm:"type:jsonb" json:"after"`
+	CreatedAt time.Time       `gorm:"type:timestamptz;not null;default:now();index:idx_audit_logs_created_at" json:"created_at"`
+}
+
+// narrows GET /events/:id/audit and the admin audit query, zero values match everything
+type AuditLogFilter struct {
+	// also matches series-wide entries of the event's series
+	EventID    *datatypes.UUID
+	SeriesID   *datatypes.UUID
+	ActorID    *datatypes.UUID
+	Action     string
+	TargetType string
+	From       *time.Time
+	To         *time.Time
+}
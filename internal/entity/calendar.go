@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// One calendar subscription token per user. Only the SHA-256 of the token is
+// stored, the token itself is shown once when it is created or rotated.
+type CalendarToken struct {
+	UserID    datatypes.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	TokenHash string         `gorm:"type:text;not null;unique" json:"-"`
+	CreatedAt time.Time      `gorm:"type:timestamptz;not null" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type consent_source string
+
+const (
+	CONSENT_REGISTRATION consent_source = "REGISTRATION"
+	CONSENT_SCAN         consent_source = "SCAN"
+	CONSENT_SELF         consent_source = "SELF"
+)
+
+func (cs *consent_source) Scan(value any) error {
+	*cs = consent_source(value.(string))
+	return nil
+}
+
+func (cs consent_source) Value() (driver.Value, error) {
+	return string(cs), nil
+}
+
+// ====================================================
+
+// A participant's acceptance of an event's privacy notice. NoticeHash is the
+// SHA-256 of the notice that was accepted, so a changed notice must be accepted again.
+type EventConsent struct {
+	ID          datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID     datatypes.UUID `gorm:"type:uuid;not null;index:unique_event_and_user_consent,unique" json:"event_id"`
+	UserID      datatypes.UUID `gorm:"type:uuid;not null;index:unique_event_and_user_consent,unique" json:"user_id"`
+	NoticeHash  string         `gorm:"type:text;not null" json:"notice_hash"`
+	AcceptedVia consent_source `gorm:"type:consent_source;not null" json:"accepted_via"`
+	AcceptedAt  time.Time      `gorm:"type:timestamptz;not null" json:"accepted_at"`
+
+	Event Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User  User  `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// What the retention job anonymised for one event. The event name is copied
+// so the report still reads after the event is deleted.
+type RetentionPurge struct {
+	ID            datatypes.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID       *datatypes.UUID `gorm:"type:uuid" json:"event_id"`
+	EventName     string          `gorm:"type:text;not null" json:"event_name"`
+	EventEndTime  time.Time       `gorm:"type:timestamptz;not null" json:"event_end_time"`
+	RetentionDays uint32          `gorm:"type:integer;not null" json:"retention_days"`
+	Participants  int64           `gorm:"type:bigint;not null" json:"participants"`
+	Registrations int64           `gorm:"type:bigint;not null" json:"registrations"`
+	Consents      int64           `gorm:"type:bigint;not null" json:"consents"`
+	PurgedAt      time.Time       `gorm:"type:timestamptz;not null;index:idx_retention_purges_purged_at" json:"purged_at"`
+
+	Event *Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+}
@@ -30,6 +30,47 @@ func (at attendence_type) Value() (driver.Value, error) {
 
 // ====================================================
 
+type checkin_method string
+
+const (
+	CHECKIN_SCAN   checkin_method = "SCAN"
+	CHECKIN_MANUAL checkin_method = "MANUAL"
+	CHECKIN_SELF   checkin_method = "SELF"
+)
+
+func (cm *checkin_method) Scan(value any) error {
+	*cm = checkin_method(value.(string))
+	return nil
+}
+
+func (cm checkin_method) Value() (driver.Value, error) {
+	return string(cm), nil
+}
+
+// ====================================================
+
+type activity_category string
+
+const (
+	ACTIVITY_ACADEMIC     activity_category = "ACADEMIC"
+	ACTIVITY_VOLUNTEER    activity_category = "VOLUNTEER"
+	ACTIVITY_SPORTS       activity_category = "SPORTS"
+	ACTIVITY_ARTS_CULTURE activity_category = "ARTS_CULTURE"
+	ACTIVITY_LEADERSHIP   activity_category = "LEADERSHIP"
+	ACTIVITY_OTHER        activity_category = "OTHER"
+)
+
+func (ac *activity_category) Scan(value any) error {
+	*ac = activity_category(value.(string))
+	return nil
+}
+
+func (ac activity_category) Value() (driver.Value, error) {
+	return string(ac), nil
+}
+
+// ====================================================
+
 type participant_data string
 
 const (
@@ -117,17 +158,38 @@ func (p Point) Value() (driver.Value, error) {
 // ====================================================
 
 type Event struct {
-	ID             datatypes.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ID             datatypes.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey;index:idx_events_start_time_id,priority:2" json:"id"`
 	Name           string            `gorm:"type:text;not null;index:idx_events_name_trgm,type:gin" json:"name"`
 	Organizer      string            `gorm:"type:text;not null;index:idx_events_organizer_trgm,type:gin" json:"organizer"`
 	Description    *string           `gorm:"type:text;index:idx_events_description_trgm,type:gin" json:"description"`
-	StartTime      time.Time         `gorm:"type:timestamptz;not null" json:"start_time"`
+	StartTime      time.Time         `gorm:"type:timestamptz;not null;index:idx_events_start_time_id,priority:1" json:"start_time"`
 	EndTime        time.Time         `gorm:"type:timestamptz;not null" json:"end_time"`
 	Location       string            `gorm:"type:text;not null;index:idx_events_location_trgm,type:gin" json:"location"`
 	AttendenceType attendence_type   `gorm:"type:attendence_type;not null" json:"attendance_type"`
 	AllowAllToScan bool              `gorm:"type:bool;not null" json:"allow_all_to_scan"`
 	EvaluationForm *string           `gorm:"type:text;index:idx_events_evaluation_form_trgm,type:gin" json:"evaluation_form"`
 	RevealedFields participant_field `gorm:"type:participant_data[];not null" json:"revealed_fields"`
+	Capacity       *uint32           `gorm:"type:integer;check:capacity > 0" json:"capacity"`
+	SeriesID       *datatypes.UUID   `gorm:"type:uuid;index:idx_events_series_id_start_time,priority:1" json:"series_id"`
+	SeriesDetached bool              `gorm:"type:bool;not null;default:false" json:"series_detached"`
+	// the club or unit that owns the event, Organizer then holds its name
+	OrganizerID *datatypes.UUID `gorm:"type:uuid;index:idx_events_organizer_id_start_time,priority:1" json:"organizer_id"`
+	// participants accept the notice before their first registration or check-in
+	PrivacyNotice *string `gorm:"type:text" json:"privacy_notice"`
+	// days after end_time until check-ins are anonymised, nil keeps them indefinitely
+	RetentionDays     *uint32    `gorm:"type:integer;check:retention_days > 0" json:"retention_days"`
+	RetentionPurgedAt *time.Time `gorm:"type:timestamptz" json:"retention_purged_at"`
+	// participants check themselves in by scanning the event QR code from inside
+	// the geofence, the code changes every SelfCheckinRotationSeconds
+	SelfCheckinEnabled         bool    `gorm:"type:bool;not null;default:false" json:"self_checkin_enabled"`
+	SelfCheckinCenter          *Point  `gorm:"type:point" json:"self_checkin_center"`
+	SelfCheckinRadiusMeters    *uint32 `gorm:"type:integer;check:self_checkin_radius_meters > 0" json:"self_checkin_radius_meters"`
+	SelfCheckinRotationSeconds uint32  `gorm:"type:integer;not null;default:30;check:self_checkin_rotation_seconds > 0" json:"self_checkin_rotation_seconds"`
+	// hours credited to every confirmed attendee's activity transcript, set together with the category
+	ActivityHours    *float64           `gorm:"type:numeric(5,2);check:activity_hours > 0" json:"activity_hours"`
+	ActivityCategory *activity_category `gorm:"type:activity_category" json:"activity_category"`
+
+	Series *EventSeries `gorm:"foreignKey:SeriesID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
 }
 
 type EventWhitelist struct {
@@ -161,16 +223,20 @@ type EventParticipants struct {
 	ID               datatypes.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	EventID          datatypes.UUID  `gorm:"type:uuid;not null;index:unique_event_and_participant,unique" json:"event_id"`
 	CheckinTimestamp *time.Time      `gorm:"type:timestamptz" json:"checkin_timestamp"`
-	ScannedTimestamp time.Time       `gorm:"type:timestamptz;not null" json:"scanned_timestamp"`
+	ScannedTimestamp time.Time       `gorm:"type:timestamptz;not null;index:idx_event_participants_participant_id_scanned_timestamp,priority:2" json:"scanned_timestamp"`
 	Comment          *string         `gorm:"type:text" json:"comment"`
-	ParticipantID    datatypes.UUID  `gorm:"type:uuid;not null;index:unique_event_and_participant,unique" json:"participant_id"`
+	ParticipantID    datatypes.UUID  `gorm:"type:uuid;not null;index:unique_event_and_participant,unique;index:idx_event_participants_participant_id_scanned_timestamp,priority:1" json:"participant_id"`
 	Organization     string          `gorm:"type:text;not null" json:"organization"`
-	ScannedLocation  Point           `gorm:"type:point;not null" json:"scanned_location"`
+	ScannedLocation  *Point          `gorm:"type:point" json:"scanned_location"`
 	ScannerID        *datatypes.UUID `gorm:"type:uuid" json:"scanner_id"`
-
-	Event                      Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	ParticipantRefIDForeignKey User  `gorm:"foreignKey:ParticipantRefID;references:RefID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	ScannerIDForeignKey        User  `gorm:"foreignKey:ScannerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	IdempotencyKey   *string         `gorm:"type:text;index:unique_event_and_idempotency_key,unique" json:"idempotency_key"`
+	// MANUAL check-ins were typed in by staff, their Comment holds the reason.
+	// SELF check-ins were made by the participant from the event QR code and have no ScannerID
+	Method checkin_method `gorm:"type:checkin_method;not null;default:'SCAN'" json:"method"`
+
+	Event                   Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ParticipantIDForeignKey User  `gorm:"foreignKey:ParticipantID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ScannerIDForeignKey     User  `gorm:"foreignKey:ScannerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 }
 
 // ====================================================
@@ -184,15 +250,24 @@ type GetOneEventAgenda struct {
 
 // for retrieving event details and total participant count in GET /events/:id
 type GetOneEventWithTotalCount struct {
-	Name            string    `gorm:"column:name"`
-	Organizer       string    `gorm:"column:organizer"`
-	Description     *string   `gorm:"column:description"`
-	StartTime       time.Time `gorm:"column:start_time"`
-	EndTime         time.Time `gorm:"column:end_time"`
-	Location        string    `gorm:"column:location"`
-	TotalRegistered uint16    `gorm:"column:total_registered"`
-	EvaluationForm  *string   `gorm:"column:evaluation_form"`
-	Role            *string   `gorm:"column:role"`
+	Name               string          `gorm:"column:name"`
+	Organizer          string          `gorm:"column:organizer"`
+	Description        *string         `gorm:"column:description"`
+	StartTime          time.Time       `gorm:"column:start_time"`
+	EndTime            time.Time       `gorm:"column:end_time"`
+	Location           string          `gorm:"column:location"`
+	TotalRegistered    uint16          `gorm:"column:total_registered"`
+	TotalAttended      uint16          `gorm:"column:total_attended"`
+	EvaluationForm     *string         `gorm:"column:evaluation_form"`
+	Role               *string         `gorm:"column:role"`
+	Capacity           *uint32         `gorm:"column:capacity"`
+	SeriesID           *datatypes.UUID `gorm:"column:series_id"`
+	PrivacyNotice      *string         `gorm:"column:privacy_notice"`
+	RetentionDays      *uint32         `gorm:"column:retention_days"`
+	RetentionPurgedAt  *time.Time      `gorm:"column:retention_purged_at"`
+	SelfCheckinEnabled bool            `gorm:"column:self_checkin_enabled"`
+	ActivityHours      *float64        `gorm:"column:activity_hours"`
+	ActivityCategory   *string         `gorm:"column:activity_category"`
 }
 
 // ====================================================
@@ -208,4 +283,82 @@ type GetEventsQueryResult struct {
 	Location       string         `gorm:"column:location"`
 	Role           *string        `gorm:"column:role"`
 	EvaluationForm *string        `gorm:"column:evaluation_form"`
+	Relevance      *float64       `gorm:"column:relevance"`
+
+	// only selected for the attended section
+	RegistrationStatus *string `gorm:"column:registration_status"`
+	Attended           *bool   `gorm:"column:attended"`
+}
+
+// values of the 'status' filter in GET /events
+const (
+	EventStatusUpcoming = "upcoming"
+	EventStatusOngoing  = "ongoing"
+	EventStatusPast     = "past"
+)
+
+// events columns GET /events can be sorted by, always with id as the tie-breaker,
+// relevance is only available when searching
+const (
+	EventSortStartTime = "start_time"
+	EventSortName      = "name"
+	EventSortOrganizer = "organizer"
+	EventSortRelevance = "relevance"
+)
+
+// filters and ordering shared by the managed, attended and discovery queries in GET /events
+type GetEventsFilter struct {
+	Search        string
+	SearchTerms   []string
+	SearchTSQuery *string
+	Tags          []string
+	Status        string
+	From          *time.Time
+	To            *time.Time
+	Organizer     string
+	SortColumn    string
+	Descending    bool
+}
+
+// position after the last row of a page in GET /events, Value holds the sort column
+// of that row (time.Time for start_time, float64 for relevance, string otherwise)
+type GetEventsCursor struct {
+	Value any
+	ID    datatypes.UUID
+}
+
+// Cursor is only used when UseCursor is set, otherwise Page selects an offset
+type GetEventsPageParams struct {
+	Page          int
+	PageSize      int
+	UseCursor     bool
+	Cursor        *GetEventsCursor
+	IncludeTotal  bool
+	IncludeFacets bool
+}
+
+// one page of GET /events, Total and Facets are only set when requested
+type GetEventsPage struct {
+	Events  *[]GetEventsQueryResult
+	Total   *int64
+	HasNext bool
+	Facets  *[]GetEventsTagFacet
+}
+
+// ====================================================
+
+// a check-in joined with the participant's identity, for GET /events/:id/participants
+type GetEventParticipants struct {
+	ID               datatypes.UUID `gorm:"column:id"`
+	ParticipantID    datatypes.UUID `gorm:"column:participant_id"`
+	CheckinTimestamp *time.Time     `gorm:"column:checkin_timestamp"`
+	ScannedTimestamp time.Time      `gorm:"column:scanned_timestamp"`
+	Method           string         `gorm:"column:method"`
+	Comment          *string        `gorm:"column:comment"`
+	RefID            uint64         `gorm:"column:ref_id"`
+	FirstnameTH      string         `gorm:"column:firstname_th"`
+	SurnameTH        string         `gorm:"column:surname_th"`
+	FirstnameEN      string         `gorm:"column:firstname_en"`
+	SurnameEN        string         `gorm:"column:surname_en"`
+	HasPhoto         bool           `gorm:"column:has_photo"`
 }
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type fraud_rule string
+
+const (
+	FRAUD_RAPID_SCANS     fraud_rule = "RAPID_SCANS"
+	FRAUD_REMOTE_LOCATION fraud_rule = "REMOTE_LOCATION"
+	FRAUD_DISTANT_SCANS   fraud_rule = "DISTANT_SCANS"
+	FRAUD_LATE_CHECKIN    fraud_rule = "LATE_CHECKIN"
+)
+
+func (fr *fraud_rule) Scan(value any) error {
+	*fr = fraud_rule(value.(string))
+	return nil
+}
+
+func (fr fraud_rule) Value() (driver.Value, error) {
+	return string(fr), nil
+}
+
+// ====================================================
+
+// An event's setting for one fraud heuristic. Rules without a row are enabled
+// with their default threshold, whose unit depends on the rule.
+type EventFraudRule struct {
+	EventID   datatypes.UUID `gorm:"type:uuid;primaryKey" json:"event_id"`
+	Rule      fraud_rule     `gorm:"type:fraud_rule;primaryKey" json:"rule"`
+	Enabled   bool           `gorm:"type:bool;not null" json:"enabled"`
+	Threshold float64        `gorm:"type:double precision;not null;check:threshold >= 0" json:"threshold"`
+
+	Event Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// ====================================================
+
+// a check-in as the fraud heuristics see it
+type FraudScan struct {
+	ID               datatypes.UUID  `gorm:"column:id"`
+	ParticipantID    datatypes.UUID  `gorm:"column:participant_id"`
+	ScannerID        *datatypes.UUID `gorm:"column:scanner_id"`
+	Method           string          `gorm:"column:method"`
+	CheckinTimestamp *time.Time      `gorm:"column:checkin_timestamp"`
+	ScannedTimestamp time.Time       `gorm:"column:scanned_timestamp"`
+	ScannedLocation  *Point          `gorm:"column:scanned_location"`
+}
+
+// a check-in paired with the same participant's check-in at another event around the same time
+type FraudScanPair struct {
+	ID                    datatypes.UUID  `gorm:"column:id"`
+	ParticipantID         datatypes.UUID  `gorm:"column:participant_id"`
+	ScannerID             *datatypes.UUID `gorm:"column:scanner_id"`
+	ScannedTimestamp      time.Time       `gorm:"column:scanned_timestamp"`
+	ScannedLocation       Point           `gorm:"column:scanned_location"`
+	OtherEventID          datatypes.UUID  `gorm:"column:other_event_id"`
+	OtherScannedTimestamp time.Time       `gorm:"column:other_scanned_timestamp"`
+	OtherScannedLocation  Point           `gorm:"column:other_scanned_location"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// first response to a mutating request sent with an Idempotency-Key header,
+// ResponseStatus is nil while the original request is still being processed
+type IdempotencyKey struct {
+	ID             datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID         datatypes.UUID `gorm:"type:uuid;not null;index:unique_user_and_idempotency_key,unique" json:"user_id"`
+	Key            string         `gorm:"type:text;not null;index:unique_user_and_idempotency_key,unique" json:"key"`
+	RequestHash    string         `gorm:"type:text;not null" json:"request_hash"`
+	ResponseStatus *int           `gorm:"type:int" json:"response_status"`
+	ResponseBody   datatypes.JSON `gorm:"type:jsonb" json:"response_body"`
+	CreatedAt      time.Time      `gorm:"type:timestamptz;not null;default:now()" json:"created_at"`
+	ExpiresAt      time.Time      `gorm:"type:timestamptz;not null;index:idx_idempotency_keys_expires_at" json:"expires_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
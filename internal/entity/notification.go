@@ -0,0 +1,163 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type notification_kind string
+
+const (
+	NOTIFY_REMINDER_24H      notification_kind = "EVENT_REMINDER_24H"
+	NOTIFY_REMINDER_1H       notification_kind = "EVENT_REMINDER_1H"
+	NOTIFY_EVALUATION_OPEN   notification_kind = "EVALUATION_OPEN"
+	NOTIFY_STAFF_ASSIGNED    notification_kind = "STAFF_ASSIGNED"
+	NOTIFY_ANNOUNCEMENT      notification_kind = "ANNOUNCEMENT"
+	NOTIFY_WAITLIST_PROMOTED notification_kind = "WAITLIST_PROMOTED"
+)
+
+func (nk *notification_kind) Scan(value any) error {
+	*nk = notification_kind(value.(string))
+	return nil
+}
+
+func (nk notification_kind) Value() (driver.Value, error) {
+	return string(nk), nil
+}
+
+// ====================================================
+
+type notification_status string
+
+const (
+	NOTIFICATION_PENDING notification_status = "PENDING"
+	NOTIFICATION_SENT    notification_status = "SENT"
+	NOTIFICATION_FAILED  notification_status = "FAILED"
+	NOTIFICATION_SKIPPED notification_status = "SKIPPED"
+)
+
+func (ns *notification_status) Scan(value any) error {
+	*ns = notification_status(value.(string))
+	return nil
+}
+
+func (ns notification_status) Value() (driver.Value, error) {
+	return string(ns), nil
+}
+
+// ====================================================
+
+// a notification_channel[] column, the names match the notify package's channels
+type NotificationChannels []string
+
+func (nc *NotificationChannels) Scan(value any) error {
+	if value == nil {
+		*nc = nil
+		return nil
+	}
+
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		return fmt.Errorf("Error scanning NotificationChannels")
+	}
+
+	str = strings.Trim(str, "{}")
+	if str == "" {
+		*nc = NotificationChannels{}
+		return nil
+	}
+
+	items := strings.Split(str, ",")
+	out := make(NotificationChannels, len(items))
+	for i, item := range items {
+		out[i] = strings.TrimSpace(item)
+	}
+
+	*nc = out
+	return nil
+}
+
+func (nc NotificationChannels) Value() (driver.Value, error) {
+	return fmt.Sprintf("{%s}", strings.Join(nc, ",")), nil
+}
+
+// ====================================================
+
+// An outbox row. DeliveredChannels lists the channels that already accepted it,
+// so a retry after a partial failure does not send it twice on those.
+// AnnouncementID is only set for ANNOUNCEMENT notifications.
+type Notification struct {
+	ID                datatypes.UUID       `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID            datatypes.UUID       `gorm:"type:uuid;not null;index:unique_user_event_and_notification_kind,unique" json:"user_id"`
+	EventID           datatypes.UUID       `gorm:"type:uuid;not null;index:unique_user_event_and_notification_kind,unique;index:idx_notifications_event_id" json:"event_id"`
+	Kind              notification_kind    `gorm:"type:notification_kind;not null;index:unique_user_event_and_notification_kind,unique" json:"kind"`
+	Status            notification_status  `gorm:"type:notification_status;not null;default:'PENDING'" json:"status"`
+	Attempts          int                  `gorm:"type:integer;not null;default:0" json:"attempts"`
+	DeliveredChannels NotificationChannels `gorm:"type:notification_channel[];not null;default:'{}'" json:"delivered_channels"`
+	LastError         *string              `gorm:"type:text" json:"last_error"`
+	CreatedAt         time.Time            `gorm:"type:timestamptz;not null;default:now()" json:"created_at"`
+	NextAttemptAt     time.Time            `gorm:"type:timestamptz;not null;default:now();index:idx_notifications_pending,where:status = 'PENDING'" json:"next_attempt_at"`
+	SentAt            *time.Time           `gorm:"type:timestamptz" json:"sent_at"`
+	AnnouncementID    *datatypes.UUID      `gorm:"type:uuid;index:unique_user_event_and_notification_kind,unique" json:"announcement_id"`
+
+	User         User               `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Event        Event              `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Announcement *EventAnnouncement `gorm:"foreignKey:AnnouncementID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Email and LineUserID are the addresses the EMAIL and LINE channels send to
+type NotificationPreference struct {
+	UserID            datatypes.UUID       `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Channels          NotificationChannels `gorm:"type:notification_channel[];not null" json:"channels"`
+	Email             *string              `gorm:"type:text" json:"email"`
+	LineUserID        *string              `gorm:"type:text" json:"-"`
+	EventReminders    bool                 `gorm:"type:bool;not null;default:true" json:"event_reminders"`
+	EvaluationNotices bool                 `gorm:"type:bool;not null;default:true" json:"evaluation_notices"`
+	StaffNotices      bool                 `gorm:"type:bool;not null;default:true" json:"staff_notices"`
+	UpdatedAt         time.Time            `gorm:"type:timestamptz;not null" json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// ====================================================
+
+// A notification claimed for delivery, with what it takes to write and address it.
+// Users without a notification_preferences row get every kind on the default channels.
+type PendingNotification struct {
+	ID                datatypes.UUID       `gorm:"column:id"`
+	UserID            datatypes.UUID       `gorm:"column:user_id"`
+	EventID           datatypes.UUID       `gorm:"column:event_id"`
+	Kind              string               `gorm:"column:kind"`
+	Attempts          int                  `gorm:"column:attempts"`
+	DeliveredChannels NotificationChannels `gorm:"column:delivered_channels"`
+
+	RefID          uint64    `gorm:"column:ref_id"`
+	EventName      string    `gorm:"column:event_name"`
+	StartTime      time.Time `gorm:"column:start_time"`
+	EndTime        time.Time `gorm:"column:end_time"`
+	Location       string    `gorm:"column:location"`
+	EvaluationForm *string   `gorm:"column:evaluation_form"`
+	// the user's current role in the event, nil once they were removed
+	Role *string `gorm:"column:role"`
+	// the user's current registration status, nil if they never registered
+	RegistrationStatus *string `gorm:"column:registration_status"`
+	// the announcement's text, only set for ANNOUNCEMENT notifications
+	Announcement *string `gorm:"column:announcement"`
+
+	HasPreferences    bool                 `gorm:"column:has_preferences"`
+	Channels          NotificationChannels `gorm:"column:channels"`
+	Email             *string              `gorm:"column:email"`
+	LineUserID        *string              `gorm:"column:line_user_id"`
+	EventReminders    bool                 `gorm:"column:event_reminders"`
+	EvaluationNotices bool                 `gorm:"column:evaluation_notices"`
+	StaffNotices      bool                 `gorm:"column:staff_notices"`
+}
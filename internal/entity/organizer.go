@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// A club or unit that runs events. Events it owns link to it through
+// Event.OrganizerID and show its Name as their Organizer.
+type Organizer struct {
+	ID          datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name        string         `gorm:"type:text;not null;index:unique_organizer_name,unique" json:"name"`
+	Description *string        `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time      `gorm:"type:timestamptz;not null;default:now()" json:"created_at"`
+}
+
+type OrganizerMember struct {
+	ID          datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Role        role           `gorm:"type:role;not null" json:"role"`
+	UserID      datatypes.UUID `gorm:"type:uuid;not null;index:unique_user_and_organizer,unique" json:"user_id"`
+	OrganizerID datatypes.UUID `gorm:"type:uuid;not null;index:unique_user_and_organizer,unique;index:idx_organizer_members_organizer_id" json:"organizer_id"`
+
+	Organizer Organizer `gorm:"foreignKey:OrganizerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User      User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// A participant's own rating of an event they checked in to, from 1 to 5
+type EventEvaluation struct {
+	ID          datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID     datatypes.UUID `gorm:"type:uuid;not null;index:unique_event_and_user_evaluation,unique" json:"event_id"`
+	UserID      datatypes.UUID `gorm:"type:uuid;not null;index:unique_event_and_user_evaluation,unique" json:"user_id"`
+	Rating      uint8          `gorm:"type:smallint;not null;check:rating BETWEEN 1 AND 5" json:"rating"`
+	Comment     *string        `gorm:"type:text" json:"comment"`
+	SubmittedAt time.Time      `gorm:"type:timestamptz;not null" json:"submitted_at"`
+
+	Event Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User  User  `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// ====================================================
+
+// for retrieving the organizers a user belongs to
+type GetMyOrganizer struct {
+	ID          datatypes.UUID `gorm:"column:id"`
+	Name        string         `gorm:"column:name"`
+	Description *string        `gorm:"column:description"`
+	Role        string         `gorm:"column:role"`
+}
+
+type GetOrganizerMember struct {
+	UserID      datatypes.UUID `gorm:"column:user_id"`
+	Role        string         `gorm:"column:role"`
+	RefID       uint64         `gorm:"column:ref_id"`
+	FirstnameTH string         `gorm:"column:firstname_th"`
+	SurnameTH   string         `gorm:"column:surname_th"`
+	FirstnameEN string         `gorm:"column:firstname_en"`
+	SurnameEN   string         `gorm:"column:surname_en"`
+}
+
+// Figures across the organizer's events that started in the report's range.
+// Attendees of events whose data was purged count once per event, since
+// nothing links them to their other check-ins any more.
+type OrganizerReport struct {
+	Events          int64    `gorm:"column:events"`
+	Checkins        int64    `gorm:"column:checkins"`
+	UniqueAttendees int64    `gorm:"column:unique_attendees"`
+	RepeatAttendees int64    `gorm:"column:repeat_attendees"`
+	RatingAverage   *float64 `gorm:"column:rating_average"`
+	Ratings         int64    `gorm:"column:ratings"`
+
+	Frequency []OrganizerReportFrequency
+	Faculties []AnalyticsCount
+	EventRows []OrganizerReportEvent
+}
+
+// how many attendees came to exactly EventsAttended of the events
+type OrganizerReportFrequency struct {
+	EventsAttended int64 `gorm:"column:events_attended"`
+	Attendees      int64 `gorm:"column:attendees"`
+}
+
+type OrganizerReportEvent struct {
+	ID            datatypes.UUID `gorm:"column:id"`
+	Name          string         `gorm:"column:name"`
+	StartTime     time.Time      `gorm:"column:start_time"`
+	EndTime       time.Time      `gorm:"column:end_time"`
+	Registered    int64          `gorm:"column:registered"`
+	Checkins      int64          `gorm:"column:checkins"`
+	RatingAverage *float64       `gorm:"column:rating_average"`
+	Ratings       int64          `gorm:"column:ratings"`
+}
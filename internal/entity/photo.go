@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// A user's uploaded profile photo. The image itself lives in the blob store
+// under ObjectKey, already resized and re-encoded as JPEG.
+type UserPhoto struct {
+	UserID    datatypes.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	ObjectKey string         `gorm:"type:text;not null" json:"-"`
+	Width     int            `gorm:"type:integer;not null" json:"width"`
+	Height    int            `gorm:"type:integer;not null" json:"height"`
+	UpdatedAt time.Time      `gorm:"type:timestamptz;not null" json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type registration_status string
+
+const (
+	REGISTERED registration_status = "REGISTERED"
+	WAITLISTED registration_status = "WAITLISTED"
+	CANCELLED  registration_status = "CANCELLED"
+)
+
+func (rs *registration_status) Scan(value any) error {
+	*rs = registration_status(value.(string))
+	return nil
+}
+
+func (rs registration_status) Value() (driver.Value, error) {
+	return string(rs), nil
+}
+
+// ====================================================
+
+// RegisteredAt is reset when a cancelled registration is renewed,
+// the waitlist is served in RegisteredAt order
+type EventRegistration struct {
+	ID           datatypes.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID      datatypes.UUID      `gorm:"type:uuid;not null;index:unique_event_and_user_registration,unique;index:idx_event_registrations_event_status_time,priority:1" json:"event_id"`
+	UserID       datatypes.UUID      `gorm:"type:uuid;not null;index:unique_event_and_user_registration,unique" json:"user_id"`
+	Status       registration_status `gorm:"type:registration_status;not null;index:idx_event_registrations_event_status_time,priority:2" json:"status"`
+	RegisteredAt time.Time           `gorm:"type:timestamptz;not null;index:idx_event_registrations_event_status_time,priority:3" json:"registered_at"`
+	UpdatedAt    time.Time           `gorm:"type:timestamptz;not null" json:"updated_at"`
+
+	Event Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User  User  `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// ====================================================
+
+type GetRegistrationStats struct {
+	Registered uint32 `gorm:"column:registered"`
+	Attended   uint32 `gorm:"column:attended"`
+	Waitlisted uint32 `gorm:"column:waitlisted"`
+	WalkIns    uint32 `gorm:"column:walk_ins"`
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Occurrences are regular events linked through Event.SeriesID. The settings
+// here are copied into every occurrence when the series is created or edited.
+// FirstStartTime and FirstEndTime are the first occurrence, RRule is the
+// RFC 5545 subset that generated the rest.
+type EventSeries struct {
+	ID             datatypes.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name           string            `gorm:"type:text;not null" json:"name"`
+	Organizer      string            `gorm:"type:text;not null" json:"organizer"`
+	Description    *string           `gorm:"type:text" json:"description"`
+	Location       string            `gorm:"type:text;not null" json:"location"`
+	RRule          string            `gorm:"type:text;not null" json:"rrule"`
+	FirstStartTime time.Time         `gorm:"type:timestamptz;not null" json:"first_start_time"`
+	FirstEndTime   time.Time         `gorm:"type:timestamptz;not null" json:"first_end_time"`
+	AttendenceType attendence_type   `gorm:"type:attendence_type;not null" json:"attendance_type"`
+	AllowAllToScan bool              `gorm:"type:bool;not null" json:"allow_all_to_scan"`
+	EvaluationForm *string           `gorm:"type:text" json:"evaluation_form"`
+	RevealedFields participant_field `gorm:"type:participant_data[];not null" json:"revealed_fields"`
+	Capacity       *uint32           `gorm:"type:integer;check:capacity > 0" json:"capacity"`
+	CreatedAt      time.Time         `gorm:"type:timestamptz;not null" json:"created_at"`
+}
+
+// staff of the whole series, copied into event_users of every occurrence
+type EventSeriesUser struct {
+	ID       datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Role     role           `gorm:"type:role;not null" json:"role"`
+	UserID   datatypes.UUID `gorm:"type:uuid;not null;index:unique_user_and_series,unique" json:"user_id"`
+	SeriesID datatypes.UUID `gorm:"type:uuid;not null;index:unique_user_and_series,unique" json:"series_id"`
+
+	Series EventSeries `gorm:"foreignKey:SeriesID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User   User        `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// ====================================================
+
+// Changes to a whole series. Details are only applied to upcoming occurrences
+// that were not edited on their own, Access (attendance rules, revealed fields,
+// scanning) is applied to every upcoming occurrence. Nil lists are left unchanged.
+type SeriesUpdate struct {
+	Details   map[string]any
+	Access    map[string]any
+	Whitelist *[]uint64
+	Faculties *[]uint8
+	Staff     *[]EventSeriesUser
+}
+
+// for retrieving the series attendance report
+type GetSeriesAttendance struct {
+	UserID      datatypes.UUID `gorm:"column:user_id"`
+	RefID       uint64         `gorm:"column:ref_id"`
+	FirstnameTH string         `gorm:"column:firstname_th"`
+	SurnameTH   string         `gorm:"column:surname_th"`
+	FirstnameEN string         `gorm:"column:firstname_en"`
+	SurnameEN   string         `gorm:"column:surname_en"`
+	HasPhoto    bool           `gorm:"column:has_photo"`
+	Attended    int64          `gorm:"column:attended"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"gorm.io/datatypes"
+)
+
+// event categories such as "volunteer" or "academic", the catalog is managed by admins
+type Tag struct {
+	ID     datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Slug   string         `gorm:"type:text;not null;unique" json:"slug"`
+	NameTH string         `gorm:"type:text;not null;index:idx_tags_name_th_trgm,type:gin" json:"name_th"`
+	NameEN string         `gorm:"type:text;not null;index:idx_tags_name_en_trgm,type:gin" json:"name_en"`
+}
+
+type EventTag struct {
+	ID      datatypes.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID datatypes.UUID `gorm:"type:uuid;not null;index:unique_event_and_tag,unique" json:"event_id"`
+	TagID   datatypes.UUID `gorm:"type:uuid;not null;index:unique_event_and_tag,unique;index:idx_event_tags_tag_id" json:"tag_id"`
+
+	Event Event `gorm:"foreignKey:EventID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tag   Tag   `gorm:"foreignKey:TagID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// ====================================================
+
+// for retrieving tag facet counts in GET /events
+type GetEventsTagFacet struct {
+	Slug   string `gorm:"column:slug"`
+	NameTH string `gorm:"column:name_th"`
+	NameEN string `gorm:"column:name_en"`
+	Count  int64  `gorm:"column:count"`
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// A reusable copy of an event's settings, owned by the user who saved it.
+// Times are stored relative to the event start so a template fits any date.
+type EventTemplate struct {
+	ID        datatypes.UUID                        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OwnerID   datatypes.UUID                        `gorm:"type:uuid;not null;index:idx_event_templates_owner_id" json:"owner_id"`
+	Name      string                                `gorm:"type:text;not null" json:"name"`
+	Data      datatypes.JSONType[EventTemplateData] `gorm:"type:jsonb;not null" json:"data"`
+	CreatedAt time.Time                             `gorm:"type:timestamptz;not null" json:"created_at"`
+	UpdatedAt time.Time                             `gorm:"type:timestamptz;not null" json:"updated_at"`
+
+	Owner User `gorm:"foreignKey:OwnerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Everything needed to create an event again, used both by templates and by
+// POST /events/:id/duplicate. Lists that were not copied are left empty.
+type EventTemplateData struct {
+	Name             string                `json:"name"`
+	Organizer        string                `json:"organizer"`
+	OrganizerID      *string               `json:"organizer_id"`
+	Description      *string               `json:"description"`
+	Location         string                `json:"location"`
+	DurationSec      int64                 `json:"duration_sec"`
+	AttendenceType   string                `json:"attendance_type"`
+	AllowAllToScan   bool                  `json:"allow_all_to_scan"`
+	EvaluationForm   *string               `json:"evaluation_form"`
+	RevealedFields   []string              `json:"revealed_fields"`
+	Capacity         *uint32               `json:"capacity"`
+	PrivacyNotice    *string               `json:"privacy_notice"`
+	RetentionDays    *uint32               `json:"retention_days"`
+	ActivityHours    *float64              `json:"activity_hours"`
+	ActivityCategory *activity_category    `json:"activity_category"`
+	Agenda           []EventTemplateAgenda `json:"agenda"`
+	Whitelist        []uint64              `json:"whitelist"`
+	Faculties        []int                 `json:"faculties"`
+	Staff            []EventTemplateStaff  `json:"staff"`
+	TagIDs           []string              `json:"tag_ids"`
+}
+
+// offsets are seconds from the event start
+type EventTemplateAgenda struct {
+	ActivityName   string `json:"activity_name"`
+	StartOffsetSec int64  `json:"start_offset_sec"`
+	EndOffsetSec   int64  `json:"end_offset_sec"`
+}
+
+type EventTemplateStaff struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// ====================================================
+
+// the parts of an event that can be copied
+type GetEventCopySource struct {
+	Event     Event
+	Agenda    []EventAgenda
+	Whitelist []uint64
+	Faculties []uint8
+	Staff     []EventUser
+	TagIDs    []datatypes.UUID
+}
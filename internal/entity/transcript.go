@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// a confirmed check-in to an event that credits activity hours
+type TranscriptEntry struct {
+	EventID          datatypes.UUID `gorm:"column:event_id"`
+	EventName        string         `gorm:"column:event_name"`
+	Organizer        string         `gorm:"column:organizer"`
+	StartTime        time.Time      `gorm:"column:start_time"`
+	EndTime          time.Time      `gorm:"column:end_time"`
+	CheckinTimestamp time.Time      `gorm:"column:checkin_timestamp"`
+	ActivityHours    float64        `gorm:"column:activity_hours"`
+	ActivityCategory string         `gorm:"column:activity_category"`
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"database/sql/driver"
+	"time"
 
 	"gorm.io/datatypes"
 )
@@ -14,6 +15,9 @@ const (
 	MANAGER role = "MANAGER"
 )
 
+// value of the "role" JWT claim for users with IsAdmin set, not an event role
+const ADMIN = "ADMIN"
+
 func (r *role) Scan(value any) error {
 	*r = role(value.(string))
 	return nil
@@ -34,6 +38,9 @@ type User struct {
 	FirstnameEN string         `gorm:"type:text;not null" json:"firstname_en"`
 	SurnameEN   string         `gorm:"type:text;not null" json:"surname_en"`
 	TitleEN     string         `gorm:"type:text;not null" json:"title_en"`
+	IsAdmin     bool           `gorm:"type:bool;not null;default:false" json:"is_admin"`
+	// set when the user deleted their account, the row is kept for organizers' records
+	AnonymisedAt *time.Time `gorm:"type:timestamptz" json:"anonymised_at"`
 }
 
 type EventUser struct {
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountHandler interface {
+	ExportMyData(c *fiber.Ctx) error
+	DeleteMyAccount(c *fiber.Ctx) error
+}
+
+// JSON by default, ?format=zip for a downloadable archive that includes the photo
+func (h *Handler) ExportMyData(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	switch c.Query("format", "json") {
+	case "json":
+		res, err := h.Service.Account.ExportMyDataService(userIdStr, c.UserContext())
+		if err != nil {
+			return response.SendError(c, err.Status, err.Code, err.Message)
+		}
+		return response.OK(c, res)
+	case "zip":
+		archive, err := h.Service.Account.ExportMyDataArchiveService(userIdStr, c.UserContext())
+		if err != nil {
+			return response.SendError(c, err.Status, err.Code, err.Message)
+		}
+		c.Set(fiber.HeaderContentType, "application/zip")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="quickattend-data-export.zip"`)
+		return c.Send(archive)
+	default:
+		return response.SendError(c, 400, response.ErrBadRequest, "URL query parameter 'format' must be 'json' or 'zip'")
+	}
+}
+
+func (h *Handler) DeleteMyAccount(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	if err := h.Service.Account.DeleteMyAccountService(userIdStr, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type AnnouncementHandler interface {
+	CreateAnnouncement(c *fiber.Ctx) error
+}
+
+func (h *Handler) CreateAnnouncement(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.CreateAnnouncementReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Announcement.CreateAnnouncementService(eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
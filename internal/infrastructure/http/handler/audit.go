@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler interface {
+	GetEventAuditLogs(c *fiber.Ctx) error
+	GetAuditLogs(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetEventAuditLogs(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+	params := c.Queries()
+
+	res, pagination, err := h.Service.Audit.GetEventAuditLogsService(eventIdStr, userIdStr, params, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.Paginated(c, res, *pagination)
+}
+
+func (h *Handler) GetAuditLogs(c *fiber.Ctx) error {
+	params := c.Queries()
+
+	res, pagination, err := h.Service.Audit.GetAuditLogsService(params, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.Paginated(c, res, *pagination)
+}
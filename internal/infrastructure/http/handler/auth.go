@@ -10,6 +10,7 @@ import (
 type AuthHandler interface {
 	AuthCunex(c *fiber.Ctx) error
 	AuthUser(c *fiber.Ctx) error
+	AuthQRCode(c *fiber.Ctx) error
 }
 
 func (h *Handler) AuthCunex(c *fiber.Ctx) error {
@@ -38,3 +39,14 @@ func (h *Handler) AuthUser(c *fiber.Ctx) error {
 
 	return response.OK(c, results)
 }
+
+func (h *Handler) AuthQRCode(c *fiber.Ctx) error {
+	userIDStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Auth.GetQRCodeService(userIDStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+const icsContentType = "text/calendar; charset=utf-8"
+
+type CalendarHandler interface {
+	GetEventICS(c *fiber.Ctx) error
+	CreateCalendarToken(c *fiber.Ctx) error
+	DeleteCalendarToken(c *fiber.Ctx) error
+	GetCalendarFeed(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetEventICS(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	ics, err := h.Service.Calendar.GetEventICSService(eventIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	c.Set(fiber.HeaderContentType, icsContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="event-`+eventIdStr+`.ics"`)
+	return c.Send(ics)
+}
+
+func (h *Handler) CreateCalendarToken(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Calendar.CreateCalendarTokenService(userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) DeleteCalendarToken(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	if err := h.Service.Calendar.DeleteCalendarTokenService(userIdStr, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
+
+func (h *Handler) GetCalendarFeed(c *fiber.Ctx) error {
+	token := c.Params("token")
+
+	ics, err := h.Service.Calendar.GetCalendarFeedService(token, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	c.Set(fiber.HeaderContentType, icsContentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=900")
+	return c.Send(ics)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type ConsentHandler interface {
+	AcceptPrivacyNotice(c *fiber.Ctx) error
+	UpdateEventPrivacy(c *fiber.Ctx) error
+}
+
+func (h *Handler) AcceptPrivacyNotice(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Consent.AcceptPrivacyNoticeService(eventIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateEventPrivacy(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.UpdateEventPrivacyReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Consent.UpdateEventPrivacyService(eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
@@ -8,6 +8,7 @@ import (
 type EventHandler interface {
 	GetOneEventHandler(*fiber.Ctx) error
 	GetEvents(*fiber.Ctx) error
+	GetHomeEvents(*fiber.Ctx) error
 }
 
 func (h *Handler) GetOneEventHandler(c *fiber.Ctx) error {
@@ -29,13 +30,28 @@ func (h *Handler) GetEvents(c *fiber.Ctx) error {
 		return response.SendError(c, 500, response.ErrInternalError, "Failed to assert user_id as a string")
 	}
 
-	res, pagination, err := h.Service.Event.GetEventsService(userIDStr, params, c.UserContext())
+	res, meta, err := h.Service.Event.GetEventsService(userIDStr, params, c.UserContext())
 	if err != nil {
 		return response.SendError(c, err.Status, err.Code, err.Message)
 	}
 
-	if pagination != nil {
-		return response.Paginated(c, res, *pagination)
+	if meta != nil {
+		return response.WithMeta(c, res, *meta)
 	}
 	return response.OK(c, res)
 }
+
+func (h *Handler) GetHomeEvents(c *fiber.Ctx) error {
+	params := c.Queries()
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok {
+		return response.SendError(c, 500, response.ErrInternalError, "Failed to assert user_id as a string")
+	}
+
+	res, err := h.Service.Event.GetHomeEventsService(userIDStr, params, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
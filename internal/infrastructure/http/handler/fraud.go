@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type FraudHandler interface {
+	GetFraudRules(c *fiber.Ctx) error
+	UpdateFraudRules(c *fiber.Ctx) error
+	GetFraudFlags(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetFraudRules(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Fraud.GetFraudRulesService(eventIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateFraudRules(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.UpdateFraudRulesReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Fraud.UpdateFraudRulesService(eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetFraudFlags(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Fraud.GetFraudFlagsService(eventIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
@@ -11,9 +11,29 @@ type Handler struct {
 }
 
 type AllOfHandler struct {
-	HealthCheckHandler HealthCheckHandler
-	AuthHandler        AuthHandler
-	EventHandler       EventHandler
+	HealthCheckHandler  HealthCheckHandler
+	AuthHandler         AuthHandler
+	EventHandler        EventHandler
+	ScanHandler         ScanHandler
+	TagHandler          TagHandler
+	RegistrationHandler RegistrationHandler
+	SeriesHandler       SeriesHandler
+	TemplateHandler     TemplateHandler
+	CalendarHandler     CalendarHandler
+	PhotoHandler        PhotoHandler
+	ParticipantHandler  ParticipantHandler
+	AccountHandler      AccountHandler
+	ConsentHandler      ConsentHandler
+	RetentionHandler    RetentionHandler
+	AuditHandler        AuditHandler
+	SelfCheckinHandler  SelfCheckinHandler
+	FraudHandler        FraudHandler
+	AnalyticsHandler    AnalyticsHandler
+	OrganizerHandler    OrganizerHandler
+	EvaluationHandler   EvaluationHandler
+	TranscriptHandler   TranscriptHandler
+	NotificationHandler NotificationHandler
+	AnnouncementHandler AnnouncementHandler
 }
 
 func NewHandler(srv *service.AllOfService, logger *zerolog.Logger) *AllOfHandler {
@@ -22,8 +42,28 @@ func NewHandler(srv *service.AllOfService, logger *zerolog.Logger) *AllOfHandler
 		Logger:  logger,
 	}
 	return &AllOfHandler{
-		HealthCheckHandler: h,
-		AuthHandler:        h,
-		EventHandler:       h,
+		HealthCheckHandler:  h,
+		AuthHandler:         h,
+		EventHandler:        h,
+		ScanHandler:         h,
+		TagHandler:          h,
+		RegistrationHandler: h,
+		SeriesHandler:       h,
+		TemplateHandler:     h,
+		CalendarHandler:     h,
+		PhotoHandler:        h,
+		ParticipantHandler:  h,
+		AccountHandler:      h,
+		ConsentHandler:      h,
+		RetentionHandler:    h,
+		AuditHandler:        h,
+		SelfCheckinHandler:  h,
+		FraudHandler:        h,
+		AnalyticsHandler:    h,
+		OrganizerHandler:    h,
+		EvaluationHandler:   h,
+		TranscriptHandler:   h,
+		NotificationHandler: h,
+		AnnouncementHandler: h,
 	}
 }
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type NotificationHandler interface {
+	GetNotificationPreferences(c *fiber.Ctx) error
+	UpdateNotificationPreferences(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetNotificationPreferences(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Notification.GetNotificationPreferencesService(userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.UpdateNotificationPreferencesReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Notification.UpdateNotificationPreferencesService(userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type OrganizerHandler interface {
+	CreateOrganizer(c *fiber.Ctx) error
+	GetMyOrganizers(c *fiber.Ctx) error
+	GetOrganizer(c *fiber.Ctx) error
+	UpdateOrganizer(c *fiber.Ctx) error
+	SetEventOrganizer(c *fiber.Ctx) error
+	GetOrganizerReport(c *fiber.Ctx) error
+}
+
+func (h *Handler) CreateOrganizer(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.CreateOrganizerReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Organizer.CreateOrganizerService(userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetMyOrganizers(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Organizer.GetMyOrganizersService(userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetOrganizer(c *fiber.Ctx) error {
+	organizerIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Organizer.GetOrganizerService(organizerIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateOrganizer(c *fiber.Ctx) error {
+	organizerIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.UpdateOrganizerReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Organizer.UpdateOrganizerService(organizerIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) SetEventOrganizer(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.SetEventOrganizerReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	if err := h.Service.Organizer.SetEventOrganizerService(eventIdStr, userIdStr, &req, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
+
+// JSON by default, ?format=csv for a spreadsheet of the per-event rows
+func (h *Handler) GetOrganizerReport(c *fiber.Ctx) error {
+	organizerIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+	params := c.Queries()
+
+	switch c.Query("format", "json") {
+	case "json":
+		res, err := h.Service.Organizer.GetOrganizerReportService(organizerIdStr, userIdStr, params, c.UserContext())
+		if err != nil {
+			return response.SendError(c, err.Status, err.Code, err.Message)
+		}
+		return response.OK(c, res)
+	case "csv":
+		report, err := h.Service.Organizer.GetOrganizerReportCSVService(organizerIdStr, userIdStr, params, c.UserContext())
+		if err != nil {
+			return response.SendError(c, err.Status, err.Code, err.Message)
+		}
+		c.Set(fiber.HeaderContentType, "text/csv")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="organizer-report.csv"`)
+		return c.Send(report)
+	default:
+		return response.SendError(c, 400, response.ErrBadRequest, "URL query parameter 'format' must be 'json' or 'csv'")
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type ParticipantHandler interface {
+	GetEventParticipants(c *fiber.Ctx) error
+	ManualCheckIn(c *fiber.Ctx) error
+	CorrectCheckIn(c *fiber.Ctx) error
+	RevokeCheckIn(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetEventParticipants(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+	params := c.Queries()
+
+	res, pagination, err := h.Service.Participant.GetEventParticipantsService(eventIdStr, userIdStr, params, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.Paginated(c, res, *pagination)
+}
+
+func (h *Handler) ManualCheckIn(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.ManualCheckinReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Participant.ManualCheckInService(eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) CorrectCheckIn(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	participantIdStr := c.Params("participantId")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.CorrectCheckinReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Participant.CorrectCheckInService(eventIdStr, participantIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) RevokeCheckIn(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	participantIdStr := c.Params("participantId")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.RevokeCheckinReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	if err := h.Service.Participant.RevokeCheckInService(eventIdStr, participantIdStr, userIdStr, &req, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"io"
+
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PhotoHandler interface {
+	UploadPhoto(c *fiber.Ctx) error
+	GetMyPhoto(c *fiber.Ctx) error
+	DeletePhoto(c *fiber.Ctx) error
+	GetSignedPhoto(c *fiber.Ctx) error
+}
+
+// expects multipart/form-data with the image in the "photo" field
+func (h *Handler) UploadPhoto(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	fileHeader, formErr := c.FormFile("photo")
+	if formErr != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "multipart field 'photo' is required")
+	}
+	file, openErr := fileHeader.Open()
+	if openErr != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "failed to read 'photo'")
+	}
+	defer file.Close()
+	data, readErr := io.ReadAll(file)
+	if readErr != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "failed to read 'photo'")
+	}
+
+	res, err := h.Service.Photo.UploadPhotoService(userIdStr, data, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetMyPhoto(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Photo.GetMyPhotoService(userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) DeletePhoto(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	if err := h.Service.Photo.DeletePhotoService(userIdStr, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
+
+func (h *Handler) GetSignedPhoto(c *fiber.Ctx) error {
+	photo, err := h.Service.Photo.GetSignedPhotoService(c.Params("userId"), c.Query("expires"), c.Query("signature"), c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=60")
+	return c.Send(photo)
+}
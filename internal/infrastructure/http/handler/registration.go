@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type RegistrationHandler interface {
+	Register(c *fiber.Ctx) error
+	CancelRegistration(c *fiber.Ctx) error
+}
+
+func (h *Handler) Register(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	// the body is optional, it only carries the privacy notice acceptance
+	var req dtoReq.RegisterReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+		}
+	}
+
+	res, err := h.Service.Registration.RegisterService(eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) CancelRegistration(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Registration.CancelRegistrationService(eventIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RetentionHandler interface {
+	GetRetentionPurges(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetRetentionPurges(c *fiber.Ctx) error {
+	params := c.Queries()
+
+	res, pagination, err := h.Service.Retention.GetRetentionPurgesService(params, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.Paginated(c, res, *pagination)
+}
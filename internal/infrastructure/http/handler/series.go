@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type SeriesHandler interface {
+	CreateSeries(c *fiber.Ctx) error
+	GetSeries(c *fiber.Ctx) error
+	UpdateSeries(c *fiber.Ctx) error
+	UpdateOccurrence(c *fiber.Ctx) error
+	GetSeriesAttendance(c *fiber.Ctx) error
+}
+
+func (h *Handler) CreateSeries(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.CreateSeriesReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Series.CreateSeriesService(userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetSeries(c *fiber.Ctx) error {
+	seriesIdStr := c.Params("id")
+
+	res, err := h.Service.Series.GetSeriesService(seriesIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateSeries(c *fiber.Ctx) error {
+	seriesIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.UpdateSeriesReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Series.UpdateSeriesService(seriesIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateOccurrence(c *fiber.Ctx) error {
+	seriesIdStr := c.Params("id")
+	eventIdStr := c.Params("eventId")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.UpdateOccurrenceReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Series.UpdateOccurrenceService(seriesIdStr, eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetSeriesAttendance(c *fiber.Ctx) error {
+	seriesIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+	params := c.Queries()
+
+	res, pagination, err := h.Service.Series.GetSeriesAttendanceService(seriesIdStr, userIdStr, params, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.Paginated(c, res, *pagination)
+}
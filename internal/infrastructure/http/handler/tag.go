@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type TagHandler interface {
+	GetTags(c *fiber.Ctx) error
+	CreateTag(c *fiber.Ctx) error
+	UpdateTag(c *fiber.Ctx) error
+	DeleteTag(c *fiber.Ctx) error
+	SetEventTags(c *fiber.Ctx) error
+}
+
+func (h *Handler) GetTags(c *fiber.Ctx) error {
+	res, err := h.Service.Tag.GetTagsService(c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) CreateTag(c *fiber.Ctx) error {
+	var req dtoReq.TagReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Tag.CreateTagService(&req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) UpdateTag(c *fiber.Ctx) error {
+	tagIdStr := c.Params("id")
+
+	var req dtoReq.TagReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Tag.UpdateTagService(tagIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) DeleteTag(c *fiber.Ctx) error {
+	tagIdStr := c.Params("id")
+
+	if err := h.Service.Tag.DeleteTagService(tagIdStr, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
+
+func (h *Handler) SetEventTags(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+
+	var req dtoReq.SetEventTagsReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Tag.SetEventTagsService(eventIdStr, userIdStr, role, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+)
+
+type TemplateHandler interface {
+	DuplicateEvent(c *fiber.Ctx) error
+	GetTemplates(c *fiber.Ctx) error
+	GetTemplate(c *fiber.Ctx) error
+	CreateTemplate(c *fiber.Ctx) error
+	DeleteTemplate(c *fiber.Ctx) error
+	CreateEventFromTemplate(c *fiber.Ctx) error
+}
+
+func (h *Handler) DuplicateEvent(c *fiber.Ctx) error {
+	eventIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.DuplicateEventReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Template.DuplicateEventService(eventIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetTemplates(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Template.GetTemplatesService(userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) GetTemplate(c *fiber.Ctx) error {
+	templateIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	res, err := h.Service.Template.GetTemplateService(templateIdStr, userIdStr, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) CreateTemplate(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.CreateTemplateReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Template.CreateTemplateService(userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
+
+func (h *Handler) DeleteTemplate(c *fiber.Ctx) error {
+	templateIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	if err := h.Service.Template.DeleteTemplateService(templateIdStr, userIdStr, c.UserContext()); err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, nil)
+}
+
+func (h *Handler) CreateEventFromTemplate(c *fiber.Ctx) error {
+	templateIdStr := c.Params("id")
+	userIdStr := c.Locals("user_id").(string)
+
+	var req dtoReq.CreateEventFromTemplateReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendError(c, 400, response.ErrBadRequest, "invalid JSON body")
+	}
+
+	res, err := h.Service.Template.CreateEventFromTemplateService(templateIdStr, userIdStr, &req, c.UserContext())
+	if err != nil {
+		return response.SendError(c, err.Status, err.Code, err.Message)
+	}
+
+	return response.OK(c, res)
+}
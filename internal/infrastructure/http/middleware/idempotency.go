@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	ErrIdempotencyKeyMismatch   = "IDEMPOTENCY_KEY_MISMATCH"
+	ErrIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// --- Idempotency Middleware ---
+// Must run after AuthRequired: keys are scoped to the user_id claim.
+// The first response to a POST/PUT/PATCH carrying an Idempotency-Key header
+// is stored and replayed for retries with the same key and body.
+// Server errors are not stored, so the client can retry them. A key whose
+// response could not be stored, or whose handler panicked, is released the
+// same way rather than left in progress until it expires.
+func (m *Middleware) Idempotency() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		key := strings.TrimSpace(c.Get("Idempotency-Key"))
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return response.SendError(c, fiber.StatusBadRequest, response.ErrBadRequest, "Idempotency-Key header is too long")
+		}
+
+		userIDStr, _ := c.Locals("user_id").(string)
+		if uuid.Validate(userIDStr) != nil {
+			return c.Next()
+		}
+		userID := datatypes.UUID(datatypes.BinUUIDFromString(userIDStr))
+
+		ctx := c.UserContext()
+		hash := requestHash(c)
+		record := &entity.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hash,
+			ExpiresAt:   time.Now().Add(m.cfg.IdempotencyKeyTTL),
+		}
+
+		for attempt := 0; ; attempt++ {
+			createErr := m.repo.Idempotency.CreateIdempotencyKey(record, ctx)
+			if createErr == nil {
+				break
+			}
+			if !errors.Is(createErr, gorm.ErrDuplicatedKey) {
+				log.Error().Err(createErr).Str("function", "IdempotencyRepository.CreateIdempotencyKey").Msg("Internal DB error")
+				return response.SendError(c, fiber.StatusInternalServerError, response.ErrInternalError, "Internal DB error")
+			}
+
+			existing, getErr := m.repo.Idempotency.GetIdempotencyKey(userID, key, ctx)
+			if getErr != nil {
+				if errors.Is(getErr, gorm.ErrRecordNotFound) && attempt == 0 {
+					continue
+				}
+				log.Error().Err(getErr).Str("function", "IdempotencyRepository.GetIdempotencyKey").Msg("Internal DB error")
+				return response.SendError(c, fiber.StatusInternalServerError, response.ErrInternalError, "Internal DB error")
+			}
+
+			// expired keys are reclaimed once, then treated as new
+			if existing.ExpiresAt.Before(time.Now()) && attempt == 0 {
+				if delErr := m.repo.Idempotency.DeleteIdempotencyKey(existing.ID, ctx); delErr != nil {
+					log.Error().Err(delErr).Str("function", "IdempotencyRepository.DeleteIdempotencyKey").Msg("Internal DB error")
+					return response.SendError(c, fiber.StatusInternalServerError, response.ErrInternalError, "Internal DB error")
+				}
+				continue
+			}
+
+			if existing.RequestHash != hash {
+				return response.SendError(c, fiber.StatusUnprocessableEntity, ErrIdempotencyKeyMismatch,
+					"Idempotency-Key was already used with a different request")
+			}
+			if existing.ResponseStatus == nil {
+				return response.SendError(c, fiber.StatusConflict, ErrIdempotencyKeyInProgress,
+					"A request with this Idempotency-Key is still being processed")
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(*existing.ResponseStatus).Send(existing.ResponseBody)
+		}
+
+		saved := false
+		defer func() {
+			recovered := recover()
+			if !saved {
+				// the request may have been cancelled, the key must be released regardless
+				if delErr := m.repo.Idempotency.DeleteIdempotencyKey(record.ID, context.WithoutCancel(ctx)); delErr != nil {
+					log.Error().Err(delErr).Str("function", "IdempotencyRepository.DeleteIdempotencyKey").Msg("Internal DB error")
+				}
+			}
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			return err
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		if saveErr := m.repo.Idempotency.SaveIdempotencyResponse(record.ID, status, body, ctx); saveErr != nil {
+			log.Error().Err(saveErr).Str("function", "IdempotencyRepository.SaveIdempotencyResponse").Msg("Internal DB error")
+			return nil
+		}
+		saved = true
+		return nil
+	}
+}
+
+// fingerprint of the request, so that a key reused with a different body is rejected
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
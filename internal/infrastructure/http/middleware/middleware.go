@@ -6,23 +6,29 @@ import (
 	"time"
 
 	"github.com/cunex-club/quickattend-backend/internal/config"
+	"github.com/cunex-club/quickattend-backend/internal/entity"
 	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/requestctx"
+	"github.com/cunex-club/quickattend-backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"gorm.io/datatypes"
 )
 
 // เก็บ config
 type Middleware struct {
-	cfg *config.Config
+	cfg  *config.Config
+	repo repository.AllRepo
 }
 
 // constructor
-func NewMiddleware(cfg *config.Config) *Middleware {
-	return &Middleware{cfg: cfg}
+func NewMiddleware(cfg *config.Config, repo repository.AllRepo) *Middleware {
+	return &Middleware{cfg: cfg, repo: repo}
 }
 
 func (m *Middleware) Recover() fiber.Handler {
@@ -39,7 +45,8 @@ func (m *Middleware) CORS() fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, Idempotency-Key",
+		ExposeHeaders:    "Idempotent-Replayed",
 		AllowCredentials: false,
 	})
 }
@@ -115,11 +122,46 @@ func (m *Middleware) AuthRequired() fiber.Handler {
 			return response.SendError(c, fiber.StatusUnauthorized, response.ErrUnauthorized, "Missing user_id claim")
 		}
 
-		role, _ := claimString(claims, "role")
+		// Tokens do not expire, so the users row is checked on every request: a
+		// deleted (anonymised) account is refused, and admin rights are taken from
+		// is_admin rather than the token's role claim so revoking them takes effect.
+		role := ""
+		if uuid.Validate(userID) == nil {
+			access, accessErr := m.repo.Account.GetUserAccess(datatypes.UUID(datatypes.BinUUIDFromString(userID)), c.UserContext())
+			if accessErr != nil {
+				log.Error().Err(accessErr).Str("function", "AccountRepository.GetUserAccess").Msg("Internal DB error")
+				return response.SendError(c, fiber.StatusInternalServerError, response.ErrInternalError, "Internal DB error")
+			}
+			if access != nil && access.Anonymised {
+				return response.SendError(c, fiber.StatusUnauthorized, response.ErrUnauthorized, "This account has been deleted")
+			}
+			if access != nil && access.IsAdmin {
+				role = entity.ADMIN
+			}
+		}
 
 		c.Locals("user_id", userID)
 		c.Locals("role", role)
 
+		// services only see the context, this is how the audit log learns the actor
+		requestID, _ := c.Locals("requestid").(string)
+		c.SetUserContext(requestctx.With(c.UserContext(), requestctx.Info{
+			UserID:    userID,
+			RequestID: requestID,
+		}))
+
+		return c.Next()
+	}
+}
+
+// --- Admin Middleware ---
+// Must run after AuthRequired, which stores the user's current role
+func (m *Middleware) AdminRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		if role != entity.ADMIN {
+			return response.SendError(c, fiber.StatusForbidden, response.ErrForbidden, "Admin access required")
+		}
 		return c.Next()
 	}
 }
@@ -7,6 +7,7 @@ const (
 	ErrUnauthorized  = "UNAUTHORIZED"
 	ErrForbidden     = "FORBIDDEN"
 	ErrNotFound      = "NOT_FOUND"
+	ErrConflict      = "CONFLICT"
 	ErrValidation    = "VALIDATION_ERROR"
 	ErrInternalError = "INTERNAL_SERVER_ERROR"
 )
@@ -25,13 +26,27 @@ type APIError struct {
 
 type Meta struct {
 	Pagination *Pagination `json:"pagination"`
+	NextCursor *string     `json:"next_cursor,omitempty"`
+	Facets     *Facets     `json:"facets,omitempty"`
 }
 
+// Page is omitted in cursor mode and Total is omitted when the count was not requested
 type Pagination struct {
-	Page     int   `json:"page"`
-	PageSize int   `json:"pageSize"`
-	Total    int64 `json:"total"`
-	HasNext  bool  `json:"hasNext"`
+	Page     *int   `json:"page,omitempty"`
+	PageSize int    `json:"pageSize"`
+	Total    *int64 `json:"total,omitempty"`
+	HasNext  bool   `json:"hasNext"`
+}
+
+type Facets struct {
+	Tags []TagFacet `json:"tags"`
+}
+
+type TagFacet struct {
+	Slug   string `json:"slug"`
+	NameTH string `json:"name_th"`
+	NameEN string `json:"name_en"`
+	Count  int64  `json:"count"`
 }
 
 // --- Success Helpers -----------
@@ -52,6 +67,14 @@ func Paginated(c *fiber.Ctx, data any, pag Pagination) error {
 	})
 }
 
+func WithMeta(c *fiber.Ctx, data any, meta Meta) error {
+	return c.Status(fiber.StatusOK).JSON(APIResponse{
+		Data:  data,
+		Error: nil,
+		Meta:  &meta,
+	})
+}
+
 // --- Error Helper ----------
 
 func SendError(c *fiber.Ctx, status int, code string, msg string) error {
@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	admin := r.Group("/admin", mw.AuthRequired(), mw.AdminRequired())
+	admin.Get("/retention-purges", h.RetentionHandler.GetRetentionPurges)
+	admin.Get("/audit", h.AuditHandler.GetAuditLogs)
+}
@@ -14,4 +14,5 @@ func AuthRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middlewa
 
 	protected := auth.Group("", mw.AuthRequired())
 	protected.Get("/user", h.AuthHandler.AuthUser)
+	protected.Get("/qr", h.AuthHandler.AuthQRCode)
 }
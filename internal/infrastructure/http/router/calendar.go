@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// The feed is polled by calendar apps that cannot send the Bearer JWT,
+// so it is public and authorised by the token in its URL instead.
+func CalendarRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	calendar := r.Group("/calendar")
+	calendar.Get("/:token.ics", h.CalendarHandler.GetCalendarFeed)
+
+	token := calendar.Group("/token", mw.AuthRequired(), mw.Idempotency())
+	token.Post("/", h.CalendarHandler.CreateCalendarToken)
+	token.Delete("/", h.CalendarHandler.DeleteCalendarToken)
+}
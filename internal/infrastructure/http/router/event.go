@@ -7,6 +7,33 @@ import (
 )
 
 func EventRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
-	event := r.Group("/events", mw.AuthRequired())
+	event := r.Group("/events", mw.AuthRequired(), mw.Idempotency())
+	event.Get("/", h.EventHandler.GetEvents)
+	event.Get("/home", h.EventHandler.GetHomeEvents)
 	event.Get("/:id", h.EventHandler.GetOneEventHandler)
+	event.Get("/:id/ics", h.CalendarHandler.GetEventICS)
+	event.Post("/:id/scans", h.ScanHandler.ScanParticipant)
+	event.Post("/:id/scans/batch", h.ScanHandler.BatchScan)
+	event.Post("/:id/self-checkin", h.SelfCheckinHandler.SelfCheckIn)
+	event.Put("/:id/self-checkin", h.SelfCheckinHandler.UpdateSelfCheckin)
+	event.Get("/:id/self-checkin/code", h.SelfCheckinHandler.GetEventQRCode)
+	event.Get("/:id/participants", h.ParticipantHandler.GetEventParticipants)
+	event.Post("/:id/participants", h.ParticipantHandler.ManualCheckIn)
+	event.Patch("/:id/participants/:participantId", h.ParticipantHandler.CorrectCheckIn)
+	event.Delete("/:id/participants/:participantId", h.ParticipantHandler.RevokeCheckIn)
+	event.Get("/:id/audit", h.AuditHandler.GetEventAuditLogs)
+	event.Get("/:id/analytics", h.AnalyticsHandler.GetEventAnalytics)
+	event.Get("/:id/fraud-flags", h.FraudHandler.GetFraudFlags)
+	event.Get("/:id/fraud-rules", h.FraudHandler.GetFraudRules)
+	event.Put("/:id/fraud-rules", h.FraudHandler.UpdateFraudRules)
+	event.Put("/:id/organizer", h.OrganizerHandler.SetEventOrganizer)
+	event.Put("/:id/evaluation", h.EvaluationHandler.SubmitEvaluation)
+	event.Put("/:id/activity", h.TranscriptHandler.UpdateEventActivity)
+	event.Post("/:id/announcements", h.AnnouncementHandler.CreateAnnouncement)
+	event.Put("/:id/tags", h.TagHandler.SetEventTags)
+	event.Post("/:id/register", h.RegistrationHandler.Register)
+	event.Delete("/:id/register", h.RegistrationHandler.CancelRegistration)
+	event.Post("/:id/consent", h.ConsentHandler.AcceptPrivacyNotice)
+	event.Put("/:id/privacy", h.ConsentHandler.UpdateEventPrivacy)
+	event.Post("/:id/duplicate", h.TemplateHandler.DuplicateEvent)
 }
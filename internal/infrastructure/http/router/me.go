@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// the signed in user's own records and their PDPA data subject rights
+func MeRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	me := r.Group("/me", mw.AuthRequired(), mw.Idempotency())
+	me.Get("/data-export", h.AccountHandler.ExportMyData)
+	me.Get("/transcript", h.TranscriptHandler.GetMyTranscript)
+	me.Get("/transcript/export", h.TranscriptHandler.ExportMyTranscript)
+	me.Get("/notification-preferences", h.NotificationHandler.GetNotificationPreferences)
+	me.Put("/notification-preferences", h.NotificationHandler.UpdateNotificationPreferences)
+	me.Delete("/", h.AccountHandler.DeleteMyAccount)
+}
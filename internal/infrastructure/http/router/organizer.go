@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func OrganizerRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	organizer := r.Group("/organizers", mw.AuthRequired(), mw.Idempotency())
+	organizer.Post("/", h.OrganizerHandler.CreateOrganizer)
+	organizer.Get("/", h.OrganizerHandler.GetMyOrganizers)
+	organizer.Get("/:id", h.OrganizerHandler.GetOrganizer)
+	organizer.Put("/:id", h.OrganizerHandler.UpdateOrganizer)
+	organizer.Get("/:id/report", h.OrganizerHandler.GetOrganizerReport)
+}
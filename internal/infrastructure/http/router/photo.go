@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Photos are read through signed, short-lived links handed out by the scan
+// endpoint, so the read route does not require the Bearer JWT.
+func PhotoRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	photo := r.Group("/photos")
+
+	me := photo.Group("/me", mw.AuthRequired(), mw.Idempotency())
+	me.Get("/", h.PhotoHandler.GetMyPhoto)
+	me.Put("/", h.PhotoHandler.UploadPhoto)
+	me.Delete("/", h.PhotoHandler.DeletePhoto)
+
+	photo.Get("/:userId", h.PhotoHandler.GetSignedPhoto)
+}
@@ -12,4 +12,13 @@ func SetupRoutes(app *fiber.App, h *handler.AllOfHandler, mw *middleware.Middlew
 	AuthRoutes(api, h, mw)
 	HealthCheckRoutes(api, h)
 	EventRoutes(api, h, mw)
+	TagRoutes(api, h, mw)
+	SeriesRoutes(api, h, mw)
+	OrganizerRoutes(api, h, mw)
+	TemplateRoutes(api, h, mw)
+	CalendarRoutes(api, h, mw)
+	PhotoRoutes(api, h, mw)
+	MeRoutes(api, h, mw)
+	TranscriptRoutes(api, h)
+	AdminRoutes(api, h, mw)
 }
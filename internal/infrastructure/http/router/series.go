@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SeriesRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	series := r.Group("/series", mw.AuthRequired(), mw.Idempotency())
+	series.Post("/", h.SeriesHandler.CreateSeries)
+	series.Get("/:id", h.SeriesHandler.GetSeries)
+	series.Put("/:id", h.SeriesHandler.UpdateSeries)
+	series.Get("/:id/attendance", h.SeriesHandler.GetSeriesAttendance)
+	series.Put("/:id/occurrences/:eventId", h.SeriesHandler.UpdateOccurrence)
+}
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TagRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	tag := r.Group("/tags", mw.AuthRequired(), mw.Idempotency())
+	tag.Get("/", h.TagHandler.GetTags)
+
+	admin := tag.Group("", mw.AdminRequired())
+	admin.Post("/", h.TagHandler.CreateTag)
+	admin.Put("/:id", h.TagHandler.UpdateTag)
+	admin.Delete("/:id", h.TagHandler.DeleteTag)
+}
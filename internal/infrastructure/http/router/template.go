@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TemplateRoutes(r fiber.Router, h *handler.AllOfHandler, mw *middleware.Middleware) {
+	template := r.Group("/templates", mw.AuthRequired(), mw.Idempotency())
+	template.Get("/", h.TemplateHandler.GetTemplates)
+	template.Post("/", h.TemplateHandler.CreateTemplate)
+	template.Get("/:id", h.TemplateHandler.GetTemplate)
+	template.Delete("/:id", h.TemplateHandler.DeleteTemplate)
+	template.Post("/:id/events", h.TemplateHandler.CreateEventFromTemplate)
+}
@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Faculty staff checking a submitted transcript may not have a CU NEX
+// account, so verification is public and authorised by the signature itself.
+func TranscriptRoutes(r fiber.Router, h *handler.AllOfHandler) {
+	transcript := r.Group("/transcripts")
+	transcript.Post("/verify", h.TranscriptHandler.VerifyTranscript)
+}
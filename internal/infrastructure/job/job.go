@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Every runs fn once right away and then on every tick of interval until ctx
+// is cancelled. A run that panics is logged and does not stop later runs.
+func Every(ctx context.Context, interval time.Duration, name string, logger *zerolog.Logger, fn func(ctx context.Context)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(ctx, name, logger, fn)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func run(ctx context.Context, name string, logger *zerolog.Logger, fn func(ctx context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Str("job", name).Interface("panic", r).Msg("Job panicked")
+		}
+	}()
+
+	start := time.Now()
+	fn(ctx)
+	logger.Debug().Str("job", name).Dur("took", time.Since(start)).Msg("Job finished")
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CUNEXChannel sends push notifications to the CU NEX app, authenticated with
+// the same client credentials as token validation.
+type CUNEXChannel struct {
+	url          string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+func NewCUNEXChannel(url string, clientID string, clientSecret string) (*CUNEXChannel, error) {
+	if url == "" || clientID == "" || clientSecret == "" {
+		return nil, errors.New("notify: NOTIFY_CUNEX_PUSH_URL, LLEClientId and LLEClientSecret are required for cunex notifications")
+	}
+	return &CUNEXChannel{
+		url:          url,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (c *CUNEXChannel) Send(msg Message, ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{
+		"ref_id": msg.To.RefID,
+		"title":  msg.Title,
+		"body":   msg.Body,
+		"link":   msg.Link,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("ClientId", c.clientID)
+	req.Header.Set("ClientSecret", c.clientSecret)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("notify: CU NEX push responded %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LineChannel pushes text messages through the LINE Messaging API from the
+// official account, to the LINE user ID the user connected in their preferences.
+// Users only receive them while they are friends with the account.
+type LineChannel struct {
+	url         string
+	accessToken string
+	client      *http.Client
+}
+
+func NewLineChannel(pushURL string, accessToken string) (*LineChannel, error) {
+	if pushURL == "" || accessToken == "" {
+		return nil, errors.New("notify: NOTIFY_LINE_PUSH_URL and NOTIFY_LINE_CHANNEL_ACCESS_TOKEN are required for line notifications")
+	}
+	return &LineChannel{url: pushURL, accessToken: accessToken, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+func (l *LineChannel) Send(msg Message, ctx context.Context) error {
+	if msg.To.LineUserID == nil {
+		return ErrNoAddress
+	}
+
+	text := msg.Title + "\n" + msg.Body
+	if msg.Link != "" {
+		text += "\n" + msg.Link
+	}
+	body, err := json.Marshal(map[string]any{
+		"to":       *msg.To.LineUserID,
+		"messages": []map[string]string{{"type": "text", "text": text}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+l.accessToken)
+
+	res, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("notify: LINE Messaging API responded %d", res.StatusCode)
+	}
+	return nil
+}
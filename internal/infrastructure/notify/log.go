@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// LogChannel writes messages to the log instead of delivering them, for development.
+type LogChannel struct {
+	name   string
+	logger *zerolog.Logger
+}
+
+func NewLogChannel(name string, logger *zerolog.Logger) *LogChannel {
+	return &LogChannel{name: name, logger: logger}
+}
+
+func (l *LogChannel) Send(msg Message, ctx context.Context) error {
+	l.logger.Info().
+		Str("channel", l.name).
+		Str("user_id", msg.To.UserID).
+		Str("title", msg.Title).
+		Str("body", msg.Body).
+		Str("link", msg.Link).
+		Msg("Notification")
+	return nil
+}
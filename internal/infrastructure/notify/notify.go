@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cunex-club/quickattend-backend/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// channel names, matching the notification_channel enum
+const (
+	CUNEX = "CUNEX"
+	EMAIL = "EMAIL"
+	LINE  = "LINE"
+)
+
+// ErrNoAddress means the recipient has not given the address a channel needs,
+// such as an email address, so the channel is skipped for them.
+var ErrNoAddress = errors.New("notify: recipient has no address for this channel")
+
+type Recipient struct {
+	UserID     string
+	RefID      string
+	Email      *string
+	LineUserID *string
+}
+
+type Message struct {
+	To    Recipient
+	Title string
+	Body  string
+	// an absolute link to open, empty when there is none
+	Link string
+}
+
+// Channel delivers messages to one kind of destination such as push or email.
+type Channel interface {
+	Send(msg Message, ctx context.Context) error
+}
+
+// Notifier holds the channels selected by NOTIFY_CHANNELS, keyed by name, and
+// the channels used for users who have not chosen their own.
+type Notifier struct {
+	Channels map[string]Channel
+	Defaults []string
+}
+
+// New builds the channels selected by NOTIFY_CHANNELS. With "log" every
+// channel only logs what it would have sent.
+func New(cfg config.NotificationConfig, lle config.LLEConfig, logger *zerolog.Logger) (*Notifier, error) {
+	defaults, err := ParseChannelNames(cfg.DefaultChannels)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := map[string]Channel{}
+	for _, name := range strings.Split(cfg.Channels, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+		case "log":
+			for _, channel := range []string{CUNEX, EMAIL, LINE} {
+				channels[channel] = NewLogChannel(channel, logger)
+			}
+		case "cunex":
+			cunex, err := NewCUNEXChannel(cfg.CUNEXPushURL, lle.ClientId, lle.ClientSecret)
+			if err != nil {
+				return nil, err
+			}
+			channels[CUNEX] = cunex
+		case "email":
+			email, err := NewSMTPChannel(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+			if err != nil {
+				return nil, err
+			}
+			channels[EMAIL] = email
+		case "line":
+			line, err := NewLineChannel(cfg.LinePushURL, cfg.LineChannelAccessToken)
+			if err != nil {
+				return nil, err
+			}
+			channels[LINE] = line
+		default:
+			return nil, fmt.Errorf("notify: unknown NOTIFY_CHANNELS entry %q", name)
+		}
+	}
+	return &Notifier{Channels: channels, Defaults: defaults}, nil
+}
+
+// ParseChannelNames turns a comma separated list such as "cunex,email" into channel names
+func ParseChannelNames(list string) ([]string, error) {
+	names := []string{}
+	for _, name := range strings.Split(list, ",") {
+		switch upper := strings.ToUpper(strings.TrimSpace(name)); upper {
+		case "":
+		case CUNEX, EMAIL, LINE:
+			names = append(names, upper)
+		default:
+			return nil, fmt.Errorf("notify: unknown channel %q", name)
+		}
+	}
+	return names, nil
+}
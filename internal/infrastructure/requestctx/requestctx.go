@@ -0,0 +1,21 @@
+package requestctx
+
+import "context"
+
+type contextKey struct{}
+
+// Info identifies who made a request, for services that record it such as the audit log.
+type Info struct {
+	UserID    string
+	RequestID string
+}
+
+func With(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// From returns the zero Info for work that is not tied to a request, such as scheduled jobs.
+func From(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// CachedStore keeps the most recently read objects in memory, so a photo
+// shown to several scanners in a row is only fetched from the store once.
+type CachedStore struct {
+	store    BlobStore
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+func NewCachedStore(store BlobStore, capacity int) *CachedStore {
+	return &CachedStore{
+		store:    store,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *CachedStore) Put(key string, data []byte, contentType string, ctx context.Context) error {
+	if err := c.store.Put(key, data, contentType, ctx); err != nil {
+		return err
+	}
+	c._Set(key, data)
+	return nil
+}
+
+func (c *CachedStore) Get(key string, ctx context.Context) ([]byte, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		data := element.Value.(*cacheEntry).data
+		c.mu.Unlock()
+		return data, nil
+	}
+	c.mu.Unlock()
+
+	data, err := c.store.Get(key, ctx)
+	if err != nil {
+		return nil, err
+	}
+	c._Set(key, data)
+	return data, nil
+}
+
+func (c *CachedStore) Delete(key string, ctx context.Context) error {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	return c.store.Delete(key, ctx)
+}
+
+func (c *CachedStore) _Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).data = data
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, data: data})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
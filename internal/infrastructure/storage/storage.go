@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cunex-club/quickattend-backend/internal/config"
+)
+
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+// BlobStore keeps binary objects such as participant photos under string keys.
+type BlobStore interface {
+	Put(key string, data []byte, contentType string, ctx context.Context) error
+	Get(key string, ctx context.Context) ([]byte, error)
+	Delete(key string, ctx context.Context) error
+}
+
+// NewBlobStore builds the store selected by PHOTO_STORAGE, wrapped in an in-memory cache.
+func NewBlobStore(cfg config.PhotoConfig) (BlobStore, error) {
+	var store BlobStore
+	switch cfg.Storage {
+	case "local":
+		local, err := NewLocalStore(cfg.LocalDir)
+		if err != nil {
+			return nil, err
+		}
+		store = local
+	case "s3":
+		s3, err := NewS3Store(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		store = s3
+	default:
+		return nil, fmt.Errorf("storage: unknown PHOTO_STORAGE %q", cfg.Storage)
+	}
+
+	if cfg.CacheSize > 0 {
+		store = NewCachedStore(store, cfg.CacheSize)
+	}
+	return store, nil
+}
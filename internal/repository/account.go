@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type AccountRepository interface {
+	GetUserAccess(userID datatypes.UUID, ctx context.Context) (*entity.UserAccess, error)
+	GetUserDataExport(userID datatypes.UUID, ctx context.Context) (*entity.UserDataExport, error)
+	CountSoleOwnerships(userID datatypes.UUID, ctx context.Context) (events int64, series int64, organizers int64, err error)
+	GetOpenRegistrationEventIDs(userID datatypes.UUID, ctx context.Context) ([]datatypes.UUID, error)
+	AnonymiseUser(userID datatypes.UUID, ctx context.Context) error
+}
+
+// nil when the user does not exist
+func (r *repository) GetUserAccess(userID datatypes.UUID, ctx context.Context) (*entity.UserAccess, error) {
+	var access []entity.UserAccess
+	err := r._DB(ctx).Model(&entity.User{}).
+		Select("anonymised_at IS NOT NULL AS anonymised", "is_admin").
+		Where("id = ?", userID).
+		Limit(1).
+		Scan(&access).Error
+	if err != nil || len(access) == 0 {
+		return nil, err
+	}
+	return &access[0], nil
+}
+
+func (r *repository) GetUserDataExport(userID datatypes.UUID, ctx context.Context) (*entity.UserDataExport, error) {
+	tx := r._DB(ctx)
+	export := entity.UserDataExport{}
+
+	if err := tx.First(&export.Profile, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	err := tx.Table("event_users eu").
+		Select("eu.event_id", "e.name AS event_name", "eu.role").
+		Joins("JOIN events e ON e.id = eu.event_id").
+		Where("eu.user_id = ?", userID).
+		Order("e.start_time").
+		Scan(&export.EventRoles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Table("event_series_users su").
+		Select("su.series_id", "es.name AS series_name", "su.role").
+		Joins("JOIN event_series es ON es.id = su.series_id").
+		Where("su.user_id = ?", userID).
+		Order("es.name").
+		Scan(&export.SeriesRoles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Table("event_registrations r").
+		Select("r.event_id", "e.name AS event_name", "r.status", "r.registered_at", "r.updated_at").
+		Joins("JOIN events e ON e.id = r.event_id").
+		Where("r.user_id = ?", userID).
+		Order("r.registered_at").
+		Scan(&export.Registrations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Table("event_participants ep").
+		Select("ep.event_id", "e.name AS event_name", "e.start_time AS event_start_time",
+			"ep.checkin_timestamp", "ep.scanned_timestamp", "ep.scanned_location", "ep.method",
+			"ep.organization", "ep.comment", "e.evaluation_form").
+		Joins("JOIN events e ON e.id = ep.event_id").
+		Where("ep.participant_id = ?", userID).
+		Order("ep.scanned_timestamp").
+		Scan(&export.Attendance).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Table("organizer_members om").
+		Select("om.organizer_id", "o.name AS organizer_name", "om.role").
+		Joins("JOIN organizers o ON o.id = om.organizer_id").
+		Where("om.user_id = ?", userID).
+		Order("o.name").
+		Scan(&export.OrganizerRoles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Table("event_evaluations ev").
+		Select("ev.event_id", "e.name AS event_name", "ev.rating", "ev.comment", "ev.submitted_at").
+		Joins("JOIN events e ON e.id = ev.event_id").
+		Where("ev.user_id = ?", userID).
+		Order("ev.submitted_at").
+		Scan(&export.Ratings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("owner_id = ?", userID).Order("created_at").Find(&export.Templates).Error; err != nil {
+		return nil, err
+	}
+
+	var photo entity.UserPhoto
+	photoErr := tx.First(&photo, "user_id = ?", userID).Error
+	if photoErr == nil {
+		export.Photo = &photo
+	} else if !errors.Is(photoErr, gorm.ErrRecordNotFound) {
+		return nil, photoErr
+	}
+
+	var token entity.CalendarToken
+	tokenErr := tx.First(&token, "user_id = ?", userID).Error
+	if tokenErr == nil {
+		export.CalendarToken = &token
+	} else if !errors.Is(tokenErr, gorm.ErrRecordNotFound) {
+		return nil, tokenErr
+	}
+
+	var preference entity.NotificationPreference
+	preferenceErr := tx.First(&preference, "user_id = ?", userID).Error
+	if preferenceErr == nil {
+		export.NotificationPreference = &preference
+	} else if !errors.Is(preferenceErr, gorm.ErrRecordNotFound) {
+		return nil, preferenceErr
+	}
+
+	return &export, nil
+}
+
+// events, series and organizers the user owns with no other owner, which would be left ownerless
+func (r *repository) CountSoleOwnerships(userID datatypes.UUID, ctx context.Context) (int64, int64, int64, error) {
+	tx := r._DB(ctx)
+
+	var events int64
+	err := tx.Table("event_users eu").
+		Where("eu.user_id = ? AND eu.role = ?", userID, entity.OWNER).
+		Where(`NOT EXISTS (SELECT 1 FROM event_users o
+			WHERE o.event_id = eu.event_id AND o.role = ? AND o.user_id <> eu.user_id)`, entity.OWNER).
+		Count(&events).Error
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var series int64
+	err = tx.Table("event_series_users su").
+		Where("su.user_id = ? AND su.role = ?", userID, entity.OWNER).
+		Where(`NOT EXISTS (SELECT 1 FROM event_series_users o
+			WHERE o.series_id = su.series_id AND o.role = ? AND o.user_id <> su.user_id)`, entity.OWNER).
+		Count(&series).Error
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var organizers int64
+	err = tx.Table("organizer_members om").
+		Where("om.user_id = ? AND om.role = ?", userID, entity.OWNER).
+		Where(`NOT EXISTS (SELECT 1 FROM organizer_members o
+			WHERE o.organizer_id = om.organizer_id AND o.role = ? AND o.user_id <> om.user_id)`, entity.OWNER).
+		Count(&organizers).Error
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return events, series, organizers, nil
+}
+
+// events that have not ended where the user still holds a seat or waitlist entry
+func (r *repository) GetOpenRegistrationEventIDs(userID datatypes.UUID, ctx context.Context) ([]datatypes.UUID, error) {
+	var eventIDs []datatypes.UUID
+	err := r._DB(ctx).Table("event_registrations r").
+		Joins("JOIN events e ON e.id = r.event_id").
+		Where("r.user_id = ? AND r.status <> ? AND e.end_time > now()", userID, entity.CANCELLED).
+		Pluck("r.event_id", &eventIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return eventIDs, nil
+}
+
+// Erases the user's personal data but keeps the users row, so organizers'
+// check-in and registration counts stay intact. Check-ins and registrations
+// stay linked to a nameless user with a ref id no CU account can have.
+// Returns gorm.ErrRecordNotFound if the user is missing or already anonymised.
+func (r *repository) AnonymiseUser(userID datatypes.UUID, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var user entity.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&user, "id = ? AND anonymised_at IS NULL", userID).Error
+		if err != nil {
+			return err
+		}
+
+		personal := []struct {
+			model any
+			where string
+			arg   any
+		}{
+			{&entity.EventUser{}, "user_id = ?", userID},
+			{&entity.EventSeriesUser{}, "user_id = ?", userID},
+			{&entity.OrganizerMember{}, "user_id = ?", userID},
+			{&entity.EventTemplate{}, "owner_id = ?", userID},
+			{&entity.UserPhoto{}, "user_id = ?", userID},
+			{&entity.CalendarToken{}, "user_id = ?", userID},
+			{&entity.IdempotencyKey{}, "user_id = ?", userID},
+			{&entity.NotificationPreference{}, "user_id = ?", userID},
+			{&entity.Notification{}, "user_id = ?", userID},
+			{&entity.EventWhitelist{}, "attendee_ref_id = ?", user.RefID},
+		}
+		for _, rows := range personal {
+			if err := tx.Where(rows.where, rows.arg).Delete(rows.model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := r._StripTemplateUser(tx, userID, user.RefID); err != nil {
+			return err
+		}
+
+		err = tx.Model(&entity.EventParticipants{}).
+			Where("participant_id = ?", userID).
+			Updates(map[string]any{"comment": nil, "idempotency_key": nil}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&entity.EventEvaluation{}).
+			Where("user_id = ?", userID).
+			Update("comment", nil).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{
+				"ref_id":        gorm.Expr("nextval('anonymised_ref_id_seq')"),
+				"firstname_th":  "",
+				"surname_th":    "",
+				"title_th":      "",
+				"firstname_en":  "",
+				"surname_en":    "",
+				"title_en":      "",
+				"is_admin":      false,
+				"anonymised_at": time.Now(),
+			}).Error
+	})
+}
+
+// removes the user from the staff and whitelist of other users' templates, so
+// an event created from one later does not bring them back
+func (r *repository) _StripTemplateUser(tx *gorm.DB, userID datatypes.UUID, refID uint64) error {
+	staff, err := json.Marshal([]map[string]string{{"user_id": userID.String()}})
+	if err != nil {
+		return err
+	}
+	whitelist, err := json.Marshal([]uint64{refID})
+	if err != nil {
+		return err
+	}
+
+	var templates []entity.EventTemplate
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("data->'staff' @> ?::jsonb OR data->'whitelist' @> ?::jsonb", string(staff), string(whitelist)).
+		Find(&templates).Error
+	if err != nil {
+		return err
+	}
+
+	for _, template := range templates {
+		data := template.Data.Data()
+		data.Staff = slices.DeleteFunc(data.Staff, func(member entity.EventTemplateStaff) bool {
+			return member.UserID == userID.String()
+		})
+		data.Whitelist = slices.DeleteFunc(data.Whitelist, func(id uint64) bool {
+			return id == refID
+		})
+		err := tx.Model(&entity.EventTemplate{}).
+			Where("id = ?", template.ID).
+			Update("data", datatypes.NewJSONType(data)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type AnalyticsRepository interface {
+	GetEventAnalytics(eventID datatypes.UUID, bucket time.Duration, peakWindow time.Duration, margin time.Duration, ctx context.Context) (*entity.EventAnalytics, error)
+}
+
+// confirmed check-ins within margin of the event's start and end, the timeline
+// is limited to these so a check-in corrected to a far-off time cannot stretch it
+const analyticsInTimeline = `ep.checkin_timestamp >= e.start_time - make_interval(secs => @margin)
+	AND ep.checkin_timestamp < e.end_time + make_interval(secs => @margin)`
+
+// check-ins per bucket from the first to the last one, empty buckets included,
+// with a running count over the peak window. Buckets are aligned to the epoch.
+const analyticsTimelineQuery = `
+	WITH counted AS (
+		SELECT to_timestamp(floor(extract(epoch FROM ep.checkin_timestamp) / @bucket) * @bucket) AS bucket_start,
+			COUNT(*) AS checkins
+		FROM event_participants ep
+		JOIN events e ON e.id = ep.event_id
+		WHERE ep.event_id = @event AND ep.checkin_timestamp IS NOT NULL AND ` + analyticsInTimeline + `
+		GROUP BY 1
+	), timeline AS (
+		SELECT s.bucket_start, COALESCE(c.checkins, 0) AS checkins
+		FROM (SELECT MIN(bucket_start) AS first, MAX(bucket_start) AS last FROM counted) r,
+			generate_series(r.first, r.last, make_interval(secs => @bucket)) AS s(bucket_start)
+		LEFT JOIN counted c ON c.bucket_start = s.bucket_start
+	)
+	SELECT bucket_start, checkins,
+		SUM(checkins) OVER (ORDER BY bucket_start ROWS BETWEEN CURRENT ROW AND @following FOLLOWING) AS window_checkins
+	FROM timeline`
+
+// Every figure is aggregated in the database over confirmed check-ins and read
+// in one snapshot, so they agree with each other even while check-ins keep arriving.
+func (r *repository) GetEventAnalytics(eventID datatypes.UUID, bucket time.Duration, peakWindow time.Duration, margin time.Duration, ctx context.Context) (*entity.EventAnalytics, error) {
+	analytics := entity.EventAnalytics{}
+	following := int64(peakWindow/bucket) - 1
+	if following < 0 {
+		following = 0
+	}
+	params := []any{
+		sql.Named("event", eventID),
+		sql.Named("bucket", int64(bucket.Seconds())),
+		sql.Named("following", following),
+		sql.Named("margin", int64(margin.Seconds())),
+	}
+
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`SELECT method AS key, COUNT(*) AS count
+			FROM event_participants
+			WHERE event_id = @event AND checkin_timestamp IS NOT NULL
+			GROUP BY method
+			ORDER BY count DESC, key`, params...).Scan(&analytics.Methods).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Raw(analyticsTimelineQuery+` ORDER BY bucket_start`, params...).
+			Scan(&analytics.Timeline).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Raw(`SELECT COUNT(*)
+			FROM event_participants ep
+			JOIN events e ON e.id = ep.event_id
+			WHERE ep.event_id = @event AND ep.checkin_timestamp IS NOT NULL
+				AND NOT (`+analyticsInTimeline+`)`, params...).
+			Scan(&analytics.OutsideTimeline).Error; err != nil {
+			return err
+		}
+
+		var peak []entity.AnalyticsBucket
+		if err := tx.Raw(`SELECT * FROM (`+analyticsTimelineQuery+`) w
+			ORDER BY window_checkins DESC, bucket_start
+			LIMIT 1`, params...).Scan(&peak).Error; err != nil {
+			return err
+		}
+		if len(peak) > 0 {
+			analytics.Peak = &peak[0]
+		}
+
+		if err := tx.Raw(`SELECT organization AS key, COUNT(*) AS count
+			FROM event_participants
+			WHERE event_id = @event AND checkin_timestamp IS NOT NULL
+			GROUP BY organization
+			ORDER BY count DESC, key`, params...).Scan(&analytics.Organizations).Error; err != nil {
+			return err
+		}
+
+		// throughput over the span between a scanner's first and last scan, at least a minute
+		return tx.Raw(`SELECT scanner_id,
+				COUNT(*) AS checkins,
+				MIN(scanned_timestamp) AS first_scan,
+				MAX(scanned_timestamp) AS last_scan,
+				COUNT(*) / GREATEST(extract(epoch FROM MAX(scanned_timestamp) - MIN(scanned_timestamp)) / 60, 1)::float8 AS per_minute
+			FROM event_participants
+			WHERE event_id = @event AND checkin_timestamp IS NOT NULL
+			GROUP BY scanner_id
+			ORDER BY checkins DESC, scanner_id NULLS LAST`, params...).Scan(&analytics.Scanners).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return &analytics, nil
+}
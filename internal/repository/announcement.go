@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+var ErrAnnouncementRateLimited = errors.New("too many announcements")
+
+type AnnouncementRepository interface {
+	CreateAnnouncement(announcement *entity.EventAnnouncement, limit int, since time.Time, ctx context.Context) (int64, error)
+	GetEventAnnouncements(eventID datatypes.UUID, userID datatypes.UUID, staff bool, limit int, ctx context.Context) ([]entity.EventAnnouncement, error)
+}
+
+// the users each audience reaches, the author is left out
+var announcementAudienceQueries = map[string]string{
+	string(entity.AUDIENCE_REGISTERED): `SELECT r.user_id FROM event_registrations r
+		WHERE r.event_id = @event AND r.status = 'REGISTERED'`,
+	string(entity.AUDIENCE_CHECKED_IN): `SELECT ep.participant_id FROM event_participants ep
+		WHERE ep.event_id = @event AND ep.checkin_timestamp IS NOT NULL`,
+	string(entity.AUDIENCE_STAFF): `SELECT eu.user_id FROM event_users eu
+		WHERE eu.event_id = @event`,
+}
+
+// Saves the announcement and queues a notification for everyone in its
+// audience, returning how many were queued. The event row is locked so
+// concurrent announcements cannot both slip under the limit.
+// Returns ErrAnnouncementRateLimited when limit announcements were made since since.
+func (r *repository) CreateAnnouncement(announcement *entity.EventAnnouncement, limit int, since time.Time, ctx context.Context) (int64, error) {
+	audienceQuery, ok := announcementAudienceQueries[string(announcement.Audience)]
+	if !ok {
+		return 0, errors.New("unknown announcement audience")
+	}
+
+	var queued int64
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, lockErr := r._LockEvent(tx, announcement.EventID); lockErr != nil {
+			return lockErr
+		}
+
+		var recent int64
+		countErr := tx.Model(&entity.EventAnnouncement{}).
+			Where("event_id = ? AND created_at > ?", announcement.EventID, since).
+			Count(&recent).Error
+		if countErr != nil {
+			return countErr
+		}
+		if recent >= int64(limit) {
+			return ErrAnnouncementRateLimited
+		}
+
+		if err := tx.Omit(clause.Associations).Create(announcement).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`INSERT INTO notifications (user_id, event_id, kind, announcement_id)
+			SELECT u.id, @event, 'ANNOUNCEMENT', @announcement
+			FROM users u
+			WHERE u.id IN (`+audienceQuery+`)
+				AND u.id IS DISTINCT FROM @author AND u.anonymised_at IS NULL
+			ON CONFLICT DO NOTHING`,
+			sql.Named("event", announcement.EventID),
+			sql.Named("announcement", announcement.ID),
+			sql.Named("author", announcement.AuthorID))
+		if result.Error != nil {
+			return result.Error
+		}
+		queued = result.RowsAffected
+		return nil
+	})
+	return queued, err
+}
+
+// The newest announcements the user may read, staff read every one. Others
+// read those addressed to them as they are now, so a participant who cancels
+// no longer sees what was sent to registered users.
+func (r *repository) GetEventAnnouncements(eventID datatypes.UUID, userID datatypes.UUID, staff bool, limit int, ctx context.Context) ([]entity.EventAnnouncement, error) {
+	query := r._DB(ctx).Model(&entity.EventAnnouncement{}).
+		Where("event_id = ?", eventID)
+	if !staff {
+		query = query.Where(`(audience = 'REGISTERED' AND EXISTS (SELECT 1 FROM event_registrations r
+				WHERE r.event_id = event_announcements.event_id AND r.user_id = @user AND r.status = 'REGISTERED'))
+			OR (audience = 'CHECKED_IN' AND EXISTS (SELECT 1 FROM event_participants ep
+				WHERE ep.event_id = event_announcements.event_id AND ep.participant_id = @user AND ep.checkin_timestamp IS NOT NULL))`,
+			sql.Named("user", userID))
+	}
+
+	var announcements []entity.EventAnnouncement
+	err := query.Order("created_at DESC").Limit(limit).Find(&announcements).Error
+	if err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+// Audit logs are append-only, so there is deliberately no update or delete.
+type AuditRepository interface {
+	CreateAuditLog(log *entity.AuditLog, ctx context.Context) error
+	GetAuditLogs(filter entity.AuditLogFilter, page int, pageSize int, ctx context.Context) (*[]entity.AuditLog, int64, error)
+	GetSeriesMembers(seriesID datatypes.UUID, ctx context.Context) (*[]entity.EventSeriesUser, error)
+	GetSeriesAccessLists(seriesID datatypes.UUID, ctx context.Context) (whitelist []uint64, faculties []int, err error)
+}
+
+func (r *repository) CreateAuditLog(log *entity.AuditLog, ctx context.Context) error {
+	return r._DB(ctx).Omit(clause.Associations).Create(log).Error
+}
+
+// newest first
+func (r *repository) GetAuditLogs(filter entity.AuditLogFilter, page int, pageSize int, ctx context.Context) (*[]entity.AuditLog, int64, error) {
+	query := func() *gorm.DB {
+		q := r._DB(ctx).Model(&entity.AuditLog{})
+		if filter.EventID != nil {
+			q = q.Where(`event_id = ? OR (event_id IS NULL AND series_id IS NOT NULL
+				AND series_id = (SELECT series_id FROM events WHERE id = ?))`, *filter.EventID, *filter.EventID)
+		}
+		if filter.SeriesID != nil {
+			q = q.Where("series_id = ?", *filter.SeriesID)
+		}
+		if filter.ActorID != nil {
+			q = q.Where("actor_id = ?", *filter.ActorID)
+		}
+		if filter.Action != "" {
+			q = q.Where("action = ?", filter.Action)
+		}
+		if filter.TargetType != "" {
+			q = q.Where("target_type = ?", filter.TargetType)
+		}
+		if filter.From != nil {
+			q = q.Where("created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			q = q.Where("created_at < ?", *filter.To)
+		}
+		return q
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []entity.AuditLog
+	err := query().
+		Order("created_at DESC").
+		Order("id").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return &logs, total, nil
+}
+
+func (r *repository) GetSeriesMembers(seriesID datatypes.UUID, ctx context.Context) (*[]entity.EventSeriesUser, error) {
+	var members []entity.EventSeriesUser
+	err := r._DB(ctx).
+		Where("series_id = ?", seriesID).
+		Order("role").
+		Order("user_id").
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return &members, nil
+}
+
+// The whitelist and allowed faculties of the next upcoming occurrence, which is
+// what a series edit replaces. Both are empty when nothing is upcoming.
+func (r *repository) GetSeriesAccessLists(seriesID datatypes.UUID, ctx context.Context) ([]uint64, []int, error) {
+	tx := r._DB(ctx)
+	nextOccurrence := func() *gorm.DB {
+		return tx.Model(&entity.Event{}).
+			Select("id").
+			Where("series_id = ? AND start_time > now()", seriesID).
+			Order("start_time").
+			Limit(1)
+	}
+
+	whitelist := []uint64{}
+	err := tx.Model(&entity.EventWhitelist{}).
+		Where("event_id = (?)", nextOccurrence()).
+		Order("attendee_ref_id").
+		Pluck("attendee_ref_id", &whitelist).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	faculties := []int{}
+	err = tx.Model(&entity.EventAllowedFaculties{}).
+		Where("event_id = (?)", nextOccurrence()).
+		Order("faculty_no").
+		Pluck("faculty_no", &faculties).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return whitelist, faculties, nil
+}
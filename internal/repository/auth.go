@@ -15,18 +15,18 @@ type AuthRepository interface {
 
 func (r *repository) GetUserById(userID datatypes.UUID, ctx context.Context) (entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, &entity.User{ID: userID}).Error
+	err := r._DB(ctx).First(&user, &entity.User{ID: userID}).Error
 	return user, err
 }
 
 func (r *repository) GetUserByRefId(refID uint64, ctx context.Context) (entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, &entity.User{RefID: refID}).Error
+	err := r._DB(ctx).First(&user, &entity.User{RefID: refID}).Error
 	return user, err
 }
 
 func (r *repository) CreateUser(user *entity.User, ctx context.Context) (*entity.User, error) {
-	err := r.db.WithContext(ctx).Create(user).Error
+	err := r._DB(ctx).Create(user).Error
 	if err != nil {
 		return nil, err
 	}
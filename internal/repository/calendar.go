@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type CalendarRepository interface {
+	SaveCalendarToken(userID datatypes.UUID, tokenHash string, ctx context.Context) error
+	DeleteCalendarToken(userID datatypes.UUID, ctx context.Context) (int64, error)
+	GetUserIDByCalendarToken(tokenHash string, ctx context.Context) (datatypes.UUID, error)
+	GetCalendarEvents(userID datatypes.UUID, since time.Time, limit int, ctx context.Context) (*[]entity.GetEventsQueryResult, error)
+	GetEventAgendas(eventIDs []datatypes.UUID, ctx context.Context) (*[]entity.EventAgenda, error)
+}
+
+// replaces the user's token, so rotating invalidates the previous feed URL
+func (r *repository) SaveCalendarToken(userID datatypes.UUID, tokenHash string, ctx context.Context) error {
+	return r._DB(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+		}).
+		Create(&entity.CalendarToken{UserID: userID, TokenHash: tokenHash, CreatedAt: time.Now()}).Error
+}
+
+func (r *repository) DeleteCalendarToken(userID datatypes.UUID, ctx context.Context) (int64, error) {
+	result := r._DB(ctx).Delete(&entity.CalendarToken{}, "user_id = ?", userID)
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) GetUserIDByCalendarToken(tokenHash string, ctx context.Context) (datatypes.UUID, error) {
+	var token entity.CalendarToken
+	err := r._DB(ctx).First(&token, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return datatypes.UUID{}, err
+	}
+	return token.UserID, nil
+}
+
+// Events the user manages, holds an active registration for or checked in to,
+// ending after since. Past the limit, upcoming events closest to now are kept
+// first and then the most recent past ones, so a long history never crowds out
+// what is coming up.
+func (r *repository) GetCalendarEvents(userID datatypes.UUID, since time.Time, limit int, ctx context.Context) (*[]entity.GetEventsQueryResult, error) {
+	var events []entity.GetEventsQueryResult
+	nearest := r._DB(ctx).Table("events e").
+		Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time", "e.end_time",
+			"e.location", "e.evaluation_form", "eu.role", "r.status AS registration_status",
+			"ep.id IS NOT NULL AS attended").
+		Joins("LEFT JOIN event_users eu ON eu.event_id = e.id AND eu.user_id = ?", userID).
+		Joins(`LEFT JOIN event_registrations r ON r.event_id = e.id AND r.user_id = ?
+			AND r.status <> 'CANCELLED'`, userID).
+		Joins("LEFT JOIN event_participants ep ON ep.event_id = e.id AND ep.participant_id = ?", userID).
+		Where("eu.id IS NOT NULL OR r.id IS NOT NULL OR ep.id IS NOT NULL").
+		Where("e.end_time >= ?", since).
+		Order("e.end_time < now()").
+		Order("abs(extract(epoch FROM e.start_time - now()))").
+		Limit(limit)
+	err := r._DB(ctx).Table("(?) AS nearest", nearest).
+		Order("start_time").
+		Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return &events, nil
+}
+
+func (r *repository) GetEventAgendas(eventIDs []datatypes.UUID, ctx context.Context) (*[]entity.EventAgenda, error) {
+	var agenda []entity.EventAgenda
+	if len(eventIDs) == 0 {
+		return &agenda, nil
+	}
+	err := r._DB(ctx).
+		Where("event_id IN ?", eventIDs).
+		Order("event_id").Order("start_time").
+		Find(&agenda).Error
+	if err != nil {
+		return nil, err
+	}
+	return &agenda, nil
+}
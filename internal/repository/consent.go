@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type ConsentRepository interface {
+	GetConsent(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventConsent, error)
+	SaveConsent(consent *entity.EventConsent, ctx context.Context) error
+	UpdateEventPrivacy(eventID datatypes.UUID, privacyNotice *string, retentionDays *uint32, ctx context.Context) error
+}
+
+func (r *repository) GetConsent(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventConsent, error) {
+	var consent entity.EventConsent
+	err := r._DB(ctx).First(&consent, "event_id = ? AND user_id = ?", eventID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// accepting a changed notice replaces the earlier acceptance
+func (r *repository) SaveConsent(consent *entity.EventConsent, ctx context.Context) error {
+	return r._DB(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"notice_hash", "accepted_via", "accepted_at"}),
+		}).
+		Create(consent).Error
+}
+
+func (r *repository) UpdateEventPrivacy(eventID datatypes.UUID, privacyNotice *string, retentionDays *uint32, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.Event{}).
+		Where("id = ?", eventID).
+		Updates(map[string]any{
+			"privacy_notice": privacyNotice,
+			"retention_days": retentionDays,
+		}).Error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type EvaluationRepository interface {
+	SaveEvaluation(evaluation *entity.EventEvaluation, ctx context.Context) error
+}
+
+// submitting again replaces the earlier rating
+func (r *repository) SaveEvaluation(evaluation *entity.EventEvaluation, ctx context.Context) error {
+	return r._DB(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rating", "comment", "submitted_at"}),
+		}).
+		Create(evaluation).Error
+}
@@ -2,23 +2,27 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
+	"strings"
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/cunex-club/quickattend-backend/internal/entity"
 )
 
 type EventRepository interface {
 	GetOneEvent(eventId datatypes.UUID, userId datatypes.UUID, ctx context.Context) (eventWithCount *entity.GetOneEventWithTotalCount, agenda *[]entity.GetOneEventAgenda, err error)
-	GetManagedEvents(userID datatypes.UUID, search string, ctx context.Context) (res *[]entity.GetEventsQueryResult, err error)
-	GetAttendedEvents(userID datatypes.UUID, page int, pageSize int, search string, ctx context.Context) (res *[]entity.GetEventsQueryResult, total int64, hasNext bool, err error)
-	GetDiscoveryEvents(userID datatypes.UUID, page int, pageSize int, search string, ctx context.Context) (res *[]entity.GetEventsQueryResult, total int64, hasNext bool, err error)
+	GetManagedEvents(userID datatypes.UUID, params entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (page *entity.GetEventsPage, err error)
+	GetAttendedEvents(userID datatypes.UUID, params entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (page *entity.GetEventsPage, err error)
+	GetDiscoveryEvents(userID datatypes.UUID, params entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (page *entity.GetEventsPage, err error)
+	GetTranscript(userID datatypes.UUID, ctx context.Context) ([]entity.TranscriptEntry, error)
+	UpdateEventActivity(eventID datatypes.UUID, hours *float64, category *string, ctx context.Context) error
 }
 
 func (r *repository) GetOneEvent(eventId datatypes.UUID, userId datatypes.UUID, ctx context.Context) (*entity.GetOneEventWithTotalCount, *[]entity.GetOneEventAgenda, error) {
-	withCtx := r.db.WithContext(ctx)
+	withCtx := r._DB(ctx)
 
 	var agenda []entity.GetOneEventAgenda
 	agendaErr := withCtx.Model(&entity.EventAgenda{}).Select("activity_name", "start_time", "end_time").
@@ -32,8 +36,12 @@ func (r *repository) GetOneEvent(eventId datatypes.UUID, userId datatypes.UUID,
 	var eventWithCount entity.GetOneEventWithTotalCount
 	eventErr := withCtx.Table("events e").
 		Select("e.name", "e.organizer", "e.description", "e.start_time",
-			"e.end_time", "e.location", "e.evaluation_form", "eu.role",
-			"COUNT(ep.id) AS total_registered").
+			"e.end_time", "e.location", "e.evaluation_form", "eu.role", "e.capacity", "e.series_id",
+			"e.privacy_notice", "e.retention_days", "e.retention_purged_at", "e.self_checkin_enabled",
+			"e.activity_hours", "e.activity_category",
+			"COUNT(ep.id) AS total_attended",
+			`(SELECT COUNT(*) FROM event_registrations r
+				WHERE r.event_id = e.id AND r.status = 'REGISTERED') AS total_registered`).
 		Joins("LEFT JOIN event_participants ep ON e.id = ep.event_id").
 		Joins("LEFT JOIN event_users eu ON e.id = eu.event_id").
 		Where("COALESCE(eu.user_id = ?, true)", userId).
@@ -48,144 +56,322 @@ func (r *repository) GetOneEvent(eventId datatypes.UUID, userId datatypes.UUID,
 	return &eventWithCount, &agenda, nil
 }
 
-func (r *repository) GetManagedEvents(userID datatypes.UUID, search string, ctx context.Context) (*[]entity.GetEventsQueryResult, error) {
-	tx := r.db.WithContext(ctx)
+func (r *repository) GetManagedEvents(userID datatypes.UUID, params entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (*entity.GetEventsPage, error) {
+	tx := r._DB(ctx)
 
-	var results []entity.GetEventsQueryResult
-	if search != "" {
-		searchQuery := fmt.Sprintf("%%%s%%", search)
-
-		errGetEvents := tx.Table("events e").
-			Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time",
-				"e.end_time", "e.location", "eu.role", "e.evaluation_form").
+	subQuery := func() *gorm.DB {
+		return r._SelectEvents(tx.Table("events e"), filter, "eu.role").
 			Joins(`JOIN event_users eu ON eu.user_id = ? 
 				AND eu.event_id = e.id`,
-				userID).
-			Where(`(e.name ILIKE ? OR e.organizer ILIKE ? OR e.description ILIKE ? OR e.location ILIKE ?
-				OR eu.role::TEXT ILIKE ? OR e.evaluation_form ILIKE ?)`,
-				searchQuery, searchQuery, searchQuery, searchQuery, searchQuery, searchQuery).
-			Order("e.id").
-			Scan(&results).Error
-
-		if errGetEvents != nil {
-			return nil, errGetEvents
-		}
-		return &results, nil
+				userID)
 	}
 
-	errGetEvents := tx.Table("events e").
-		Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time",
-			"e.end_time", "e.location", "eu.role", "e.evaluation_form").
-		Joins(`JOIN event_users eu ON eu.user_id = ? 
-			AND eu.event_id = e.id`,
-			userID).
-		Order("e.id").
-		Scan(&results).Error
-
-	if errGetEvents != nil {
-		return nil, errGetEvents
-	}
-	return &results, nil
+	return r._PaginateEvents(tx, subQuery, params, filter)
 }
 
-func (r *repository) GetAttendedEvents(userID datatypes.UUID, page int, pageSize int, search string, ctx context.Context) (*[]entity.GetEventsQueryResult, int64, bool, error) {
-	tx := r.db.WithContext(ctx)
-
-	var subQuery *gorm.DB
-	if search != "" {
-		searchQuery := fmt.Sprintf("%%%s%%", search)
-
-		subQuery = tx.Table("events e").
-			Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time",
-				"e.end_time", "e.location", "e.evaluation_form").
-			Joins(`JOIN event_participants ep ON ep.participant_id = ? 
-				AND ep.event_id = e.id
-				`, userID).
-			Where(`(e.name ILIKE ? OR e.organizer ILIKE ? OR e.description ILIKE ? OR e.location ILIKE ?
-				OR e.evaluation_form ILIKE ?)
-				`, searchQuery, searchQuery, searchQuery, searchQuery, searchQuery)
-	} else {
-		subQuery = tx.Table("events e").
-			Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time",
-				"e.end_time", "e.location", "e.evaluation_form").
-			Joins(`JOIN event_participants ep ON ep.participant_id = ? 
-			AND ep.event_id = e.id
-			`, userID)
-	}
-
-	var count int64
-	countErr := tx.Raw(`SELECT COUNT(*) AS total FROM (?) AS subQuery`, subQuery).Scan(&count).Error
-	if countErr != nil {
-		return nil, -1, false, countErr
-	}
+func (r *repository) GetAttendedEvents(userID datatypes.UUID, params entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (*entity.GetEventsPage, error) {
+	tx := r._DB(ctx)
 
-	var rawResult []entity.GetEventsQueryResult
-	getEventsErr := tx.Raw(`SELECT subQuery.* FROM (?) AS subQuery
-		ORDER BY subQuery.id
-		OFFSET ?
-		LIMIT ?
-	`, subQuery, page*pageSize, pageSize+1).Scan(&rawResult).Error
-	if getEventsErr != nil {
-		return nil, -1, false, getEventsErr
+	// events the user checked in to or holds an active registration for
+	subQuery := func() *gorm.DB {
+		return r._SelectEvents(tx.Table("events e"), filter,
+			"r.status AS registration_status", "ep.id IS NOT NULL AS attended").
+			Joins(`LEFT JOIN event_participants ep ON ep.participant_id = ?
+				AND ep.event_id = e.id`, userID).
+			Joins(`LEFT JOIN event_registrations r ON r.user_id = ?
+				AND r.event_id = e.id AND r.status <> 'CANCELLED'`, userID).
+			Where("ep.id IS NOT NULL OR r.id IS NOT NULL")
 	}
 
-	if len(rawResult) <= pageSize {
-		return &rawResult, count, false, nil
+	return r._PaginateEvents(tx, subQuery, params, filter)
+}
+
+// The attended events that count towards the user's activity hours: only
+// confirmed check-ins, so a registration alone or a cleared check-in does not
+func (r *repository) GetTranscript(userID datatypes.UUID, ctx context.Context) ([]entity.TranscriptEntry, error) {
+	var entries []entity.TranscriptEntry
+	err := r._DB(ctx).Table("events e").
+		Select("e.id AS event_id", "e.name AS event_name", "e.organizer", "e.start_time", "e.end_time",
+			"ep.checkin_timestamp", "e.activity_hours", "e.activity_category").
+		Joins(`JOIN event_participants ep ON ep.participant_id = ?
+			AND ep.event_id = e.id`, userID).
+		Where("ep.checkin_timestamp IS NOT NULL AND e.activity_hours IS NOT NULL").
+		Order("e.start_time, e.id").
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
 	}
-	clipped := rawResult[:pageSize]
-	return &clipped, count, true, nil
+	return entries, nil
 }
 
-func (r *repository) GetDiscoveryEvents(userID datatypes.UUID, page int, pageSize int, search string, ctx context.Context) (*[]entity.GetEventsQueryResult, int64, bool, error) {
-	tx := r.db.WithContext(ctx)
+func (r *repository) UpdateEventActivity(eventID datatypes.UUID, hours *float64, category *string, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.Event{}).
+		Where("id = ?", eventID).
+		Updates(map[string]any{
+			"activity_hours":    hours,
+			"activity_category": category,
+		}).Error
+}
 
-	var subQuery *gorm.DB
-	if search != "" {
-		searchQuery := fmt.Sprintf("%%%s%%", search)
+func (r *repository) GetDiscoveryEvents(userID datatypes.UUID, params entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (*entity.GetEventsPage, error) {
+	tx := r._DB(ctx)
 
-		subQuery = tx.Table("events e").Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time",
-			"e.end_time", "e.location", "e.evaluation_form").
-			Where(`NOT EXISTS (
-					SELECT 1 FROM event_users eu WHERE eu.event_id = e.id
-					AND eu.user_id = ?
-				) AND NOT EXISTS (
-					SELECT 1 FROM event_participants ep WHERE ep.event_id = e.id
-					AND ep.participant_id = ?
-				)`, userID, userID).
-			Where(`(e.name ILIKE ? OR e.organizer ILIKE ? OR e.description ILIKE ? OR e.location ILIKE ?
-				OR e.evaluation_form ILIKE ?)
-				`, searchQuery, searchQuery, searchQuery, searchQuery, searchQuery)
-	} else {
-		subQuery = tx.Table("events e").Select("e.id", "e.name", "e.organizer", "e.description", "e.start_time",
-			"e.end_time", "e.location", "e.evaluation_form").
+	subQuery := func() *gorm.DB {
+		return r._SelectEvents(tx.Table("events e"), filter).
 			Where(`NOT EXISTS (
 				SELECT 1 FROM event_users eu WHERE eu.event_id = e.id
 				AND eu.user_id = ?
 			) AND NOT EXISTS (
 				SELECT 1 FROM event_participants ep WHERE ep.event_id = e.id
 				AND ep.participant_id = ?
-			)`, userID, userID)
+			) AND NOT EXISTS (
+				SELECT 1 FROM event_registrations r WHERE r.event_id = e.id
+				AND r.user_id = ? AND r.status <> 'CANCELLED'
+			)`, userID, userID, userID)
+	}
+
+	return r._PaginateEvents(tx, subQuery, params, filter)
+}
+
+// Selects the GET /events columns from "events e", plus a relevance score when searching:
+// full-text rank over search_vector (name A > organizer B > location C > description D)
+// plus trigram word similarity of each field with the same weighting.
+// Rounded so that cursors can compare it exactly.
+func (r *repository) _SelectEvents(query *gorm.DB, filter entity.GetEventsFilter, extraColumns ...string) *gorm.DB {
+	columns := append([]string{"e.id", "e.name", "e.organizer", "e.description", "e.start_time",
+		"e.end_time", "e.location", "e.evaluation_form"}, extraColumns...)
+
+	if len(filter.SearchTerms) == 0 {
+		return query.Select(columns)
+	}
+
+	terms := strings.Join(filter.SearchTerms, " ")
+	return query.Select(strings.Join(columns, ", ")+`,
+		ROUND((
+			COALESCE(ts_rank(e.search_vector, to_tsquery('simple', ?)), 0)
+			+ 1.00 * word_similarity(?, e.name)
+			+ 0.75 * word_similarity(?, e.organizer)
+			+ 0.50 * word_similarity(?, e.location)
+			+ 0.25 * word_similarity(?, COALESCE(e.description, ''))
+		)::numeric, 6)::float8 AS relevance`,
+		filter.SearchTSQuery, terms, terms, terms, terms)
+}
+
+// Every search term must match some field or tag name, either as a word prefix, as a
+// substring (which also covers Thai, where words are not separated by spaces) or as a
+// fuzzy trigram word match that tolerates typos. Word prefixes use the search_vector
+// GIN index and substring matches the pg_trgm GIN indexes.
+func (r *repository) _SearchEvents(query *gorm.DB, filter entity.GetEventsFilter) *gorm.DB {
+	for _, term := range filter.SearchTerms {
+		pattern := "%" + _EscapeLike(term) + "%"
+		query = query.Where(`(e.search_vector @@ to_tsquery('simple', @prefix)
+			OR e.name ILIKE @pattern ESCAPE '\' OR e.organizer ILIKE @pattern ESCAPE '\'
+			OR e.location ILIKE @pattern ESCAPE '\' OR e.description ILIKE @pattern ESCAPE '\'
+			OR @term <% e.name OR @term <% e.organizer OR @term <% e.location
+			OR EXISTS (
+				SELECT 1 FROM event_tags et JOIN tags t ON t.id = et.tag_id
+				WHERE et.event_id = e.id
+				AND (t.slug ILIKE @pattern ESCAPE '\' OR t.name_th ILIKE @pattern ESCAPE '\' OR t.name_en ILIKE @pattern ESCAPE '\')
+			))`,
+			sql.Named("prefix", strings.ToLower(term)+":*"), sql.Named("pattern", pattern), sql.Named("term", term))
+	}
+	return query
+}
+
+// applies the search, status, date range, organizer and tag filters to a query over "events e"
+func (r *repository) _FilterEvents(query *gorm.DB, filter entity.GetEventsFilter) *gorm.DB {
+	query = r._SearchEvents(query, filter)
+
+	switch filter.Status {
+	case entity.EventStatusUpcoming:
+		query = query.Where("e.start_time > now()")
+	case entity.EventStatusOngoing:
+		query = query.Where("e.start_time <= now() AND e.end_time >= now()")
+	case entity.EventStatusPast:
+		query = query.Where("e.end_time < now()")
+	}
+
+	if filter.From != nil {
+		query = query.Where("e.start_time >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("e.start_time < ?", *filter.To)
+	}
+	if filter.Organizer != "" {
+		query = query.Where(`e.organizer ILIKE ? ESCAPE '\'`, "%"+_EscapeLike(filter.Organizer)+"%")
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM event_tags et JOIN tags t ON t.id = et.tag_id
+			WHERE et.event_id = e.id AND t.slug IN ?
+		)`, filter.Tags)
+	}
+
+	return query
+}
+
+// sort columns are passed as clause.Column so they are always quoted, never interpolated
+func _OrderEvents(table string, filter entity.GetEventsFilter) clause.OrderBy {
+	return clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Table: table, Name: filter.SortColumn}, Desc: filter.Descending},
+		{Column: clause.Column{Table: table, Name: "id"}, Desc: filter.Descending},
+	}}
+}
+
+func _EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Pages through the filtered subQuery ordered by (filter.SortColumn, id), either by offset
+// or by keyset after params.Cursor. subQuery must build a fresh unfiltered query on each call.
+// The total and the tag facets are only computed when requested.
+func (r *repository) _PaginateEvents(tx *gorm.DB, subQuery func() *gorm.DB, params entity.GetEventsPageParams, filter entity.GetEventsFilter) (*entity.GetEventsPage, error) {
+	filtered := r._FilterEvents(subQuery(), filter)
+	page := entity.GetEventsPage{}
+
+	if params.IncludeTotal {
+		var count int64
+		countErr := tx.Raw(`SELECT COUNT(*) FROM (?) AS subQuery`, filtered).Scan(&count).Error
+		if countErr != nil {
+			return nil, countErr
+		}
+		page.Total = &count
+	}
+
+	if params.IncludeFacets {
+		// facets ignore the tag filter itself, so every tag shows how many events it would give
+		facetFilter := filter
+		facetFilter.Tags = nil
+
+		var facets []entity.GetEventsTagFacet
+		facetErr := tx.Raw(`SELECT t.slug, t.name_th, t.name_en, COUNT(*) AS count
+			FROM (?) AS faceted
+			JOIN event_tags et ON et.event_id = faceted.id
+			JOIN tags t ON t.id = et.tag_id
+			GROUP BY t.id
+			ORDER BY count DESC, t.slug
+		`, r._FilterEvents(subQuery(), facetFilter)).Scan(&facets).Error
+		if facetErr != nil {
+			return nil, facetErr
+		}
+		page.Facets = &facets
 	}
 
-	var count int64
-	countErr := tx.Raw(`SELECT COUNT(*) FROM (?) AS subQuery`, subQuery).Scan(&count).Error
-	if countErr != nil {
-		return nil, -1, false, countErr
+	query := tx.Table("(?) AS paged", filtered).Order(_OrderEvents("paged", filter))
+
+	switch {
+	case params.UseCursor && params.Cursor != nil:
+		sortColumn := clause.Column{Table: "paged", Name: filter.SortColumn}
+		idColumn := clause.Column{Table: "paged", Name: "id"}
+		if filter.Descending {
+			query = query.Where("(?, ?) < (?, ?)", sortColumn, idColumn, params.Cursor.Value, params.Cursor.ID)
+		} else {
+			query = query.Where("(?, ?) > (?, ?)", sortColumn, idColumn, params.Cursor.Value, params.Cursor.ID)
+		}
+	case !params.UseCursor:
+		query = query.Offset(params.Page * params.PageSize)
 	}
 
 	var rawResult []entity.GetEventsQueryResult
-	getEventsErr := tx.Raw(`SELECT subQuery.* FROM (?) AS subQuery
-		ORDER BY subQuery.id
-		OFFSET ?
-		LIMIT ?
-	`, subQuery, page*pageSize, pageSize+1).Scan(&rawResult).Error
+	getEventsErr := query.Limit(params.PageSize + 1).Scan(&rawResult).Error
 	if getEventsErr != nil {
-		return nil, -1, false, getEventsErr
+		return nil, getEventsErr
+	}
+
+	if len(rawResult) > params.PageSize {
+		rawResult = rawResult[:params.PageSize]
+		page.HasNext = true
+	}
+	page.Events = &rawResult
+	return &page, nil
+}
+
+// Replaces the staff, whitelist and allowed faculties of the given events, nil
+// lists are left unchanged. Managers and staff who were not on an event before
+// are sent a STAFF_ASSIGNED notification, unless the event already ended.
+func (r *repository) _ReplaceEventAccess(tx *gorm.DB, eventIDs []datatypes.UUID, staff *[]entity.EventUser, whitelist *[]uint64, faculties *[]uint8) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+
+	if staff != nil {
+		var previous []entity.EventUser
+		if err := tx.Where("event_id IN ?", eventIDs).Find(&previous).Error; err != nil {
+			return err
+		}
+		assigned := map[string]bool{}
+		for _, member := range previous {
+			assigned[member.EventID.String()+"/"+member.UserID.String()] = true
+		}
+
+		if err := tx.Where("event_id IN ?", eventIDs).Delete(&entity.EventUser{}).Error; err != nil {
+			return err
+		}
+		rows := []entity.EventUser{}
+		for _, eventID := range eventIDs {
+			for _, member := range *staff {
+				rows = append(rows, entity.EventUser{EventID: eventID, UserID: member.UserID, Role: member.Role})
+			}
+		}
+		if len(rows) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+
+		added := []datatypes.UUID{}
+		for _, row := range rows {
+			if !assigned[row.EventID.String()+"/"+row.UserID.String()] {
+				added = append(added, row.ID)
+			}
+		}
+		if len(added) > 0 {
+			err := tx.Exec(`INSERT INTO notifications (user_id, event_id, kind)
+				SELECT eu.user_id, eu.event_id, 'STAFF_ASSIGNED'
+				FROM event_users eu
+				JOIN events e ON e.id = eu.event_id AND e.end_time > now()
+				JOIN users u ON u.id = eu.user_id AND u.anonymised_at IS NULL
+				WHERE eu.id IN ? AND eu.role IN ('MANAGER', 'STAFF')
+				`+notificationRequeue, added).Error
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	if len(rawResult) <= pageSize {
-		return &rawResult, count, false, nil
+	if whitelist != nil {
+		if err := tx.Where("event_id IN ?", eventIDs).Delete(&entity.EventWhitelist{}).Error; err != nil {
+			return err
+		}
+		rows := []entity.EventWhitelist{}
+		for _, eventID := range eventIDs {
+			for _, refID := range *whitelist {
+				rows = append(rows, entity.EventWhitelist{EventID: eventID, AttendeeRefID: refID})
+			}
+		}
+		if len(rows) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&rows).Error; err != nil {
+				return err
+			}
+		}
 	}
-	clipped := rawResult[:pageSize]
-	return &clipped, count, true, nil
+
+	if faculties != nil {
+		if err := tx.Where("event_id IN ?", eventIDs).Delete(&entity.EventAllowedFaculties{}).Error; err != nil {
+			return err
+		}
+		rows := []entity.EventAllowedFaculties{}
+		for _, eventID := range eventIDs {
+			for _, facultyNO := range *faculties {
+				rows = append(rows, entity.EventAllowedFaculties{EventID: eventID, FacultyNO: facultyNO})
+			}
+		}
+		if len(rows) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type FraudRepository interface {
+	GetFraudRules(eventID datatypes.UUID, ctx context.Context) ([]entity.EventFraudRule, error)
+	SaveFraudRules(rules []entity.EventFraudRule, ctx context.Context) error
+	GetFraudScans(eventID datatypes.UUID, ctx context.Context) ([]entity.FraudScan, error)
+	GetConcurrentScans(eventID datatypes.UUID, window time.Duration, ctx context.Context) ([]entity.FraudScanPair, error)
+}
+
+func (r *repository) GetFraudRules(eventID datatypes.UUID, ctx context.Context) ([]entity.EventFraudRule, error) {
+	var rules []entity.EventFraudRule
+	err := r._DB(ctx).Where("event_id = ?", eventID).Find(&rules).Error
+	return rules, err
+}
+
+func (r *repository) SaveFraudRules(rules []entity.EventFraudRule, ctx context.Context) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "event_id"}, {Name: "rule"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "threshold"}),
+			}).
+			Create(&rules).Error
+	})
+}
+
+// every check-in of the event in the order it was scanned
+func (r *repository) GetFraudScans(eventID datatypes.UUID, ctx context.Context) ([]entity.FraudScan, error) {
+	var scans []entity.FraudScan
+	err := r._DB(ctx).Model(&entity.EventParticipants{}).
+		Select("id", "participant_id", "scanner_id", "method", "checkin_timestamp", "scanned_timestamp", "scanned_location").
+		Where("event_id = ?", eventID).
+		Order("scanned_timestamp, id").
+		Scan(&scans).Error
+	return scans, err
+}
+
+// check-ins of the event's participants at other events within window of their
+// check-in here, where both have a location
+func (r *repository) GetConcurrentScans(eventID datatypes.UUID, window time.Duration, ctx context.Context) ([]entity.FraudScanPair, error) {
+	var pairs []entity.FraudScanPair
+	err := r._DB(ctx).Raw(`
+		SELECT ep.id, ep.participant_id, ep.scanner_id, ep.scanned_timestamp, ep.scanned_location,
+			other.event_id AS other_event_id,
+			other.scanned_timestamp AS other_scanned_timestamp,
+			other.scanned_location AS other_scanned_location
+		FROM event_participants ep
+		JOIN event_participants other
+			ON other.participant_id = ep.participant_id
+			AND other.event_id <> ep.event_id
+			AND other.scanned_timestamp BETWEEN ep.scanned_timestamp - make_interval(secs => @window)
+				AND ep.scanned_timestamp + make_interval(secs => @window)
+		WHERE ep.event_id = @event_id
+			AND ep.scanned_location IS NOT NULL
+			AND other.scanned_location IS NOT NULL
+		ORDER BY ep.scanned_timestamp, ep.id`,
+		sql.Named("event_id", eventID),
+		sql.Named("window", window.Seconds()),
+	).Scan(&pairs).Error
+	return pairs, err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type IdempotencyRepository interface {
+	CreateIdempotencyKey(record *entity.IdempotencyKey, ctx context.Context) error
+	GetIdempotencyKey(userID datatypes.UUID, key string, ctx context.Context) (*entity.IdempotencyKey, error)
+	SaveIdempotencyResponse(id datatypes.UUID, status int, body []byte, ctx context.Context) error
+	DeleteIdempotencyKey(id datatypes.UUID, ctx context.Context) error
+	DeleteExpiredIdempotencyKeys(now time.Time, limit int, ctx context.Context) (int64, error)
+}
+
+// returns gorm.ErrDuplicatedKey if the user already used this key
+func (r *repository) CreateIdempotencyKey(record *entity.IdempotencyKey, ctx context.Context) error {
+	return r._DB(ctx).Omit(clause.Associations).Create(record).Error
+}
+
+func (r *repository) GetIdempotencyKey(userID datatypes.UUID, key string, ctx context.Context) (*entity.IdempotencyKey, error) {
+	var record entity.IdempotencyKey
+	err := r._DB(ctx).First(&record, "user_id = ? AND key = ?", userID, key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *repository) SaveIdempotencyResponse(id datatypes.UUID, status int, body []byte, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"response_status": status,
+			"response_body":   datatypes.JSON(body),
+		}).Error
+}
+
+func (r *repository) DeleteIdempotencyKey(id datatypes.UUID, ctx context.Context) error {
+	return r._DB(ctx).Delete(&entity.IdempotencyKey{}, "id = ?", id).Error
+}
+
+// deletes up to limit keys that expired before now, returning how many were deleted
+func (r *repository) DeleteExpiredIdempotencyKeys(now time.Time, limit int, ctx context.Context) (int64, error) {
+	result := r._DB(ctx).
+		Where("id IN (?)", r.db.Model(&entity.IdempotencyKey{}).
+			Select("id").
+			Where("expires_at < ?", now).
+			Limit(limit)).
+		Delete(&entity.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type NotificationRepository interface {
+	ScheduleNotifications(now time.Time, evaluationWindow time.Duration, ctx context.Context) (int64, error)
+	ClaimNotifications(now time.Time, leaseUntil time.Time, limit int, ctx context.Context) ([]entity.PendingNotification, error)
+	SaveNotificationAttempt(notification *entity.Notification, ctx context.Context) error
+	GetNotificationPreference(userID datatypes.UUID, ctx context.Context) (*entity.NotificationPreference, error)
+	SaveNotificationPreference(preference *entity.NotificationPreference, ctx context.Context) error
+}
+
+// Each query adds the notifications that are due as of @now. The unique
+// (user_id, event_id, kind, announcement_id) constraint keeps every run from adding them again,
+// and anything no longer due, such as a cancelled registration, is never added.
+var notificationScheduleQueries = []string{
+	// registered participants a day ahead, unless the event is already within the hour
+	`INSERT INTO notifications (user_id, event_id, kind)
+		SELECT r.user_id, r.event_id, 'EVENT_REMINDER_24H'
+		FROM events e
+		JOIN event_registrations r ON r.event_id = e.id AND r.status = 'REGISTERED'
+		JOIN users u ON u.id = r.user_id AND u.anonymised_at IS NULL
+		WHERE e.start_time > @now + interval '1 hour' AND e.start_time <= @now + interval '24 hours'
+		ON CONFLICT DO NOTHING`,
+	`INSERT INTO notifications (user_id, event_id, kind)
+		SELECT r.user_id, r.event_id, 'EVENT_REMINDER_1H'
+		FROM events e
+		JOIN event_registrations r ON r.event_id = e.id AND r.status = 'REGISTERED'
+		JOIN users u ON u.id = r.user_id AND u.anonymised_at IS NULL
+		WHERE e.start_time > @now AND e.start_time <= @now + interval '1 hour'
+		ON CONFLICT DO NOTHING`,
+	// confirmed attendees of events that ended within the window
+	`INSERT INTO notifications (user_id, event_id, kind)
+		SELECT ep.participant_id, ep.event_id, 'EVALUATION_OPEN'
+		FROM events e
+		JOIN event_participants ep ON ep.event_id = e.id AND ep.checkin_timestamp IS NOT NULL
+		JOIN users u ON u.id = ep.participant_id AND u.anonymised_at IS NULL
+		WHERE e.end_time <= @now AND e.end_time > @now - make_interval(secs => @window)
+		ON CONFLICT DO NOTHING`,
+}
+
+// Ends an insert of notifications queued when something happens, such as a
+// staff assignment, rather than by the schedule. A notification of the same
+// kind sent before is queued again, the user is told each time it happens.
+const notificationRequeue = `ON CONFLICT ON CONSTRAINT unique_user_event_and_notification_kind DO UPDATE SET
+	status = 'PENDING', attempts = 0, delivered_channels = '{}', last_error = NULL,
+	created_at = now(), next_attempt_at = now(), sent_at = NULL`
+
+func (r *repository) ScheduleNotifications(now time.Time, evaluationWindow time.Duration, ctx context.Context) (int64, error) {
+	var added int64
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, query := range notificationScheduleQueries {
+			result := tx.Exec(query, sql.Named("now", now), sql.Named("window", evaluationWindow.Seconds()))
+			if result.Error != nil {
+				return result.Error
+			}
+			added += result.RowsAffected
+		}
+		return nil
+	})
+	return added, err
+}
+
+// Leases up to limit due notifications until leaseUntil, so concurrent workers
+// skip them and a worker that dies mid-delivery leaves them to be retried.
+func (r *repository) ClaimNotifications(now time.Time, leaseUntil time.Time, limit int, ctx context.Context) ([]entity.PendingNotification, error) {
+	var pending []entity.PendingNotification
+	err := r._DB(ctx).Raw(`
+		WITH claimed AS (
+			UPDATE notifications n SET next_attempt_at = @lease
+			WHERE n.id IN (
+				SELECT id FROM notifications
+				WHERE status = 'PENDING' AND next_attempt_at <= @now
+				ORDER BY next_attempt_at
+				LIMIT @limit
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING n.id, n.user_id, n.event_id, n.kind, n.attempts, n.delivered_channels, n.announcement_id
+		)
+		SELECT c.id, c.user_id, c.event_id, c.kind, c.attempts, c.delivered_channels,
+			u.ref_id, e.name AS event_name, e.start_time, e.end_time, e.location, e.evaluation_form, eu.role,
+			r.status AS registration_status, a.message AS announcement,
+			np.user_id IS NOT NULL AS has_preferences, np.channels, np.email, np.line_user_id,
+			COALESCE(np.event_reminders, true) AS event_reminders,
+			COALESCE(np.evaluation_notices, true) AS evaluation_notices,
+			COALESCE(np.staff_notices, true) AS staff_notices
+		FROM claimed c
+		JOIN users u ON u.id = c.user_id
+		JOIN events e ON e.id = c.event_id
+		LEFT JOIN event_users eu ON eu.event_id = c.event_id AND eu.user_id = c.user_id
+		LEFT JOIN event_registrations r ON r.event_id = c.event_id AND r.user_id = c.user_id
+		LEFT JOIN event_announcements a ON a.id = c.announcement_id
+		LEFT JOIN notification_preferences np ON np.user_id = c.user_id`,
+		sql.Named("now", now), sql.Named("lease", leaseUntil), sql.Named("limit", limit)).
+		Scan(&pending).Error
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (r *repository) SaveNotificationAttempt(notification *entity.Notification, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.Notification{}).
+		Where("id = ?", notification.ID).
+		Updates(map[string]any{
+			"status":             notification.Status,
+			"attempts":           notification.Attempts,
+			"delivered_channels": notification.DeliveredChannels,
+			"last_error":         notification.LastError,
+			"next_attempt_at":    notification.NextAttemptAt,
+			"sent_at":            notification.SentAt,
+		}).Error
+}
+
+func (r *repository) GetNotificationPreference(userID datatypes.UUID, ctx context.Context) (*entity.NotificationPreference, error) {
+	var preference entity.NotificationPreference
+	if err := r._DB(ctx).First(&preference, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// every column is written, otherwise gorm would leave a false toggle to the column's true default
+func (r *repository) SaveNotificationPreference(preference *entity.NotificationPreference, ctx context.Context) error {
+	return r._DB(ctx).
+		Select("*").
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"channels", "email", "line_user_id", "event_reminders", "evaluation_notices", "staff_notices", "updated_at"}),
+		}).
+		Create(preference).Error
+}
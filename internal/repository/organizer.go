@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type OrganizerRepository interface {
+	CreateOrganizer(organizer *entity.Organizer, members []entity.OrganizerMember, ctx context.Context) error
+	GetOrganizerById(organizerID datatypes.UUID, ctx context.Context) (*entity.Organizer, error)
+	GetOrganizerUserRole(organizerID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*string, error)
+	GetMyOrganizers(userID datatypes.UUID, ctx context.Context) (*[]entity.GetMyOrganizer, error)
+	GetOrganizerMembers(organizerID datatypes.UUID, ctx context.Context) (*[]entity.GetOrganizerMember, error)
+	UpdateOrganizer(organizerID datatypes.UUID, name string, description *string, members *[]entity.OrganizerMember, ctx context.Context) error
+	SetEventOrganizer(eventID datatypes.UUID, organizer *entity.Organizer, ctx context.Context) error
+	GetOrganizerReport(organizerID datatypes.UUID, from *time.Time, to *time.Time, ctx context.Context) (*entity.OrganizerReport, error)
+}
+
+// Returns gorm.ErrDuplicatedKey if another organizer has the same name
+func (r *repository) CreateOrganizer(organizer *entity.Organizer, members []entity.OrganizerMember, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(organizer).Error; err != nil {
+			return err
+		}
+		for i := range members {
+			members[i].OrganizerID = organizer.ID
+		}
+		return tx.Omit(clause.Associations).Create(&members).Error
+	})
+}
+
+func (r *repository) GetOrganizerById(organizerID datatypes.UUID, ctx context.Context) (*entity.Organizer, error) {
+	var organizer entity.Organizer
+	err := r._DB(ctx).First(&organizer, "id = ?", organizerID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organizer, nil
+}
+
+// returns nil role (and no error) when the user is not a member
+func (r *repository) GetOrganizerUserRole(organizerID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*string, error) {
+	var roles []string
+	err := r._DB(ctx).Model(&entity.OrganizerMember{}).
+		Where("organizer_id = ? AND user_id = ?", organizerID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	return &roles[0], nil
+}
+
+func (r *repository) GetMyOrganizers(userID datatypes.UUID, ctx context.Context) (*[]entity.GetMyOrganizer, error) {
+	var organizers []entity.GetMyOrganizer
+	err := r._DB(ctx).Table("organizers o").
+		Select("o.id", "o.name", "o.description", "m.role").
+		Joins("JOIN organizer_members m ON m.organizer_id = o.id").
+		Where("m.user_id = ?", userID).
+		Order("o.name").
+		Scan(&organizers).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organizers, nil
+}
+
+func (r *repository) GetOrganizerMembers(organizerID datatypes.UUID, ctx context.Context) (*[]entity.GetOrganizerMember, error) {
+	var members []entity.GetOrganizerMember
+	err := r._DB(ctx).Table("organizer_members m").
+		Select("m.user_id", "m.role", "u.ref_id", "u.firstname_th", "u.surname_th", "u.firstname_en", "u.surname_en").
+		Joins("JOIN users u ON u.id = m.user_id").
+		Where("m.organizer_id = ?", organizerID).
+		Order("m.role, u.firstname_en, u.surname_en").
+		Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return &members, nil
+}
+
+// Renames the organizer along with the Organizer of every event it owns. A
+// non-nil members replaces everyone but the owners.
+// Returns gorm.ErrDuplicatedKey if another organizer has the same name.
+func (r *repository) UpdateOrganizer(organizerID datatypes.UUID, name string, description *string, members *[]entity.OrganizerMember, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entity.Organizer{}).
+			Where("id = ?", organizerID).
+			Updates(map[string]any{"name": name, "description": description}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Event{}).Where("organizer_id = ?", organizerID).Update("organizer", name).Error; err != nil {
+			return err
+		}
+
+		if members == nil {
+			return nil
+		}
+		if err := tx.Where("organizer_id = ? AND role <> ?", organizerID, entity.OWNER).Delete(&entity.OrganizerMember{}).Error; err != nil {
+			return err
+		}
+		if len(*members) == 0 {
+			return nil
+		}
+		for i := range *members {
+			(*members)[i].OrganizerID = organizerID
+		}
+		return tx.Omit(clause.Associations).Create(members).Error
+	})
+}
+
+// a nil organizer detaches the event and leaves its Organizer text as it was
+func (r *repository) SetEventOrganizer(eventID datatypes.UUID, organizer *entity.Organizer, ctx context.Context) error {
+	updates := map[string]any{"organizer_id": nil}
+	if organizer != nil {
+		updates = map[string]any{"organizer_id": organizer.ID, "organizer": organizer.Name}
+	}
+	return r._DB(ctx).Model(&entity.Event{}).Where("id = ?", eventID).Updates(updates).Error
+}
+
+// Every figure is aggregated in the database over the organizer's events that
+// started in [from, to), read in one snapshot. Check-ins count only once
+// confirmed, a check-in whose timestamp a correction cleared is not attendance.
+func (r *repository) GetOrganizerReport(organizerID datatypes.UUID, from *time.Time, to *time.Time, ctx context.Context) (*entity.OrganizerReport, error) {
+	report := entity.OrganizerReport{}
+
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		events := func() *gorm.DB {
+			q := tx.Model(&entity.Event{}).Select("id").Where("organizer_id = ?", organizerID)
+			if from != nil {
+				q = q.Where("start_time >= ?", *from)
+			}
+			if to != nil {
+				q = q.Where("start_time < ?", *to)
+			}
+			return q
+		}
+
+		err := tx.Raw(`WITH per_person AS (
+				SELECT participant_id, COUNT(*) AS events_attended
+				FROM event_participants
+				WHERE event_id IN (@events) AND checkin_timestamp IS NOT NULL
+				GROUP BY participant_id
+			)
+			SELECT
+				(SELECT COUNT(*) FROM events WHERE id IN (@events)) AS events,
+				COALESCE(SUM(events_attended), 0)::bigint AS checkins,
+				COUNT(*) AS unique_attendees,
+				COUNT(*) FILTER (WHERE events_attended > 1) AS repeat_attendees,
+				(SELECT AVG(rating)::float8 FROM event_evaluations WHERE event_id IN (@events)) AS rating_average,
+				(SELECT COUNT(*) FROM event_evaluations WHERE event_id IN (@events)) AS ratings
+			FROM per_person`,
+			sql.Named("events", events())).Scan(&report).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw(`SELECT events_attended, COUNT(*) AS attendees
+			FROM (
+				SELECT participant_id, COUNT(*) AS events_attended
+				FROM event_participants
+				WHERE event_id IN (@events) AND checkin_timestamp IS NOT NULL
+				GROUP BY participant_id
+			) per_person
+			GROUP BY events_attended
+			ORDER BY events_attended`,
+			sql.Named("events", events())).Scan(&report.Frequency).Error
+		if err != nil {
+			return err
+		}
+
+		// faculty mix of distinct attendees rather than of check-ins
+		err = tx.Raw(`SELECT organization AS key, COUNT(DISTINCT participant_id) AS count
+			FROM event_participants
+			WHERE event_id IN (@events) AND checkin_timestamp IS NOT NULL
+			GROUP BY organization
+			ORDER BY count DESC, key`,
+			sql.Named("events", events())).Scan(&report.Faculties).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Raw(`SELECT e.id, e.name, e.start_time, e.end_time,
+				(SELECT COUNT(*) FROM event_registrations r
+					WHERE r.event_id = e.id AND r.status = @registered) AS registered,
+				(SELECT COUNT(*) FROM event_participants ep
+					WHERE ep.event_id = e.id AND ep.checkin_timestamp IS NOT NULL) AS checkins,
+				(SELECT AVG(v.rating)::float8 FROM event_evaluations v WHERE v.event_id = e.id) AS rating_average,
+				(SELECT COUNT(*) FROM event_evaluations v WHERE v.event_id = e.id) AS ratings
+			FROM events e
+			WHERE e.id IN (@events)
+			ORDER BY e.start_time, e.id`,
+			sql.Named("events", events()),
+			sql.Named("registered", entity.REGISTERED)).Scan(&report.EventRows).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
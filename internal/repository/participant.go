@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type ParticipantRepository interface {
+	GetEventById(eventID datatypes.UUID, ctx context.Context) (*entity.Event, error)
+	GetEventUserRole(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*string, error)
+	IsWhitelisted(eventID datatypes.UUID, refID uint64, ctx context.Context) (bool, error)
+	IsFacultyAllowed(eventID datatypes.UUID, facultyNO uint8, ctx context.Context) (bool, error)
+	GetParticipant(eventID datatypes.UUID, participantID datatypes.UUID, ctx context.Context) (*entity.EventParticipants, error)
+	GetParticipantByIdempotencyKey(eventID datatypes.UUID, key string, ctx context.Context) (*entity.EventParticipants, error)
+	CreateParticipant(participant *entity.EventParticipants, ctx context.Context) (*entity.EventParticipants, error)
+	UpdateParticipantScan(id datatypes.UUID, scannedTimestamp time.Time, location entity.Point, scannerID *datatypes.UUID, ctx context.Context) error
+	GetEventParticipants(eventID datatypes.UUID, since *time.Time, page int, pageSize int, ctx context.Context) (*[]entity.GetEventParticipants, int64, error)
+	GetParticipantByID(eventID datatypes.UUID, id datatypes.UUID, ctx context.Context) (*entity.EventParticipants, error)
+	CorrectParticipant(id datatypes.UUID, checkinTimestamp time.Time, comment *string, ctx context.Context) error
+	DeleteParticipant(eventID datatypes.UUID, id datatypes.UUID, ctx context.Context) (int64, error)
+}
+
+func (r *repository) GetEventById(eventID datatypes.UUID, ctx context.Context) (*entity.Event, error) {
+	var event entity.Event
+	err := r._DB(ctx).First(&event, "id = ?", eventID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// returns nil role (and no error) when the user has no role in the event
+func (r *repository) GetEventUserRole(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*string, error) {
+	var roles []string
+	err := r._DB(ctx).Model(&entity.EventUser{}).
+		Where("event_id = ? AND user_id = ?", eventID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	return &roles[0], nil
+}
+
+func (r *repository) IsWhitelisted(eventID datatypes.UUID, refID uint64, ctx context.Context) (bool, error) {
+	var count int64
+	err := r._DB(ctx).Model(&entity.EventWhitelist{}).
+		Where("event_id = ? AND attendee_ref_id = ?", eventID, refID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) IsFacultyAllowed(eventID datatypes.UUID, facultyNO uint8, ctx context.Context) (bool, error) {
+	var count int64
+	err := r._DB(ctx).Model(&entity.EventAllowedFaculties{}).
+		Where("event_id = ? AND faculty_no = ?", eventID, facultyNO).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) GetParticipant(eventID datatypes.UUID, participantID datatypes.UUID, ctx context.Context) (*entity.EventParticipants, error) {
+	var participant entity.EventParticipants
+	err := r._DB(ctx).
+		First(&participant, "event_id = ? AND participant_id = ?", eventID, participantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *repository) GetParticipantByIdempotencyKey(eventID datatypes.UUID, key string, ctx context.Context) (*entity.EventParticipants, error) {
+	var participant entity.EventParticipants
+	err := r._DB(ctx).
+		First(&participant, "event_id = ? AND idempotency_key = ?", eventID, key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *repository) CreateParticipant(participant *entity.EventParticipants, ctx context.Context) (*entity.EventParticipants, error) {
+	err := r._DB(ctx).Omit(clause.Associations).Create(participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return participant, nil
+}
+
+func (r *repository) UpdateParticipantScan(id datatypes.UUID, scannedTimestamp time.Time, location entity.Point, scannerID *datatypes.UUID, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.EventParticipants{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"scanned_timestamp": scannedTimestamp,
+			"scanned_location":  location,
+			"scanner_id":        scannerID,
+		}).Error
+}
+
+// check-ins in the order they happened, since only keeps those after it so
+// scanner apps can poll for new check-ins as a live feed
+func (r *repository) GetEventParticipants(eventID datatypes.UUID, since *time.Time, page int, pageSize int, ctx context.Context) (*[]entity.GetEventParticipants, int64, error) {
+	query := func() *gorm.DB {
+		q := r._DB(ctx).Table("event_participants ep").
+			Joins("JOIN users u ON u.id = ep.participant_id").
+			Joins("LEFT JOIN user_photos up ON up.user_id = u.id").
+			Where("ep.event_id = ?", eventID)
+		if since != nil {
+			q = q.Where("ep.checkin_timestamp > ?", *since)
+		}
+		return q
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var participants []entity.GetEventParticipants
+	err := query().
+		Select("ep.id", "ep.participant_id", "ep.checkin_timestamp", "ep.scanned_timestamp",
+			"ep.method", "ep.comment",
+			"u.ref_id", "u.firstname_th", "u.surname_th", "u.firstname_en", "u.surname_en",
+			"up.user_id IS NOT NULL AS has_photo").
+		Order("ep.checkin_timestamp").
+		Order("ep.id").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Scan(&participants).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return &participants, total, nil
+}
+
+// looks up a check-in by its own id, unlike GetParticipant which takes the user's id
+func (r *repository) GetParticipantByID(eventID datatypes.UUID, id datatypes.UUID, ctx context.Context) (*entity.EventParticipants, error) {
+	var participant entity.EventParticipants
+	err := r._DB(ctx).
+		First(&participant, "event_id = ? AND id = ?", eventID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *repository) CorrectParticipant(id datatypes.UUID, checkinTimestamp time.Time, comment *string, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.EventParticipants{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"checkin_timestamp": checkinTimestamp,
+			"comment":           comment,
+		}).Error
+}
+
+// Deletes a check-in. A walk-in held a seat, so the waitlist is promoted into
+// whatever the deletion frees.
+func (r *repository) DeleteParticipant(eventID datatypes.UUID, id datatypes.UUID, ctx context.Context) (int64, error) {
+	var deleted int64
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		event, lockErr := r._LockEvent(tx, eventID)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		result := tx.Where("event_id = ? AND id = ?", eventID, id).
+			Delete(&entity.EventParticipants{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		if deleted == 0 {
+			return nil
+		}
+
+		_, promoteErr := r._PromoteWaitlist(tx, event, time.Now())
+		return promoteErr
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type PhotoRepository interface {
+	GetUserPhoto(userID datatypes.UUID, ctx context.Context) (*entity.UserPhoto, error)
+	SaveUserPhoto(photo *entity.UserPhoto, ctx context.Context) error
+	DeleteUserPhoto(userID datatypes.UUID, ctx context.Context) (int64, error)
+}
+
+func (r *repository) GetUserPhoto(userID datatypes.UUID, ctx context.Context) (*entity.UserPhoto, error) {
+	var photo entity.UserPhoto
+	err := r._DB(ctx).First(&photo, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// a user has at most one photo, uploading again replaces it
+func (r *repository) SaveUserPhoto(photo *entity.UserPhoto, ctx context.Context) error {
+	return r._DB(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"object_key", "width", "height", "updated_at"}),
+		}).
+		Create(photo).Error
+}
+
+func (r *repository) DeleteUserPhoto(userID datatypes.UUID, ctx context.Context) (int64, error) {
+	result := r._DB(ctx).Delete(&entity.UserPhoto{}, "user_id = ?", userID)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+// returned when a capacity limited event has no seat left for a walk-in participant
+var ErrEventFull = errors.New("event is full")
+
+type RegistrationRepository interface {
+	GetRegistration(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventRegistration, error)
+	GetWaitlistPosition(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (int64, error)
+	CountTakenSeats(eventID datatypes.UUID, ctx context.Context) (int64, error)
+	GetRegistrationStats(eventID datatypes.UUID, ctx context.Context) (*entity.GetRegistrationStats, error)
+	Register(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventRegistration, error)
+	CancelRegistration(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (cancelled *entity.EventRegistration, promoted []entity.EventRegistration, err error)
+	CreateParticipantWithinCapacity(participant *entity.EventParticipants, ctx context.Context) (*entity.EventParticipants, error)
+}
+
+func (r *repository) GetRegistration(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventRegistration, error) {
+	var registration entity.EventRegistration
+	err := r._DB(ctx).
+		First(&registration, "event_id = ? AND user_id = ?", eventID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// 1-based position of the user in the event's waitlist
+func (r *repository) GetWaitlistPosition(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (int64, error) {
+	var position int64
+	err := r._DB(ctx).Raw(`SELECT COUNT(*) FROM event_registrations w
+		JOIN event_registrations me ON me.event_id = w.event_id AND me.user_id = ?
+		WHERE w.event_id = ? AND w.status = ?
+		AND (w.registered_at, w.id) <= (me.registered_at, me.id)`,
+		userID, eventID, entity.WAITLISTED).Scan(&position).Error
+	return position, err
+}
+
+func (r *repository) CountTakenSeats(eventID datatypes.UUID, ctx context.Context) (int64, error) {
+	return r._CountTakenSeats(r._DB(ctx), eventID)
+}
+
+// attended counts registered users who checked in, walk-ins checked in without a
+// registration. Both count only confirmed check-ins, as analytics do.
+func (r *repository) GetRegistrationStats(eventID datatypes.UUID, ctx context.Context) (*entity.GetRegistrationStats, error) {
+	var stats entity.GetRegistrationStats
+	err := r._DB(ctx).Raw(`SELECT
+		COUNT(*) FILTER (WHERE r.status = @registered) AS registered,
+		COUNT(*) FILTER (WHERE r.status = @registered AND ep.checkin_timestamp IS NOT NULL) AS attended,
+		COUNT(*) FILTER (WHERE r.status = @waitlisted) AS waitlisted,
+		(SELECT COUNT(*) FROM event_participants w
+			WHERE w.event_id = @event AND w.checkin_timestamp IS NOT NULL AND NOT EXISTS (
+				SELECT 1 FROM event_registrations wr
+				WHERE wr.event_id = w.event_id AND wr.user_id = w.participant_id
+				AND wr.status = @registered
+			)) AS walk_ins
+		FROM event_registrations r
+		LEFT JOIN event_participants ep ON ep.event_id = r.event_id AND ep.participant_id = r.user_id
+		WHERE r.event_id = @event`,
+		sql.Named("event", eventID),
+		sql.Named("registered", entity.REGISTERED),
+		sql.Named("waitlisted", entity.WAITLISTED)).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Registers the user, or puts them on the waitlist when the event is full.
+// A cancelled registration is renewed at the back of the queue.
+// Returns gorm.ErrDuplicatedKey if the user is already registered or waitlisted.
+func (r *repository) Register(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventRegistration, error) {
+	var registration entity.EventRegistration
+
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		event, lockErr := r._LockEvent(tx, eventID)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		existingErr := tx.First(&registration, "event_id = ? AND user_id = ?", eventID, userID).Error
+		if existingErr != nil && !errors.Is(existingErr, gorm.ErrRecordNotFound) {
+			return existingErr
+		}
+		if existingErr == nil && registration.Status != entity.CANCELLED {
+			return gorm.ErrDuplicatedKey
+		}
+
+		status := entity.REGISTERED
+		if event.Capacity != nil {
+			taken, countErr := r._CountTakenSeats(tx, eventID)
+			if countErr != nil {
+				return countErr
+			}
+			if taken >= int64(*event.Capacity) {
+				status = entity.WAITLISTED
+			}
+		}
+
+		now := time.Now()
+		if existingErr == nil {
+			registration.Status = status
+			registration.RegisteredAt = now
+			registration.UpdatedAt = now
+			return tx.Model(&registration).Updates(map[string]any{
+				"status":        status,
+				"registered_at": now,
+				"updated_at":    now,
+			}).Error
+		}
+
+		registration = entity.EventRegistration{
+			EventID:      eventID,
+			UserID:       userID,
+			Status:       status,
+			RegisteredAt: now,
+			UpdatedAt:    now,
+		}
+		return tx.Omit(clause.Associations).Create(&registration).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// Cancels the user's registration or waitlist entry. When a seat is freed the
+// waitlist is promoted into it, see _PromoteWaitlist.
+// Returns gorm.ErrRecordNotFound if there is nothing to cancel.
+func (r *repository) CancelRegistration(eventID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.EventRegistration, []entity.EventRegistration, error) {
+	var registration entity.EventRegistration
+	promoted := []entity.EventRegistration{}
+
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		event, lockErr := r._LockEvent(tx, eventID)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		getErr := tx.First(&registration, "event_id = ? AND user_id = ? AND status <> ?",
+			eventID, userID, entity.CANCELLED).Error
+		if getErr != nil {
+			return getErr
+		}
+
+		wasRegistered := registration.Status == entity.REGISTERED
+		now := time.Now()
+		registration.Status = entity.CANCELLED
+		registration.UpdatedAt = now
+		cancelErr := tx.Model(&registration).Updates(map[string]any{
+			"status":     entity.CANCELLED,
+			"updated_at": now,
+		}).Error
+		if cancelErr != nil {
+			return cancelErr
+		}
+
+		if !wasRegistered {
+			return nil
+		}
+
+		var promoteErr error
+		promoted, promoteErr = r._PromoteWaitlist(tx, event, now)
+		return promoteErr
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &registration, promoted, nil
+}
+
+// Checks a participant in unless the event is full. Registered participants always
+// have their seat, anyone else is admitted only while seats are left.
+// Returns ErrEventFull when there is no seat for the participant.
+func (r *repository) CreateParticipantWithinCapacity(participant *entity.EventParticipants, ctx context.Context) (*entity.EventParticipants, error) {
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		event, lockErr := r._LockEvent(tx, participant.EventID)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		if event.Capacity != nil {
+			var registered int64
+			regErr := tx.Model(&entity.EventRegistration{}).
+				Where("event_id = ? AND user_id = ? AND status = ?",
+					participant.EventID, participant.ParticipantID, entity.REGISTERED).
+				Count(&registered).Error
+			if regErr != nil {
+				return regErr
+			}
+
+			if registered == 0 {
+				taken, countErr := r._CountTakenSeats(tx, participant.EventID)
+				if countErr != nil {
+					return countErr
+				}
+				if taken >= int64(*event.Capacity) {
+					return ErrEventFull
+				}
+			}
+		}
+
+		return tx.Omit(clause.Associations).Create(participant).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return participant, nil
+}
+
+// locks the event row so that seat counting and the write that follows are serialised per event
+func (r *repository) _LockEvent(tx *gorm.DB, eventID datatypes.UUID) (*entity.Event, error) {
+	var event entity.Event
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "capacity").
+		First(&event, "id = ?", eventID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Fills free seats from the waitlist in registration order until the event is
+// full again, and sends everyone promoted a WAITLIST_PROMOTED notification.
+// Every path that frees seats calls this while holding the _LockEvent lock.
+func (r *repository) _PromoteWaitlist(tx *gorm.DB, event *entity.Event, now time.Time) ([]entity.EventRegistration, error) {
+	promoted := []entity.EventRegistration{}
+	if event.Capacity == nil {
+		return promoted, nil
+	}
+
+	for {
+		taken, countErr := r._CountTakenSeats(tx, event.ID)
+		if countErr != nil {
+			return nil, countErr
+		}
+		if taken >= int64(*event.Capacity) {
+			return promoted, nil
+		}
+
+		var next entity.EventRegistration
+		nextErr := tx.Where("event_id = ? AND status = ?", event.ID, entity.WAITLISTED).
+			Order("registered_at").Order("id").
+			First(&next).Error
+		if errors.Is(nextErr, gorm.ErrRecordNotFound) {
+			return promoted, nil
+		}
+		if nextErr != nil {
+			return nil, nextErr
+		}
+
+		next.Status = entity.REGISTERED
+		next.UpdatedAt = now
+		promoteErr := tx.Model(&next).Updates(map[string]any{
+			"status":     entity.REGISTERED,
+			"updated_at": now,
+		}).Error
+		if promoteErr != nil {
+			return nil, promoteErr
+		}
+		notifyErr := tx.Exec(`INSERT INTO notifications (user_id, event_id, kind)
+			SELECT u.id, @event, 'WAITLIST_PROMOTED'
+			FROM users u
+			WHERE u.id = @user AND u.anonymised_at IS NULL
+			`+notificationRequeue,
+			sql.Named("event", event.ID), sql.Named("user", next.UserID)).Error
+		if notifyErr != nil {
+			return nil, notifyErr
+		}
+		promoted = append(promoted, next)
+	}
+}
+
+// seats are taken by registered users plus participants who checked in without a registration
+func (r *repository) _CountTakenSeats(tx *gorm.DB, eventID datatypes.UUID) (int64, error) {
+	var taken int64
+	err := tx.Raw(`SELECT
+		(SELECT COUNT(*) FROM event_registrations r
+			WHERE r.event_id = @event AND r.status = @registered)
+		+ (SELECT COUNT(*) FROM event_participants ep
+			WHERE ep.event_id = @event AND NOT EXISTS (
+				SELECT 1 FROM event_registrations r
+				WHERE r.event_id = ep.event_id AND r.user_id = ep.participant_id
+				AND r.status = @registered
+			))`,
+		sql.Named("event", eventID), sql.Named("registered", entity.REGISTERED)).Scan(&taken).Error
+	return taken, err
+}
@@ -7,16 +7,56 @@ type repository struct {
 }
 
 type AllRepo struct {
-	HealthCheck HealthCheckRepository
-	Auth        AuthRepository
-	Event       EventRepository
+	HealthCheck  HealthCheckRepository
+	Auth         AuthRepository
+	Event        EventRepository
+	Participant  ParticipantRepository
+	Idempotency  IdempotencyRepository
+	Tag          TagRepository
+	Registration RegistrationRepository
+	Series       SeriesRepository
+	Template     TemplateRepository
+	Calendar     CalendarRepository
+	Photo        PhotoRepository
+	Account      AccountRepository
+	Consent      ConsentRepository
+	Retention    RetentionRepository
+	Audit        AuditRepository
+	SelfCheckin  SelfCheckinRepository
+	Fraud        FraudRepository
+	Analytics    AnalyticsRepository
+	Organizer    OrganizerRepository
+	Evaluation   EvaluationRepository
+	Notification NotificationRepository
+	Announcement AnnouncementRepository
+	Transaction  TransactionRepository
 }
 
 func NewRepository(db *gorm.DB) AllRepo {
 	repo := &repository{db: db}
 	return AllRepo{
-		HealthCheck: repo,
-		Auth:        repo,
-		Event:       repo,
+		HealthCheck:  repo,
+		Auth:         repo,
+		Event:        repo,
+		Participant:  repo,
+		Idempotency:  repo,
+		Tag:          repo,
+		Registration: repo,
+		Series:       repo,
+		Template:     repo,
+		Calendar:     repo,
+		Photo:        repo,
+		Account:      repo,
+		Consent:      repo,
+		Retention:    repo,
+		Audit:        repo,
+		SelfCheckin:  repo,
+		Fraud:        repo,
+		Analytics:    repo,
+		Organizer:    repo,
+		Evaluation:   repo,
+		Notification: repo,
+		Announcement: repo,
+		Transaction:  repo,
 	}
 }
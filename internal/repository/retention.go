@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type RetentionRepository interface {
+	GetEventsDueForPurge(now time.Time, limit int, ctx context.Context) (*[]entity.Event, error)
+	PurgeEventPersonalData(eventID datatypes.UUID, now time.Time, ctx context.Context) (*entity.RetentionPurge, error)
+	GetRetentionPurges(page int, pageSize int, ctx context.Context) (*[]entity.RetentionPurge, int64, error)
+}
+
+// ended events whose retention period is over and that still hold personal data
+func (r *repository) GetEventsDueForPurge(now time.Time, limit int, ctx context.Context) (*[]entity.Event, error) {
+	var events []entity.Event
+	err := r._DB(ctx).
+		Where("retention_days IS NOT NULL AND retention_purged_at IS NULL").
+		Where("end_time + retention_days * interval '1 day' <= ?", now).
+		Order("end_time").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return &events, nil
+}
+
+// Anonymises the event's check-ins and registrations while keeping every count.
+// Each participant is moved onto a new anonymised user that exists only for this
+// event, so registered/attended/no-show/walk-in figures still join up but
+// nothing links the rows to the person or to their other events. Scan
+// locations are rounded to two decimals (about 1 km).
+// Returns gorm.ErrRecordNotFound if the event was purged in the meantime.
+func (r *repository) PurgeEventPersonalData(eventID datatypes.UUID, now time.Time, ctx context.Context) (*entity.RetentionPurge, error) {
+	var purge *entity.RetentionPurge
+
+	err := r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var event entity.Event
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&event, "id = ? AND retention_days IS NOT NULL AND retention_purged_at IS NULL", eventID).Error
+		if err != nil {
+			return err
+		}
+
+		createErr := tx.Exec(`CREATE TEMP TABLE retention_people (
+			person_id uuid PRIMARY KEY,
+			anonymous_id uuid NOT NULL
+		) ON COMMIT DROP`).Error
+		if createErr != nil {
+			return createErr
+		}
+
+		eventArg := sql.Named("event", eventID)
+		steps := []string{
+			`INSERT INTO retention_people (person_id, anonymous_id)
+				SELECT person_id, gen_random_uuid() FROM (
+					SELECT participant_id AS person_id FROM event_participants WHERE event_id = @event
+					UNION
+					SELECT user_id FROM event_registrations WHERE event_id = @event
+					UNION
+					SELECT user_id FROM event_evaluations WHERE event_id = @event
+				) people`,
+			`INSERT INTO users (id, ref_id, firstname_th, surname_th, title_th,
+				firstname_en, surname_en, title_en, is_admin, anonymised_at)
+				SELECT anonymous_id, nextval('anonymised_ref_id_seq'), '', '', '', '', '', '', false, @now
+				FROM retention_people`,
+			`UPDATE event_participants ep SET
+				participant_id = p.anonymous_id,
+				scanner_id = NULL,
+				comment = NULL,
+				idempotency_key = NULL,
+				scanned_location = point(
+					round(ep.scanned_location[0]::numeric, 2)::float8,
+					round(ep.scanned_location[1]::numeric, 2)::float8)
+				FROM retention_people p
+				WHERE ep.event_id = @event AND ep.participant_id = p.person_id`,
+			`UPDATE event_registrations r SET user_id = p.anonymous_id
+				FROM retention_people p
+				WHERE r.event_id = @event AND r.user_id = p.person_id`,
+			`UPDATE event_evaluations ev SET user_id = p.anonymous_id, comment = NULL
+				FROM retention_people p
+				WHERE ev.event_id = @event AND ev.user_id = p.person_id`,
+		}
+		for _, step := range steps {
+			if err := tx.Exec(step, eventArg, sql.Named("now", now)).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Where("event_id = ?", eventID).Delete(&entity.EventConsent{})
+		if result.Error != nil {
+			return result.Error
+		}
+		consents := result.RowsAffected
+
+		// queued notifications still name the people who were just anonymised
+		if err := tx.Where("event_id = ?", eventID).Delete(&entity.Notification{}).Error; err != nil {
+			return err
+		}
+
+		var participants, registrations int64
+		if err := tx.Model(&entity.EventParticipants{}).Where("event_id = ?", eventID).Count(&participants).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.EventRegistration{}).Where("event_id = ?", eventID).Count(&registrations).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entity.Event{}).Where("id = ?", eventID).Update("retention_purged_at", now).Error; err != nil {
+			return err
+		}
+
+		purge = &entity.RetentionPurge{
+			EventID:       &event.ID,
+			EventName:     event.Name,
+			EventEndTime:  event.EndTime,
+			RetentionDays: *event.RetentionDays,
+			Participants:  participants,
+			Registrations: registrations,
+			Consents:      consents,
+			PurgedAt:      now,
+		}
+		return tx.Omit(clause.Associations).Create(purge).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purge, nil
+}
+
+func (r *repository) GetRetentionPurges(page int, pageSize int, ctx context.Context) (*[]entity.RetentionPurge, int64, error) {
+	tx := r._DB(ctx)
+
+	var total int64
+	if err := tx.Model(&entity.RetentionPurge{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var purges []entity.RetentionPurge
+	err := tx.Order("purged_at DESC").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Find(&purges).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return &purges, total, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/datatypes"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type SelfCheckinRepository interface {
+	UpdateSelfCheckin(eventID datatypes.UUID, enabled bool, center *entity.Point, radiusMeters *uint32, rotationSeconds uint32, ctx context.Context) error
+}
+
+func (r *repository) UpdateSelfCheckin(eventID datatypes.UUID, enabled bool, center *entity.Point, radiusMeters *uint32, rotationSeconds uint32, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.Event{}).
+		Where("id = ?", eventID).
+		Updates(map[string]any{
+			"self_checkin_enabled":          enabled,
+			"self_checkin_center":           center,
+			"self_checkin_radius_meters":    radiusMeters,
+			"self_checkin_rotation_seconds": rotationSeconds,
+		}).Error
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type SeriesRepository interface {
+	CreateSeries(series *entity.EventSeries, occurrences []entity.Event, staff []entity.EventSeriesUser, whitelist []uint64, faculties []uint8, ctx context.Context) error
+	GetSeriesById(seriesID datatypes.UUID, ctx context.Context) (*entity.EventSeries, error)
+	GetSeriesOccurrences(seriesID datatypes.UUID, ctx context.Context) (*[]entity.Event, error)
+	GetSeriesUserRole(seriesID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*string, error)
+	UpdateSeries(seriesID datatypes.UUID, update entity.SeriesUpdate, ctx context.Context) error
+	UpdateOccurrence(seriesID datatypes.UUID, eventID datatypes.UUID, updates map[string]any, ctx context.Context) error
+	GetSeriesAttendance(seriesID datatypes.UUID, page int, pageSize int, ctx context.Context) (attendance *[]entity.GetSeriesAttendance, total int64, held int64, err error)
+}
+
+// creates the series and all of its occurrences with the shared settings in one transaction
+func (r *repository) CreateSeries(series *entity.EventSeries, occurrences []entity.Event, staff []entity.EventSeriesUser, whitelist []uint64, faculties []uint8, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(series).Error; err != nil {
+			return err
+		}
+
+		for i := range staff {
+			staff[i].SeriesID = series.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&staff).Error; err != nil {
+			return err
+		}
+
+		for i := range occurrences {
+			occurrences[i].SeriesID = &series.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&occurrences).Error; err != nil {
+			return err
+		}
+
+		eventIDs := make([]datatypes.UUID, len(occurrences))
+		for i, occurrence := range occurrences {
+			eventIDs[i] = occurrence.ID
+		}
+		eventStaff := _SeriesStaffToEventUsers(staff)
+		return r._ReplaceEventAccess(tx, eventIDs, &eventStaff, &whitelist, &faculties)
+	})
+}
+
+func (r *repository) GetSeriesById(seriesID datatypes.UUID, ctx context.Context) (*entity.EventSeries, error) {
+	var series entity.EventSeries
+	err := r._DB(ctx).First(&series, "id = ?", seriesID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *repository) GetSeriesOccurrences(seriesID datatypes.UUID, ctx context.Context) (*[]entity.Event, error) {
+	var occurrences []entity.Event
+	err := r._DB(ctx).
+		Where("series_id = ?", seriesID).
+		Order("start_time").
+		Find(&occurrences).Error
+	if err != nil {
+		return nil, err
+	}
+	return &occurrences, nil
+}
+
+// returns nil role (and no error) when the user has no role in the series
+func (r *repository) GetSeriesUserRole(seriesID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*string, error) {
+	var roles []string
+	err := r._DB(ctx).Model(&entity.EventSeriesUser{}).
+		Where("series_id = ? AND user_id = ?", seriesID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	return &roles[0], nil
+}
+
+// Applies a series edit to the series row and its upcoming occurrences.
+// Occurrences that already started are history and are left as they were.
+// Where the capacity is raised the waitlist is promoted into the new seats.
+func (r *repository) UpdateSeries(seriesID datatypes.UUID, update entity.SeriesUpdate, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		upcoming := func() *gorm.DB {
+			return tx.Model(&entity.Event{}).Where("series_id = ? AND start_time > now()", seriesID)
+		}
+
+		// occurrences getting a new capacity are locked like any other seat change
+		var resized []datatypes.UUID
+		if _, ok := update.Details["capacity"]; ok {
+			if err := upcoming().Where("NOT series_detached").Order("id").Pluck("id", &resized).Error; err != nil {
+				return err
+			}
+			for _, eventID := range resized {
+				if _, err := r._LockEvent(tx, eventID); err != nil {
+					return err
+				}
+			}
+		}
+
+		seriesUpdates := map[string]any{}
+		for column, value := range update.Details {
+			seriesUpdates[column] = value
+		}
+		for column, value := range update.Access {
+			seriesUpdates[column] = value
+		}
+		if len(seriesUpdates) > 0 {
+			if err := tx.Model(&entity.EventSeries{}).Where("id = ?", seriesID).Updates(seriesUpdates).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(update.Details) > 0 {
+			if err := upcoming().Where("NOT series_detached").Updates(update.Details).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		for _, eventID := range resized {
+			event, err := r._LockEvent(tx, eventID)
+			if err != nil {
+				return err
+			}
+			if _, err := r._PromoteWaitlist(tx, event, now); err != nil {
+				return err
+			}
+		}
+		if len(update.Access) > 0 {
+			if err := upcoming().Updates(update.Access).Error; err != nil {
+				return err
+			}
+		}
+
+		// owners are kept, the new staff list replaces everyone else
+		var staff *[]entity.EventUser
+		if update.Staff != nil {
+			newStaff := *update.Staff
+			for i := range newStaff {
+				newStaff[i].SeriesID = seriesID
+			}
+			if err := tx.Where("series_id = ? AND role <> ?", seriesID, entity.OWNER).Delete(&entity.EventSeriesUser{}).Error; err != nil {
+				return err
+			}
+			if len(newStaff) > 0 {
+				if err := tx.Omit(clause.Associations).Create(&newStaff).Error; err != nil {
+					return err
+				}
+			}
+
+			var allStaff []entity.EventSeriesUser
+			if err := tx.Where("series_id = ?", seriesID).Find(&allStaff).Error; err != nil {
+				return err
+			}
+			eventStaff := _SeriesStaffToEventUsers(allStaff)
+			staff = &eventStaff
+		}
+
+		if update.Staff == nil && update.Whitelist == nil && update.Faculties == nil {
+			return nil
+		}
+		var eventIDs []datatypes.UUID
+		if err := upcoming().Pluck("id", &eventIDs).Error; err != nil {
+			return err
+		}
+		return r._ReplaceEventAccess(tx, eventIDs, staff, update.Whitelist, update.Faculties)
+	})
+}
+
+// edits one occurrence and detaches it, so later series edits no longer overwrite its details.
+// A raised capacity promotes the waitlist into the new seats.
+func (r *repository) UpdateOccurrence(seriesID datatypes.UUID, eventID datatypes.UUID, updates map[string]any, ctx context.Context) error {
+	updates["series_detached"] = true
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		_, resized := updates["capacity"]
+		if resized {
+			if _, err := r._LockEvent(tx, eventID); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&entity.Event{}).
+			Where("id = ? AND series_id = ?", eventID, seriesID).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if !resized {
+			return nil
+		}
+
+		event, err := r._LockEvent(tx, eventID)
+		if err != nil {
+			return err
+		}
+		_, err = r._PromoteWaitlist(tx, event, time.Now())
+		return err
+	})
+}
+
+// How many occurrences each participant attended, most first, and how many
+// occurrences have been held so far.
+func (r *repository) GetSeriesAttendance(seriesID datatypes.UUID, page int, pageSize int, ctx context.Context) (*[]entity.GetSeriesAttendance, int64, int64, error) {
+	tx := r._DB(ctx)
+
+	var held int64
+	heldErr := tx.Model(&entity.Event{}).
+		Where("series_id = ? AND start_time <= now()", seriesID).
+		Count(&held).Error
+	if heldErr != nil {
+		return nil, 0, 0, heldErr
+	}
+
+	subQuery := func() *gorm.DB {
+		return tx.Table("event_participants ep").
+			Select("u.id AS user_id", "u.ref_id", "u.firstname_th", "u.surname_th",
+				"u.firstname_en", "u.surname_en", "up.user_id IS NOT NULL AS has_photo", "COUNT(*) AS attended").
+			Joins("JOIN events e ON e.id = ep.event_id").
+			Joins("JOIN users u ON u.id = ep.participant_id").
+			Joins("LEFT JOIN user_photos up ON up.user_id = u.id").
+			Where("e.series_id = ?", seriesID).
+			Group("u.id, up.user_id")
+	}
+
+	var total int64
+	countErr := tx.Raw(`SELECT COUNT(*) FROM (?) AS subQuery`, subQuery()).Scan(&total).Error
+	if countErr != nil {
+		return nil, 0, 0, countErr
+	}
+
+	var attendance []entity.GetSeriesAttendance
+	err := subQuery().
+		Order("attended DESC").
+		Order("u.ref_id").
+		Offset(page * pageSize).
+		Limit(pageSize).
+		Scan(&attendance).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return &attendance, total, held, nil
+}
+
+func _SeriesStaffToEventUsers(staff []entity.EventSeriesUser) []entity.EventUser {
+	users := make([]entity.EventUser, len(staff))
+	for i, member := range staff {
+		users[i] = entity.EventUser{UserID: member.UserID, Role: member.Role}
+	}
+	return users
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type TagRepository interface {
+	GetTags(ctx context.Context) (*[]entity.Tag, error)
+	GetTagById(tagID datatypes.UUID, ctx context.Context) (*entity.Tag, error)
+	GetTagsBySlugs(slugs []string, ctx context.Context) (*[]entity.Tag, error)
+	CreateTag(tag *entity.Tag, ctx context.Context) (*entity.Tag, error)
+	UpdateTag(tag *entity.Tag, ctx context.Context) error
+	DeleteTag(tagID datatypes.UUID, ctx context.Context) (int64, error)
+	GetEventTags(eventID datatypes.UUID, ctx context.Context) (*[]entity.Tag, error)
+	SetEventTags(eventID datatypes.UUID, tagIDs []datatypes.UUID, ctx context.Context) error
+}
+
+func (r *repository) GetTags(ctx context.Context) (*[]entity.Tag, error) {
+	var tags []entity.Tag
+	err := r._DB(ctx).Order("slug").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tags, nil
+}
+
+func (r *repository) GetTagById(tagID datatypes.UUID, ctx context.Context) (*entity.Tag, error) {
+	var tag entity.Tag
+	err := r._DB(ctx).First(&tag, "id = ?", tagID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *repository) GetTagsBySlugs(slugs []string, ctx context.Context) (*[]entity.Tag, error) {
+	var tags []entity.Tag
+	err := r._DB(ctx).Where("slug IN ?", slugs).Order("slug").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tags, nil
+}
+
+func (r *repository) CreateTag(tag *entity.Tag, ctx context.Context) (*entity.Tag, error) {
+	err := r._DB(ctx).Create(tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *repository) UpdateTag(tag *entity.Tag, ctx context.Context) error {
+	return r._DB(ctx).Model(&entity.Tag{}).
+		Where("id = ?", tag.ID).
+		Updates(map[string]any{
+			"slug":    tag.Slug,
+			"name_th": tag.NameTH,
+			"name_en": tag.NameEN,
+		}).Error
+}
+
+func (r *repository) DeleteTag(tagID datatypes.UUID, ctx context.Context) (int64, error) {
+	tx := r._DB(ctx).Delete(&entity.Tag{}, "id = ?", tagID)
+	return tx.RowsAffected, tx.Error
+}
+
+func (r *repository) GetEventTags(eventID datatypes.UUID, ctx context.Context) (*[]entity.Tag, error) {
+	var tags []entity.Tag
+	err := r._DB(ctx).Table("tags t").
+		Select("t.id", "t.slug", "t.name_th", "t.name_en").
+		Joins("JOIN event_tags et ON et.tag_id = t.id").
+		Where("et.event_id = ?", eventID).
+		Order("t.slug").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tags, nil
+}
+
+// replaces all tags of the event
+func (r *repository) SetEventTags(eventID datatypes.UUID, tagIDs []datatypes.UUID, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.EventTag{}, "event_id = ?", eventID).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		eventTags := make([]entity.EventTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			eventTags[i] = entity.EventTag{EventID: eventID, TagID: tagID}
+		}
+		return tx.Omit(clause.Associations).Create(&eventTags).Error
+	})
+}
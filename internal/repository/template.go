@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+type TemplateRepository interface {
+	GetEventCopySource(eventID datatypes.UUID, ctx context.Context) (*entity.GetEventCopySource, error)
+	CreateEventCopy(event *entity.Event, agenda []entity.EventAgenda, staff []entity.EventUser, whitelist []uint64, faculties []uint8, tagIDs []datatypes.UUID, ctx context.Context) error
+	GetTemplates(ownerID datatypes.UUID, ctx context.Context) (*[]entity.EventTemplate, error)
+	GetTemplateById(templateID datatypes.UUID, ownerID datatypes.UUID, ctx context.Context) (*entity.EventTemplate, error)
+	CreateTemplate(template *entity.EventTemplate, ctx context.Context) (*entity.EventTemplate, error)
+	DeleteTemplate(templateID datatypes.UUID, ownerID datatypes.UUID, ctx context.Context) (int64, error)
+}
+
+// loads the event with its agenda, whitelist, allowed faculties, staff and tags
+func (r *repository) GetEventCopySource(eventID datatypes.UUID, ctx context.Context) (*entity.GetEventCopySource, error) {
+	tx := r._DB(ctx)
+	source := entity.GetEventCopySource{}
+
+	if err := tx.First(&source.Event, "id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("event_id = ?", eventID).Order("start_time").Find(&source.Agenda).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&entity.EventWhitelist{}).Where("event_id = ?", eventID).
+		Order("attendee_ref_id").Pluck("attendee_ref_id", &source.Whitelist).Error; err != nil {
+		return nil, err
+	}
+	var faculties []int
+	if err := tx.Model(&entity.EventAllowedFaculties{}).Where("event_id = ?", eventID).
+		Order("faculty_no").Pluck("faculty_no", &faculties).Error; err != nil {
+		return nil, err
+	}
+	for _, facultyNO := range faculties {
+		source.Faculties = append(source.Faculties, uint8(facultyNO))
+	}
+
+	if err := tx.Where("event_id = ?", eventID).Find(&source.Staff).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&entity.EventTag{}).Where("event_id = ?", eventID).
+		Pluck("tag_id", &source.TagIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return &source, nil
+}
+
+// creates a new event together with its agenda, access lists and tags in one transaction
+func (r *repository) CreateEventCopy(event *entity.Event, agenda []entity.EventAgenda, staff []entity.EventUser, whitelist []uint64, faculties []uint8, tagIDs []datatypes.UUID, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(event).Error; err != nil {
+			return err
+		}
+
+		if len(agenda) > 0 {
+			for i := range agenda {
+				agenda[i].EventID = event.ID
+			}
+			if err := tx.Omit(clause.Associations).Create(&agenda).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(tagIDs) > 0 {
+			eventTags := make([]entity.EventTag, len(tagIDs))
+			for i, tagID := range tagIDs {
+				eventTags[i] = entity.EventTag{EventID: event.ID, TagID: tagID}
+			}
+			if err := tx.Omit(clause.Associations).Create(&eventTags).Error; err != nil {
+				return err
+			}
+		}
+
+		return r._ReplaceEventAccess(tx, []datatypes.UUID{event.ID}, &staff, &whitelist, &faculties)
+	})
+}
+
+func (r *repository) GetTemplates(ownerID datatypes.UUID, ctx context.Context) (*[]entity.EventTemplate, error) {
+	var templates []entity.EventTemplate
+	err := r._DB(ctx).
+		Where("owner_id = ?", ownerID).
+		Order("updated_at DESC").
+		Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return &templates, nil
+}
+
+// templates are private, another user's template is reported as not found
+func (r *repository) GetTemplateById(templateID datatypes.UUID, ownerID datatypes.UUID, ctx context.Context) (*entity.EventTemplate, error) {
+	var template entity.EventTemplate
+	err := r._DB(ctx).
+		First(&template, "id = ? AND owner_id = ?", templateID, ownerID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *repository) CreateTemplate(template *entity.EventTemplate, ctx context.Context) (*entity.EventTemplate, error) {
+	err := r._DB(ctx).Omit(clause.Associations).Create(template).Error
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *repository) DeleteTemplate(templateID datatypes.UUID, ownerID datatypes.UUID, ctx context.Context) (int64, error) {
+	result := r._DB(ctx).
+		Where("id = ? AND owner_id = ?", templateID, ownerID).
+		Delete(&entity.EventTemplate{})
+	return result.RowsAffected, result.Error
+}
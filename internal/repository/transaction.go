@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type TransactionRepository interface {
+	WithinTransaction(fn func(ctx context.Context) error, ctx context.Context) error
+}
+
+type txKey struct{}
+
+// Runs fn in one transaction. Repository calls made with the ctx passed to fn
+// join it, so they commit or roll back together; fn's error rolls back.
+func (r *repository) WithinTransaction(fn func(ctx context.Context) error, ctx context.Context) error {
+	return r._DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// the transaction WithinTransaction put in ctx, or the connection pool outside one
+func (r *repository) _DB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/gorm"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const ErrOwnsEvents = "OWNS_EVENTS"
+
+type AccountService interface {
+	ExportMyDataService(userIDStr string, ctx context.Context) (*dtoRes.DataExportRes, *response.APIError)
+	ExportMyDataArchiveService(userIDStr string, ctx context.Context) ([]byte, *response.APIError)
+	DeleteMyAccountService(userIDStr string, ctx context.Context) *response.APIError
+}
+
+func (s *service) ExportMyDataService(userIDStr string, ctx context.Context) (*dtoRes.DataExportRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	export, err := s.repo.Account.GetUserDataExport(userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "User not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "AccountRepository.GetUserDataExport").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	profile := export.Profile
+	res := dtoRes.DataExportRes{
+		ExportedAt: time.Now().UTC(),
+		Profile: dtoRes.DataExportProfileRes{
+			ID:          profile.ID.String(),
+			RefID:       s.FormatRefIdToStr(profile.RefID),
+			TitleTH:     profile.TitleTH,
+			FirstnameTH: profile.FirstnameTH,
+			SurnameTH:   profile.SurnameTH,
+			TitleEN:     profile.TitleEN,
+			FirstnameEN: profile.FirstnameEN,
+			SurnameEN:   profile.SurnameEN,
+			IsAdmin:     profile.IsAdmin,
+		},
+		EventRoles:     []dtoRes.DataExportEventRoleRes{},
+		SeriesRoles:    []dtoRes.DataExportSeriesRoleRes{},
+		OrganizerRoles: []dtoRes.DataExportOrganizerRoleRes{},
+		Registrations:  []dtoRes.DataExportRegistrationRes{},
+		Attendance:     []dtoRes.DataExportAttendanceRes{},
+		Evaluations:    []dtoRes.DataExportEvaluationRes{},
+		Ratings:        []dtoRes.DataExportRatingRes{},
+		Templates:      []dtoRes.DataExportTemplateRes{},
+	}
+
+	for _, role := range export.EventRoles {
+		res.EventRoles = append(res.EventRoles, dtoRes.DataExportEventRoleRes{
+			EventID:   role.EventID.String(),
+			EventName: role.EventName,
+			Role:      role.Role,
+		})
+	}
+	for _, role := range export.SeriesRoles {
+		res.SeriesRoles = append(res.SeriesRoles, dtoRes.DataExportSeriesRoleRes{
+			SeriesID:   role.SeriesID.String(),
+			SeriesName: role.SeriesName,
+			Role:       role.Role,
+		})
+	}
+	for _, role := range export.OrganizerRoles {
+		res.OrganizerRoles = append(res.OrganizerRoles, dtoRes.DataExportOrganizerRoleRes{
+			OrganizerID:   role.OrganizerID.String(),
+			OrganizerName: role.OrganizerName,
+			Role:          role.Role,
+		})
+	}
+	for _, registration := range export.Registrations {
+		res.Registrations = append(res.Registrations, dtoRes.DataExportRegistrationRes{
+			EventID:      registration.EventID.String(),
+			EventName:    registration.EventName,
+			Status:       registration.Status,
+			RegisteredAt: registration.RegisteredAt.UTC(),
+			UpdatedAt:    registration.UpdatedAt.UTC(),
+		})
+	}
+	for _, attendance := range export.Attendance {
+		var checkin *time.Time
+		if attendance.CheckinTimestamp != nil {
+			utc := attendance.CheckinTimestamp.UTC()
+			checkin = &utc
+		}
+		var location *dtoRes.ScanLocationRes
+		if attendance.ScannedLocation != nil {
+			location = &dtoRes.ScanLocationRes{
+				X: attendance.ScannedLocation.X,
+				Y: attendance.ScannedLocation.Y,
+			}
+		}
+		res.Attendance = append(res.Attendance, dtoRes.DataExportAttendanceRes{
+			EventID:          attendance.EventID.String(),
+			EventName:        attendance.EventName,
+			EventStartTime:   attendance.EventStartTime.UTC(),
+			CheckinTimestamp: checkin,
+			ScannedTimestamp: attendance.ScannedTimestamp.UTC(),
+			ScannedLocation:  location,
+			Method:           attendance.Method,
+			Organization:     attendance.Organization,
+			Comment:          attendance.Comment,
+		})
+		if attendance.EvaluationForm != nil {
+			res.Evaluations = append(res.Evaluations, dtoRes.DataExportEvaluationRes{
+				EventID:        attendance.EventID.String(),
+				EventName:      attendance.EventName,
+				EvaluationForm: *attendance.EvaluationForm,
+			})
+		}
+	}
+	for _, rating := range export.Ratings {
+		res.Ratings = append(res.Ratings, dtoRes.DataExportRatingRes{
+			EventID:     rating.EventID.String(),
+			EventName:   rating.EventName,
+			Rating:      rating.Rating,
+			Comment:     rating.Comment,
+			SubmittedAt: rating.SubmittedAt.UTC(),
+		})
+	}
+	for _, template := range export.Templates {
+		data, marshalErr := json.Marshal(template.Data)
+		if marshalErr != nil {
+			s.logger.Error().Err(marshalErr).
+				Str("template_id", template.ID.String()).
+				Msg("Failed to marshal template data")
+			return nil, &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Failed to build data export",
+				Status:  500,
+			}
+		}
+		res.Templates = append(res.Templates, dtoRes.DataExportTemplateRes{
+			ID:        template.ID.String(),
+			Name:      template.Name,
+			Data:      data,
+			CreatedAt: template.CreatedAt.UTC(),
+			UpdatedAt: template.UpdatedAt.UTC(),
+		})
+	}
+	if export.Photo != nil {
+		res.Photo = &dtoRes.DataExportPhotoRes{
+			Width:     export.Photo.Width,
+			Height:    export.Photo.Height,
+			UpdatedAt: export.Photo.UpdatedAt.UTC(),
+		}
+	}
+	if export.CalendarToken != nil {
+		res.CalendarSubscription = &dtoRes.DataExportCalendarTokenRes{
+			CreatedAt: export.CalendarToken.CreatedAt.UTC(),
+		}
+	}
+	if preference := export.NotificationPreference; preference != nil {
+		res.NotificationPreferences = &dtoRes.DataExportNotificationPreferencesRes{
+			Channels:          preference.Channels,
+			Email:             preference.Email,
+			LineConnected:     preference.LineUserID != nil,
+			EventReminders:    preference.EventReminders,
+			EvaluationNotices: preference.EvaluationNotices,
+			StaffNotices:      preference.StaffNotices,
+			UpdatedAt:         preference.UpdatedAt.UTC(),
+		}
+	}
+
+	return &res, nil
+}
+
+// the same export as a ZIP of data.json, with the user's photo as photo.jpg when there is one
+func (s *service) ExportMyDataArchiveService(userIDStr string, ctx context.Context) ([]byte, *response.APIError) {
+	export, exportErr := s.ExportMyDataService(userIDStr, ctx)
+	if exportErr != nil {
+		return nil, exportErr
+	}
+	archiveErr := &response.APIError{
+		Code:    response.ErrInternalError,
+		Message: "Failed to build data export",
+		Status:  500,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	modified := export.ExportedAt
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to marshal data export")
+		return nil, archiveErr
+	}
+	if err := _WriteZipFile(archive, "data.json", data, modified); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write data export archive")
+		return nil, archiveErr
+	}
+
+	if export.Photo != nil {
+		userID, _ := s._ParseUserID(userIDStr)
+		photo, photoErr := s.repo.Photo.GetUserPhoto(userID, ctx)
+		if photoErr != nil {
+			s.logger.Error().Err(photoErr).
+				Str("function", "PhotoRepository.GetUserPhoto").
+				Msg("Internal DB error")
+			return nil, archiveErr
+		}
+		image, blobErr := s.blob.Get(photo.ObjectKey, ctx)
+		if blobErr != nil {
+			s.logger.Error().Err(blobErr).
+				Str("function", "BlobStore.Get").
+				Msg("Failed to read photo")
+			return nil, archiveErr
+		}
+		if err := _WriteZipFile(archive, "photo.jpg", image, modified); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to write data export archive")
+			return nil, archiveErr
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write data export archive")
+		return nil, archiveErr
+	}
+	return buf.Bytes(), nil
+}
+
+// Deletes the account by anonymising it. Check-ins and registrations stay so
+// organizers keep their attendance counts, but nothing links them to the person.
+func (s *service) DeleteMyAccountService(userIDStr string, ctx context.Context) *response.APIError {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	events, series, organizers, err := s.repo.Account.CountSoleOwnerships(userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "AccountRepository.CountSoleOwnerships").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if events > 0 || series > 0 || organizers > 0 {
+		return &response.APIError{
+			Code:    ErrOwnsEvents,
+			Message: fmt.Sprintf("You are the only owner of %d event(s), %d series and %d organizer(s), add another owner before deleting your account", events, series, organizers),
+			Status:  409,
+		}
+	}
+
+	// give up held seats first, so waitlisted participants are promoted into them
+	eventIDs, err := s.repo.Account.GetOpenRegistrationEventIDs(userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "AccountRepository.GetOpenRegistrationEventIDs").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	for _, eventID := range eventIDs {
+		_, promoted, cancelErr := s.repo.Registration.CancelRegistration(eventID, userID, ctx)
+		if cancelErr != nil && !errors.Is(cancelErr, gorm.ErrRecordNotFound) {
+			s.logger.Error().Err(cancelErr).
+				Str("event_id", eventID.String()).
+				Str("function", "RegistrationRepository.CancelRegistration").
+				Msg("Internal DB error")
+			return &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Internal DB error",
+				Status:  500,
+			}
+		}
+		for _, registration := range promoted {
+			s.logger.Info().
+				Str("event_id", eventID.String()).
+				Str("user_id", registration.UserID.String()).
+				Msg("Promoted from waitlist")
+		}
+	}
+
+	photo, photoErr := s.repo.Photo.GetUserPhoto(userID, ctx)
+	if photoErr != nil && !errors.Is(photoErr, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(photoErr).
+			Str("function", "PhotoRepository.GetUserPhoto").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	anonymiseErr := s.repo.Account.AnonymiseUser(userID, ctx)
+	if errors.Is(anonymiseErr, gorm.ErrRecordNotFound) {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "User not found",
+			Status:  404,
+		}
+	}
+	if anonymiseErr != nil {
+		s.logger.Error().Err(anonymiseErr).
+			Str("function", "AccountRepository.AnonymiseUser").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if photo != nil {
+		s._DeletePhotoObject(photo.ObjectKey, ctx)
+	}
+	s.logger.Info().Str("user_id", userID.String()).Msg("Account anonymised")
+	return nil
+}
+
+func _WriteZipFile(archive *zip.Writer, name string, data []byte, modified time.Time) error {
+	file, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
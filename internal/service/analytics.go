@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const (
+	analyticsBucket     = 5 * time.Minute
+	analyticsPeakWindow = 15 * time.Minute
+	// how far before the start and after the end of the event the timeline reaches
+	analyticsMargin = 2 * time.Hour
+)
+
+type AnalyticsService interface {
+	GetEventAnalyticsService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.EventAnalyticsRes, *response.APIError)
+}
+
+// Check-ins over time, by organization and by scanner, with the busiest
+// analyticsPeakWindow of arrivals and the no-show rate of registered users
+func (s *service) GetEventAnalyticsService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.EventAnalyticsRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	analytics, err := s.repo.Analytics.GetEventAnalytics(eventID, analyticsBucket, analyticsPeakWindow, analyticsMargin, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "AnalyticsRepository.GetEventAnalytics").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := dtoRes.EventAnalyticsRes{
+		Methods:               []dtoRes.AnalyticsCountRes{},
+		BucketMinutes:         int(analyticsBucket / time.Minute),
+		TimelineMarginMinutes: int(analyticsMargin / time.Minute),
+		Timeline:              []dtoRes.AnalyticsBucketRes{},
+		OutsideTimeline:       analytics.OutsideTimeline,
+		Organizations:         []dtoRes.AnalyticsCountRes{},
+		Scanners:              []dtoRes.AnalyticsScannerRes{},
+	}
+	for _, method := range analytics.Methods {
+		res.TotalCheckins += method.Count
+		res.Methods = append(res.Methods, dtoRes.AnalyticsCountRes{Key: method.Key, Count: method.Count})
+	}
+	for _, bucket := range analytics.Timeline {
+		res.Timeline = append(res.Timeline, dtoRes.AnalyticsBucketRes{
+			Start:    bucket.BucketStart.UTC(),
+			Checkins: bucket.Checkins,
+		})
+	}
+	if analytics.Peak != nil {
+		res.Peak = &dtoRes.AnalyticsPeakRes{
+			Start:    analytics.Peak.BucketStart.UTC(),
+			End:      analytics.Peak.BucketStart.Add(analyticsPeakWindow).UTC(),
+			Checkins: analytics.Peak.WindowCheckins,
+		}
+	}
+	for _, organization := range analytics.Organizations {
+		res.Organizations = append(res.Organizations, dtoRes.AnalyticsCountRes{Key: organization.Key, Count: organization.Count})
+	}
+	for _, scanner := range analytics.Scanners {
+		row := dtoRes.AnalyticsScannerRes{
+			Checkins:  scanner.Checkins,
+			FirstScan: scanner.FirstScan.UTC(),
+			LastScan:  scanner.LastScan.UTC(),
+			PerMinute: scanner.PerMinute,
+		}
+		if scanner.ScannerID != nil {
+			id := scanner.ScannerID.String()
+			row.ScannerID = &id
+		}
+		res.Scanners = append(res.Scanners, row)
+	}
+
+	stats, statsErr := s._RegistrationStats(eventID, ctx)
+	if statsErr != nil {
+		return nil, statsErr
+	}
+	if stats.Registered > 0 {
+		res.Registrations = stats
+	}
+
+	return &res, nil
+}
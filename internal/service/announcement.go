@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/repository"
+	"gorm.io/datatypes"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const ErrRateLimited = "RATE_LIMITED"
+
+const (
+	maxAnnouncementLength = 1000
+	// how many of the newest announcements GetOneEventRes lists
+	announcementFeedSize = 20
+)
+
+var announcementAudiences = []string{
+	string(entity.AUDIENCE_REGISTERED),
+	string(entity.AUDIENCE_CHECKED_IN),
+	string(entity.AUDIENCE_STAFF),
+}
+
+type AnnouncementService interface {
+	CreateAnnouncementService(eventIDStr string, userIDStr string, req *dtoReq.CreateAnnouncementReq, ctx context.Context) (*dtoRes.CreateAnnouncementRes, *response.APIError)
+}
+
+// Posts an announcement to the event's feed and queues it on the notification
+// channels of everyone in the audience. Each event may make at most
+// ANNOUNCE_RATE_LIMIT announcements within ANNOUNCE_RATE_WINDOW.
+func (s *service) CreateAnnouncementService(eventIDStr string, userIDStr string, req *dtoReq.CreateAnnouncementReq, ctx context.Context) (*dtoRes.CreateAnnouncementRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	announcement := entity.EventAnnouncement{
+		EventID:  eventID,
+		AuthorID: &userID,
+	}
+	switch req.Audience {
+	case string(entity.AUDIENCE_REGISTERED):
+		announcement.Audience = entity.AUDIENCE_REGISTERED
+	case string(entity.AUDIENCE_CHECKED_IN):
+		announcement.Audience = entity.AUDIENCE_CHECKED_IN
+	case string(entity.AUDIENCE_STAFF):
+		announcement.Audience = entity.AUDIENCE_STAFF
+	default:
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'audience' must be one of " + strings.Join(announcementAudiences, ", "),
+			Status:  400,
+		}
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" || utf8.RuneCountInString(message) > maxAnnouncementLength {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: fmt.Sprintf("'message' must be between 1 and %d characters", maxAnnouncementLength),
+			Status:  400,
+		}
+	}
+
+	event, err := s.repo.Participant.GetEventById(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if event.RetentionPurgedAt != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Personal data of this event has been purged, there is no one left to announce to",
+			Status:  409,
+		}
+	}
+
+	cfg := s.cfg.AnnouncementConfig
+	announcement.Message = message
+	announcement.CreatedAt = time.Now()
+
+	var res dtoRes.CreateAnnouncementRes
+	err = s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		recipients, err := s.repo.Announcement.CreateAnnouncement(&announcement, max(cfg.RateLimit, 1), announcement.CreatedAt.Add(-cfg.RateWindow), ctx)
+		if err != nil {
+			return err
+		}
+		res = dtoRes.CreateAnnouncementRes{
+			AnnouncementRes: _AnnouncementDTOFormat(&announcement),
+			Recipients:      recipients,
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventAnnouncementCreate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			After:      res,
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, repository.ErrAnnouncementRateLimited) {
+		return nil, &response.APIError{
+			Code:    ErrRateLimited,
+			Message: fmt.Sprintf("This event can make at most %d announcements every %s", max(cfg.RateLimit, 1), cfg.RateWindow),
+			Status:  429,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "AnnouncementRepository.CreateAnnouncement").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return &res, nil
+}
+
+// the event's newest announcements the user may read, staff read all of them
+func (s *service) _EventAnnouncements(eventID datatypes.UUID, userID datatypes.UUID, staff bool, ctx context.Context) ([]dtoRes.AnnouncementRes, *response.APIError) {
+	announcements, err := s.repo.Announcement.GetEventAnnouncements(eventID, userID, staff, announcementFeedSize, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "AnnouncementRepository.GetEventAnnouncements").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := make([]dtoRes.AnnouncementRes, len(announcements))
+	for i := range announcements {
+		res[i] = _AnnouncementDTOFormat(&announcements[i])
+	}
+	return res, nil
+}
+
+func _AnnouncementDTOFormat(announcement *entity.EventAnnouncement) dtoRes.AnnouncementRes {
+	return dtoRes.AnnouncementRes{
+		ID:        announcement.ID.String(),
+		Audience:  string(announcement.Audience),
+		Message:   announcement.Message,
+		CreatedAt: announcement.CreatedAt.UTC(),
+	}
+}
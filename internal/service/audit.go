@@ -0,0 +1,509 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/requestctx"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+// audit log actions, named <target>.<change>
+const (
+	auditEventCreate             = "event.create"
+	auditEventUpdate             = "event.update"
+	auditEventTagsUpdate         = "event.tags.update"
+	auditEventPrivacyUpdate      = "event.privacy.update"
+	auditEventActivityUpdate     = "event.activity.update"
+	auditEventSelfCheckinUpdate  = "event.self_checkin.update"
+	auditEventFraudRulesUpdate   = "event.fraud_rules.update"
+	auditEventOrganizerUpdate    = "event.organizer.update"
+	auditEventAnnouncementCreate = "event.announcement.create"
+	auditEventPurge              = "event.retention_purge"
+
+	auditSeriesCreate        = "series.create"
+	auditSeriesUpdate        = "series.update"
+	auditSeriesMembersUpdate = "series.members.update"
+	auditSeriesAccessUpdate  = "series.access.update"
+
+	auditParticipantCreate = "participant.create"
+	auditParticipantUpdate = "participant.update"
+	auditParticipantRevoke = "participant.revoke"
+	auditParticipantReveal = "participant.reveal_all"
+
+	auditOrganizerCreate        = "organizer.create"
+	auditOrganizerUpdate        = "organizer.update"
+	auditOrganizerMembersUpdate = "organizer.members.update"
+
+	auditTagCreate = "tag.create"
+	auditTagUpdate = "tag.update"
+	auditTagDelete = "tag.delete"
+)
+
+const (
+	auditTargetEvent       = "event"
+	auditTargetSeries      = "series"
+	auditTargetParticipant = "participant"
+	auditTargetTag         = "tag"
+	auditTargetOrganizer   = "organizer"
+)
+
+type AuditService interface {
+	GetEventAuditLogsService(eventIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.AuditLogRes, *response.Pagination, *response.APIError)
+	GetAuditLogsService(queryParams map[string]string, ctx context.Context) (*[]dtoRes.AuditLogRes, *response.Pagination, *response.APIError)
+}
+
+// one change passed to _Audit, Before is nil for a creation and After for a deletion
+type auditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	EventID    *datatypes.UUID
+	SeriesID   *datatypes.UUID
+	Before     any
+	After      any
+	Reason     *string
+}
+
+type auditMember struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// The audit snapshots below copy the fields of an entity that can change.
+// Entities are not logged as they are, their UUIDs would marshal as byte arrays.
+type auditEvent struct {
+	Name           string    `json:"name"`
+	Organizer      string    `json:"organizer"`
+	Description    *string   `json:"description"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Location       string    `json:"location"`
+	AttendenceType string    `json:"attendance_type"`
+	AllowAllToScan bool      `json:"allow_all_to_scan"`
+	EvaluationForm *string   `json:"evaluation_form"`
+	RevealedFields []string  `json:"revealed_fields"`
+	Capacity       *uint32   `json:"capacity"`
+	SeriesID       *string   `json:"series_id"`
+	SeriesDetached bool      `json:"series_detached"`
+	PrivacyNotice  *string   `json:"privacy_notice"`
+	RetentionDays  *uint32   `json:"retention_days"`
+}
+
+type auditSeries struct {
+	Name           string    `json:"name"`
+	Organizer      string    `json:"organizer"`
+	Description    *string   `json:"description"`
+	Location       string    `json:"location"`
+	RRule          string    `json:"rrule"`
+	FirstStartTime time.Time `json:"first_start_time"`
+	FirstEndTime   time.Time `json:"first_end_time"`
+	AttendenceType string    `json:"attendance_type"`
+	AllowAllToScan bool      `json:"allow_all_to_scan"`
+	EvaluationForm *string   `json:"evaluation_form"`
+	RevealedFields []string  `json:"revealed_fields"`
+	Capacity       *uint32   `json:"capacity"`
+}
+
+type auditTag struct {
+	Slug   string `json:"slug"`
+	NameTH string `json:"name_th"`
+	NameEN string `json:"name_en"`
+}
+
+// Who checked in, where and their comment are left out on purpose: the log is
+// never purged, so it must not undo the retention job's anonymisation.
+type auditParticipant struct {
+	CheckinTimestamp *time.Time `json:"checkin_timestamp"`
+	ScannedTimestamp time.Time  `json:"scanned_timestamp"`
+	ScannerID        *string    `json:"scanner_id"`
+	Organization     string     `json:"organization"`
+	Method           string     `json:"method"`
+}
+
+// the event's audit trail, including series-wide changes of its series, for its owners
+func (s *service) GetEventAuditLogsService(eventIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.AuditLogRes, *response.Pagination, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER)}, ctx); roleErr != nil {
+		return nil, nil, roleErr
+	}
+
+	filter, page, pageSize, queryErr := s._ParseAuditQuery(queryParams, false)
+	if queryErr != nil {
+		return nil, nil, queryErr
+	}
+	filter.EventID = &eventID
+
+	return s._GetAuditLogs(filter, page, pageSize, ctx)
+}
+
+// the whole audit trail for admins, narrowed by optional query parameters
+func (s *service) GetAuditLogsService(queryParams map[string]string, ctx context.Context) (*[]dtoRes.AuditLogRes, *response.Pagination, *response.APIError) {
+	filter, page, pageSize, queryErr := s._ParseAuditQuery(queryParams, true)
+	if queryErr != nil {
+		return nil, nil, queryErr
+	}
+
+	return s._GetAuditLogs(filter, page, pageSize, ctx)
+}
+
+// returned by _Audit when the audit log was not written, the change it records must be rolled back
+var errAuditFailed = errors.New("audit log not written")
+
+// Records a privileged change. It is called in the transaction that makes the
+// change, see TransactionRepository, so a change is never committed without
+// its audit log: a failed write returns errAuditFailed to roll it back. The
+// actor and request ID come from the request context, so jobs are recorded
+// without an actor. Entries where nothing changed are skipped.
+func (s *service) _Audit(entry auditEntry, ctx context.Context) error {
+	before, after, changed, err := _AuditDiff(entry.Before, entry.After)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("action", entry.Action).
+			Msg("Failed to build audit log diff")
+		return errAuditFailed
+	}
+	if !changed {
+		return nil
+	}
+
+	log := entity.AuditLog{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		EventID:    entry.EventID,
+		SeriesID:   entry.SeriesID,
+		Before:     before,
+		After:      after,
+		Reason:     entry.Reason,
+	}
+	if entry.TargetID != "" {
+		log.TargetID = &entry.TargetID
+	}
+	info := requestctx.From(ctx)
+	if uuid.Validate(info.UserID) == nil {
+		actorID := datatypes.UUID(datatypes.BinUUIDFromString(info.UserID))
+		log.ActorID = &actorID
+	}
+	if info.RequestID != "" {
+		log.RequestID = &info.RequestID
+	}
+
+	if err := s.repo.Audit.CreateAuditLog(&log, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("action", entry.Action).
+			Str("request_id", info.RequestID).
+			Str("function", "AuditRepository.CreateAuditLog").
+			Msg("Failed to write audit log")
+		return errAuditFailed
+	}
+	return nil
+}
+
+// the response to a change rolled back because its audit log was not written,
+// the failure itself was already logged by _Audit
+func _AuditFailedError() *response.APIError {
+	return &response.APIError{
+		Code:    response.ErrInternalError,
+		Message: "Failed to write audit log",
+		Status:  500,
+	}
+}
+
+// Reduces two JSON objects to the top-level fields that differ. A nil side is
+// kept as null, so a creation stores the whole new object and no before.
+func _AuditDiff(before any, after any) (datatypes.JSON, datatypes.JSON, bool, error) {
+	beforeMap, err := _AuditObject(before)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	afterMap, err := _AuditObject(after)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if beforeMap != nil && afterMap != nil {
+		changedBefore, changedAfter := map[string]any{}, map[string]any{}
+		for key, value := range beforeMap {
+			if !reflect.DeepEqual(value, afterMap[key]) {
+				changedBefore[key] = value
+				changedAfter[key] = afterMap[key]
+			}
+		}
+		for key, value := range afterMap {
+			if _, seen := beforeMap[key]; !seen && value != nil {
+				changedBefore[key] = nil
+				changedAfter[key] = value
+			}
+		}
+		beforeMap, afterMap = changedBefore, changedAfter
+		if len(afterMap) == 0 {
+			return nil, nil, false, nil
+		}
+	}
+	if beforeMap == nil && afterMap == nil {
+		return nil, nil, false, nil
+	}
+
+	var beforeJSON, afterJSON datatypes.JSON
+	if beforeMap != nil {
+		if beforeJSON, err = json.Marshal(beforeMap); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	if afterMap != nil {
+		if afterJSON, err = json.Marshal(afterMap); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	return beforeJSON, afterJSON, true, nil
+}
+
+// nil for a nil value, including typed nil pointers
+func _AuditObject(value any) (map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func _AuditEvent(event *entity.Event) auditEvent {
+	snapshot := auditEvent{
+		Name:           event.Name,
+		Organizer:      event.Organizer,
+		Description:    event.Description,
+		StartTime:      event.StartTime.UTC(),
+		EndTime:        event.EndTime.UTC(),
+		Location:       event.Location,
+		AttendenceType: string(event.AttendenceType),
+		AllowAllToScan: event.AllowAllToScan,
+		EvaluationForm: event.EvaluationForm,
+		RevealedFields: []string{},
+		Capacity:       event.Capacity,
+		SeriesDetached: event.SeriesDetached,
+		PrivacyNotice:  event.PrivacyNotice,
+		RetentionDays:  event.RetentionDays,
+	}
+	for _, field := range event.RevealedFields {
+		snapshot.RevealedFields = append(snapshot.RevealedFields, string(field))
+	}
+	if event.SeriesID != nil {
+		seriesID := event.SeriesID.String()
+		snapshot.SeriesID = &seriesID
+	}
+	return snapshot
+}
+
+func _AuditSeries(series *entity.EventSeries) auditSeries {
+	snapshot := auditSeries{
+		Name:           series.Name,
+		Organizer:      series.Organizer,
+		Description:    series.Description,
+		Location:       series.Location,
+		RRule:          series.RRule,
+		FirstStartTime: series.FirstStartTime.UTC(),
+		FirstEndTime:   series.FirstEndTime.UTC(),
+		AttendenceType: string(series.AttendenceType),
+		AllowAllToScan: series.AllowAllToScan,
+		EvaluationForm: series.EvaluationForm,
+		RevealedFields: []string{},
+		Capacity:       series.Capacity,
+	}
+	for _, field := range series.RevealedFields {
+		snapshot.RevealedFields = append(snapshot.RevealedFields, string(field))
+	}
+	return snapshot
+}
+
+func _AuditTag(tag *entity.Tag) auditTag {
+	return auditTag{Slug: tag.Slug, NameTH: tag.NameTH, NameEN: tag.NameEN}
+}
+
+func _AuditParticipant(participant *entity.EventParticipants) auditParticipant {
+	snapshot := auditParticipant{
+		CheckinTimestamp: participant.CheckinTimestamp,
+		ScannedTimestamp: participant.ScannedTimestamp.UTC(),
+		Organization:     participant.Organization,
+		Method:           string(participant.Method),
+	}
+	if participant.ScannerID != nil {
+		scannerID := participant.ScannerID.String()
+		snapshot.ScannerID = &scannerID
+	}
+	return snapshot
+}
+
+func _AuditSeriesMembers(members []entity.EventSeriesUser) []auditMember {
+	res := []auditMember{}
+	for _, member := range members {
+		res = append(res, auditMember{UserID: member.UserID.String(), Role: string(member.Role)})
+	}
+	return res
+}
+
+// Audit rows cannot be changed, so they must not hold the student ref IDs an
+// erasure request or retention purge has to remove. A whitelist is recorded as
+// its size, and a change as how many ref IDs it added and removed.
+func _AuditWhitelist(before []uint64, after []uint64) (map[string]int, map[string]int) {
+	kept := map[uint64]bool{}
+	for _, refID := range before {
+		kept[refID] = true
+	}
+	added := 0
+	for _, refID := range after {
+		if kept[refID] {
+			delete(kept, refID)
+		} else {
+			added++
+		}
+	}
+
+	beforeSize := map[string]int{"size": len(before)}
+	afterSize := map[string]int{"size": len(after)}
+	// left out when zero, so an unchanged whitelist is not logged as a change
+	if added > 0 {
+		afterSize["added"] = added
+	}
+	if removed := len(kept); removed > 0 {
+		afterSize["removed"] = removed
+	}
+	return beforeSize, afterSize
+}
+
+func (s *service) _ParseAuditQuery(queryParams map[string]string, admin bool) (entity.AuditLogFilter, int, int, *response.APIError) {
+	filter := entity.AuditLogFilter{
+		Action:     queryParams["action"],
+		TargetType: queryParams["target_type"],
+	}
+
+	page := 0
+	if pageQuery, ok := queryParams["page"]; ok {
+		pageInt, err := strconv.Atoi(pageQuery)
+		if err != nil || pageInt < 0 {
+			return filter, 0, 0, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'page' must be a non-negative int",
+				Status:  400,
+			}
+		}
+		page = pageInt
+	}
+	pageSize := 50
+	if sizeQuery, ok := queryParams["pageSize"]; ok {
+		sizeInt, err := strconv.Atoi(sizeQuery)
+		if err != nil || sizeInt < 1 || sizeInt > 100 {
+			return filter, 0, 0, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'pageSize' must be an int from 1 to 100",
+				Status:  400,
+			}
+		}
+		pageSize = sizeInt
+	}
+
+	for name, bound := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value, ok := queryParams[name]
+		if !ok {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return filter, 0, 0, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter '" + name + "' must be an RFC 3339 timestamp",
+				Status:  400,
+			}
+		}
+		*bound = &parsed
+	}
+
+	if !admin {
+		return filter, page, pageSize, nil
+	}
+	for name, id := range map[string]**datatypes.UUID{"event_id": &filter.EventID, "series_id": &filter.SeriesID, "actor_id": &filter.ActorID} {
+		value, ok := queryParams[name]
+		if !ok {
+			continue
+		}
+		if uuid.Validate(value) != nil {
+			return filter, 0, 0, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter '" + name + "' must be a UUID",
+				Status:  400,
+			}
+		}
+		parsed := datatypes.UUID(datatypes.BinUUIDFromString(value))
+		*id = &parsed
+	}
+	return filter, page, pageSize, nil
+}
+
+func (s *service) _GetAuditLogs(filter entity.AuditLogFilter, page int, pageSize int, ctx context.Context) (*[]dtoRes.AuditLogRes, *response.Pagination, *response.APIError) {
+	logs, total, err := s.repo.Audit.GetAuditLogs(filter, page, pageSize, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "AuditRepository.GetAuditLogs").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	optionalID := func(id *datatypes.UUID) *string {
+		if id == nil {
+			return nil
+		}
+		str := id.String()
+		return &str
+	}
+	res := []dtoRes.AuditLogRes{}
+	for _, log := range *logs {
+		res = append(res, dtoRes.AuditLogRes{
+			ID:         log.ID.String(),
+			ActorID:    optionalID(log.ActorID),
+			RequestID:  log.RequestID,
+			Action:     log.Action,
+			TargetType: log.TargetType,
+			TargetID:   log.TargetID,
+			EventID:    optionalID(log.EventID),
+			SeriesID:   optionalID(log.SeriesID),
+			Reason:     log.Reason,
+			Before:     json.RawMessage(log.Before),
+			After:      json.RawMessage(log.After),
+			CreatedAt:  log.CreatedAt.UTC(),
+		})
+	}
+
+	return &res, &response.Pagination{
+		Page:     &page,
+		PageSize: pageSize,
+		Total:    &total,
+		HasNext:  int64((page+1)*pageSize) < total,
+	}, nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cunex-club/quickattend-backend/internal/entity"
 	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
@@ -23,6 +24,7 @@ type AuthService interface {
 	GetUserService(string, context.Context) (*dtoRes.GetAuthUserRes, *response.APIError)
 	VerifyCUNEXToken(string, context.Context) (*dtoRes.VerifyTokenRes, *response.APIError)
 	CreateUserIfNotExists(*entity.User, context.Context) (*entity.User, *response.APIError)
+	GetQRCodeService(string, context.Context) (*dtoRes.GetQRCodeRes, *response.APIError)
 }
 
 func (s *service) GetUserService(userIDStr string, ctx context.Context) (*dtoRes.GetAuthUserRes, *response.APIError) {
@@ -63,6 +65,7 @@ func (s *service) GetUserService(userIDStr string, ctx context.Context) (*dtoRes
 		FirstnameEN: user.FirstnameEN,
 		SurnameEN:   user.SurnameEN,
 		TitleEN:     user.TitleEN,
+		IsAdmin:     user.IsAdmin,
 	}
 
 	return &userDTO, nil
@@ -230,10 +233,13 @@ func (s *service) VerifyCUNEXToken(token string, ctx context.Context) (*dtoRes.V
 		t   *jwt.Token
 	)
 
-	t = jwt.NewWithClaims(jwt.SigningMethodHS256,
-		jwt.MapClaims{
-			"user_id": createdUser.ID.String(),
-		})
+	claims := jwt.MapClaims{
+		"user_id": createdUser.ID.String(),
+	}
+	if createdUser.IsAdmin {
+		claims["role"] = entity.ADMIN
+	}
+	t = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	JWTSecret := s.cfg.JWTSecret
 	if JWTSecret == "" {
@@ -259,6 +265,33 @@ func (s *service) VerifyCUNEXToken(token string, ctx context.Context) (*dtoRes.V
 	}, nil
 }
 
+func (s *service) GetQRCodeService(userIDStr string, ctx context.Context) (*dtoRes.GetQRCodeRes, *response.APIError) {
+	uuidValidateErr := uuid.Validate(userIDStr)
+	if uuidValidateErr != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to validate user_id as UUID",
+			Status:  500,
+		}
+	}
+	userID := datatypes.UUID(datatypes.BinUUIDFromString(userIDStr))
+
+	code, expiresAt, signErr := s._SignParticipantQR(userID, time.Now())
+	if signErr != nil {
+		s.logger.Error().Err(signErr).Str("user_id", userIDStr).Msg("failed to sign participant QR code")
+		return nil, &response.APIError{
+			Code:    "QR_SIGN_FAIL",
+			Message: "failed to sign QR code",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.GetQRCodeRes{
+		Code:      code,
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
+
 func (s *service) FormatRefIdToStr(refId uint64) string {
 	str := fmt.Sprint(refId)
 	if len(str) < 8 {
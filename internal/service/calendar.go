@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+// the feed keeps recently ended events so calendars do not drop them right away
+const (
+	calendarFeedHistory   = 180 * 24 * time.Hour
+	calendarFeedMaxEvents = 500
+)
+
+type CalendarService interface {
+	GetEventICSService(eventIDStr string, userIDStr string, ctx context.Context) ([]byte, *response.APIError)
+	CreateCalendarTokenService(userIDStr string, ctx context.Context) (*dtoRes.CalendarTokenRes, *response.APIError)
+	DeleteCalendarTokenService(userIDStr string, ctx context.Context) *response.APIError
+	GetCalendarFeedService(token string, ctx context.Context) ([]byte, *response.APIError)
+}
+
+func (s *service) GetEventICSService(eventIDStr string, userIDStr string, ctx context.Context) ([]byte, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	event, err := s.repo.Participant.GetEventById(eventID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Event with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	calendarEvent := entity.GetEventsQueryResult{
+		ID:          event.ID,
+		Name:        event.Name,
+		Organizer:   event.Organizer,
+		Description: event.Description,
+		StartTime:   event.StartTime,
+		EndTime:     event.EndTime,
+		Location:    event.Location,
+	}
+	registration, registrationErr := s.repo.Registration.GetRegistration(eventID, userID, ctx)
+	if registrationErr != nil && !errors.Is(registrationErr, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(registrationErr).
+			Str("event_id", eventID.String()).
+			Str("function", "RegistrationRepository.GetRegistration").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if registration != nil {
+		status := string(registration.Status)
+		calendarEvent.RegistrationStatus = &status
+	}
+
+	agenda, agendaErr := s.repo.Calendar.GetEventAgendas([]datatypes.UUID{eventID}, ctx)
+	if agendaErr != nil {
+		s.logger.Error().Err(agendaErr).
+			Str("event_id", eventID.String()).
+			Str("function", "CalendarRepository.GetEventAgendas").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._BuildICS("", []entity.GetEventsQueryResult{calendarEvent}, *agenda, time.Now()), nil
+}
+
+// creates the user's calendar subscription token, replacing any previous one
+func (s *service) CreateCalendarTokenService(userIDStr string, ctx context.Context) (*dtoRes.CalendarTokenRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to generate calendar token")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to generate calendar token",
+			Status:  500,
+		}
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := s.repo.Calendar.SaveCalendarToken(userID, s._HashCalendarToken(token), ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "CalendarRepository.SaveCalendarToken").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.CalendarTokenRes{
+		Token:   token,
+		FeedURL: strings.TrimSuffix(s.cfg.PublicURL, "/") + "/api/calendar/" + token + ".ics",
+	}, nil
+}
+
+func (s *service) DeleteCalendarTokenService(userIDStr string, ctx context.Context) *response.APIError {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	deleted, err := s.repo.Calendar.DeleteCalendarToken(userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "CalendarRepository.DeleteCalendarToken").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if deleted == 0 {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "No calendar token to revoke",
+			Status:  404,
+		}
+	}
+	return nil
+}
+
+// serves the feed to calendar apps, the token in the URL takes the place of the JWT
+func (s *service) GetCalendarFeedService(token string, ctx context.Context) ([]byte, *response.APIError) {
+	notFound := &response.APIError{
+		Code:    response.ErrNotFound,
+		Message: "Calendar feed not found",
+		Status:  404,
+	}
+	if raw, err := base64.RawURLEncoding.DecodeString(token); err != nil || len(raw) != 32 {
+		return nil, notFound
+	}
+
+	userID, err := s.repo.Calendar.GetUserIDByCalendarToken(s._HashCalendarToken(token), ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "CalendarRepository.GetUserIDByCalendarToken").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	now := time.Now()
+	events, eventsErr := s.repo.Calendar.GetCalendarEvents(userID, now.Add(-calendarFeedHistory), calendarFeedMaxEvents, ctx)
+	if eventsErr != nil {
+		s.logger.Error().Err(eventsErr).
+			Str("function", "CalendarRepository.GetCalendarEvents").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	eventIDs := make([]datatypes.UUID, len(*events))
+	for i, event := range *events {
+		eventIDs[i] = event.ID
+	}
+	agenda, agendaErr := s.repo.Calendar.GetEventAgendas(eventIDs, ctx)
+	if agendaErr != nil {
+		s.logger.Error().Err(agendaErr).
+			Str("function", "CalendarRepository.GetEventAgendas").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._BuildICS("QuickAttend", *events, *agenda, now), nil
+}
+
+func (s *service) _HashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const ErrConsentRequired = "CONSENT_REQUIRED"
+
+type ConsentService interface {
+	AcceptPrivacyNoticeService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.ConsentRes, *response.APIError)
+	UpdateEventPrivacyService(eventIDStr string, userIDStr string, req *dtoReq.UpdateEventPrivacyReq, ctx context.Context) (*dtoRes.EventPrivacyRes, *response.APIError)
+}
+
+// lets a participant accept the notice ahead of time, e.g. before showing their QR code
+func (s *service) AcceptPrivacyNoticeService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.ConsentRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	event, eventErr := s._GetOpenEvent(eventID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+	if event.PrivacyNotice == nil {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "This event has no privacy notice",
+			Status:  404,
+		}
+	}
+
+	consent := &entity.EventConsent{
+		EventID:     eventID,
+		UserID:      userID,
+		NoticeHash:  _PrivacyNoticeHash(*event.PrivacyNotice),
+		AcceptedVia: entity.CONSENT_SELF,
+		AcceptedAt:  time.Now(),
+	}
+	if err := s.repo.Consent.SaveConsent(consent, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ConsentRepository.SaveConsent").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.ConsentRes{
+		EventID:     eventID.String(),
+		NoticeHash:  consent.NoticeHash,
+		AcceptedVia: string(consent.AcceptedVia),
+		AcceptedAt:  consent.AcceptedAt.UTC(),
+	}, nil
+}
+
+// replaces the event's privacy notice and retention period, nil removes either
+func (s *service) UpdateEventPrivacyService(eventIDStr string, userIDStr string, req *dtoReq.UpdateEventPrivacyReq, ctx context.Context) (*dtoRes.EventPrivacyRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	var notice *string
+	if req.PrivacyNotice != nil {
+		trimmed := strings.TrimSpace(*req.PrivacyNotice)
+		if trimmed == "" {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'privacy_notice' must not be empty, send null to remove it",
+				Status:  400,
+			}
+		}
+		notice = &trimmed
+	}
+	if req.RetentionDays != nil && *req.RetentionDays == 0 {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'retention_days' must be greater than 0, send null to keep data indefinitely",
+			Status:  400,
+		}
+	}
+
+	event, eventErr := s.repo.Participant.GetEventById(eventID, ctx)
+	if eventErr != nil {
+		s.logger.Error().Err(eventErr).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if event.RetentionPurgedAt != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Personal data of this event has already been purged",
+			Status:  409,
+		}
+	}
+
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Consent.UpdateEventPrivacy(eventID, notice, req.RetentionDays, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventPrivacyUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     map[string]any{"privacy_notice": event.PrivacyNotice, "retention_days": event.RetentionDays},
+			After:      map[string]any{"privacy_notice": notice, "retention_days": req.RetentionDays},
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ConsentRepository.UpdateEventPrivacy").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := &dtoRes.EventPrivacyRes{
+		PrivacyNotice: notice,
+		RetentionDays: req.RetentionDays,
+	}
+	if req.RetentionDays != nil {
+		purgeAfter := event.EndTime.AddDate(0, 0, int(*req.RetentionDays)).UTC()
+		res.PurgeAfter = &purgeAfter
+	}
+	return res, nil
+}
+
+// Makes sure the participant accepted the event's current privacy notice.
+// accepted records a fresh acceptance given at this registration or scan.
+func (s *service) _RequireConsent(event *entity.Event, userID datatypes.UUID, accepted bool, via string, ctx context.Context) *response.APIError {
+	if event.PrivacyNotice == nil {
+		return nil
+	}
+	noticeHash := _PrivacyNoticeHash(*event.PrivacyNotice)
+
+	consent, err := s.repo.Consent.GetConsent(event.ID, userID, ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(err).
+			Str("event_id", event.ID.String()).
+			Str("function", "ConsentRepository.GetConsent").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if consent != nil && consent.NoticeHash == noticeHash {
+		return nil
+	}
+	if !accepted {
+		return &response.APIError{
+			Code:    ErrConsentRequired,
+			Message: "The participant must accept this event's privacy notice first",
+			Status:  409,
+		}
+	}
+
+	newConsent := &entity.EventConsent{
+		EventID:    event.ID,
+		UserID:     userID,
+		NoticeHash: noticeHash,
+		AcceptedAt: time.Now(),
+	}
+	switch via {
+	case string(entity.CONSENT_REGISTRATION):
+		newConsent.AcceptedVia = entity.CONSENT_REGISTRATION
+	default:
+		newConsent.AcceptedVia = entity.CONSENT_SCAN
+	}
+	if err := s.repo.Consent.SaveConsent(newConsent, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", event.ID.String()).
+			Str("function", "ConsentRepository.SaveConsent").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return nil
+}
+
+// nil when the event has no notice, otherwise whether the user accepted the current one
+func (s *service) _PrivacyNoticeAccepted(eventID datatypes.UUID, userID datatypes.UUID, notice *string, ctx context.Context) (*bool, *response.APIError) {
+	if notice == nil {
+		return nil, nil
+	}
+	consent, err := s.repo.Consent.GetConsent(eventID, userID, ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ConsentRepository.GetConsent").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	accepted := consent != nil && consent.NoticeHash == _PrivacyNoticeHash(*notice)
+	return &accepted, nil
+}
+
+func _PrivacyNoticeHash(notice string) string {
+	sum := sha256.Sum256([]byte(notice))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const ErrNotAttended = "NOT_ATTENDED"
+
+type EvaluationService interface {
+	SubmitEvaluationService(eventIDStr string, userIDStr string, req *dtoReq.SubmitEvaluationReq, ctx context.Context) (*dtoRes.EvaluationRes, *response.APIError)
+}
+
+// Only attendees who checked in can rate the event. Submitting again replaces
+// the earlier rating.
+func (s *service) SubmitEvaluationService(eventIDStr string, userIDStr string, req *dtoReq.SubmitEvaluationReq, ctx context.Context) (*dtoRes.EvaluationRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'rating' must be between 1 and 5",
+			Status:  400,
+		}
+	}
+	var comment *string
+	if req.Comment != nil {
+		if trimmed := strings.TrimSpace(*req.Comment); trimmed != "" {
+			comment = &trimmed
+		}
+	}
+
+	_, err := s.repo.Participant.GetParticipant(eventID, userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    ErrNotAttended,
+			Message: "Only attendees who checked in can rate this event",
+			Status:  403,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetParticipant").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	evaluation := entity.EventEvaluation{
+		EventID:     eventID,
+		UserID:      userID,
+		Rating:      uint8(req.Rating),
+		Comment:     comment,
+		SubmittedAt: time.Now(),
+	}
+	if err := s.repo.Evaluation.SaveEvaluation(&evaluation, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "EvaluationRepository.SaveEvaluation").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.EvaluationRes{
+		EventID:     eventID.String(),
+		Rating:      evaluation.Rating,
+		Comment:     evaluation.Comment,
+		SubmittedAt: evaluation.SubmittedAt.UTC(),
+	}, nil
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
@@ -17,7 +21,8 @@ import (
 
 type EventService interface {
 	GetOneEventService(eventIdStr string, userIdStr string, ctx context.Context) (res *dtoRes.GetOneEventRes, err *response.APIError)
-	GetEventsService(userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.GetEventsRes, *response.Pagination, *response.APIError)
+	GetEventsService(userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.GetEventsRes, *response.Meta, *response.APIError)
+	GetHomeEventsService(userIDStr string, queryParams map[string]string, ctx context.Context) (*dtoRes.GetHomeEventsRes, *response.APIError)
 }
 
 func (s *service) GetOneEventService(eventIdStr string, userIdStr string, ctx context.Context) (*dtoRes.GetOneEventRes, *response.APIError) {
@@ -71,6 +76,61 @@ func (s *service) GetOneEventService(eventIdStr string, userIdStr string, ctx co
 		}
 	}
 
+	tags, tagsErr := s.repo.Tag.GetEventTags(eventId, ctx)
+	if tagsErr != nil {
+		s.logger.Error().Err(tagsErr).
+			Str("function", "TagRepository.GetEventTags").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	announcements, announcementsErr := s._EventAnnouncements(eventId, userId, eventWithCount.Role != nil, ctx)
+	if announcementsErr != nil {
+		return nil, announcementsErr
+	}
+
+	remainingSeats, seatsErr := s._RemainingSeats(eventId, eventWithCount.Capacity, ctx)
+	if seatsErr != nil {
+		return nil, seatsErr
+	}
+
+	var registrationStatus *string
+	registration, registrationErr := s.repo.Registration.GetRegistration(eventId, userId, ctx)
+	if registrationErr != nil && !errors.Is(registrationErr, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(registrationErr).
+			Str("function", "RegistrationRepository.GetRegistration").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if registration != nil {
+		status := string(registration.Status)
+		registrationStatus = &status
+	}
+
+	noticeAccepted, consentErr := s._PrivacyNoticeAccepted(eventId, userId, eventWithCount.PrivacyNotice, ctx)
+	if consentErr != nil {
+		return nil, consentErr
+	}
+	var purgedAt *time.Time
+	if eventWithCount.RetentionPurgedAt != nil {
+		utc := eventWithCount.RetentionPurgedAt.UTC()
+		purgedAt = &utc
+	}
+
+	var seriesID *string
+	if eventWithCount.SeriesID != nil {
+		id := eventWithCount.SeriesID.String()
+		seriesID = &id
+	}
+
 	finalRes := dtoRes.GetOneEventRes{
 		Name:            eventWithCount.Name,
 		Organizer:       eventWithCount.Organizer,
@@ -79,15 +139,38 @@ func (s *service) GetOneEventService(eventIdStr string, userIdStr string, ctx co
 		EndTime:         eventWithCount.EndTime.UTC(),
 		Location:        eventWithCount.Location,
 		TotalRegistered: eventWithCount.TotalRegistered,
+		TotalAttended:   eventWithCount.TotalAttended,
 		EvaluationForm:  eventWithCount.EvaluationForm,
 		Agenda:          agendaDTO,
 		Role:            eventWithCount.Role,
+		Capacity:        eventWithCount.Capacity,
+		RemainingSeats:  remainingSeats,
+		Registration:    registrationStatus,
+		SeriesID:        seriesID,
+		Tags:            *s._TagsDTOFormat(tags),
+		Announcements:   announcements,
+
+		PrivacyNotice:         eventWithCount.PrivacyNotice,
+		PrivacyNoticeAccepted: noticeAccepted,
+		RetentionDays:         eventWithCount.RetentionDays,
+		RetentionPurgedAt:     purgedAt,
+		SelfCheckinEnabled:    eventWithCount.SelfCheckinEnabled,
+		ActivityHours:         eventWithCount.ActivityHours,
+		ActivityCategory:      eventWithCount.ActivityCategory,
+	}
+
+	if eventWithCount.Role != nil {
+		attendance, statsErr := s._RegistrationStats(eventId, ctx)
+		if statsErr != nil {
+			return nil, statsErr
+		}
+		finalRes.Attendance = attendance
 	}
 
 	return &finalRes, nil
 }
 
-func (s *service) GetEventsService(userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.GetEventsRes, *response.Pagination, *response.APIError) {
+func (s *service) GetEventsService(userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.GetEventsRes, *response.Meta, *response.APIError) {
 	uuidValidationErr := uuid.Validate(userIDStr)
 	if uuidValidationErr != nil {
 		return nil, nil, &response.APIError{
@@ -140,106 +223,498 @@ func (s *service) GetEventsService(userIDStr string, queryParams map[string]stri
 		size = pageSizeInt
 	}
 
-	search := ""
-	searchQuery, searchOk := queryParams["search"]
-	if searchOk {
-		search = strings.TrimSpace(searchQuery)
-		if utf8.RuneCountInString(search) > 256 {
+	filter, filterErr := s._ParseEventsFilter(queryParams)
+	if filterErr != nil {
+		return nil, nil, filterErr
+	}
+
+	// 'cursor' present (even empty, for the first page) -> keyset pagination instead of 'page'
+	cursorQuery, cursorOk := queryParams["cursor"]
+	var cursor *entity.GetEventsCursor
+	if cursorOk && cursorQuery != "" {
+		decoded, err := s._DecodeEventsCursor(cursorQuery, filter)
+		if err != nil {
 			return nil, nil, &response.APIError{
 				Code:    response.ErrBadRequest,
-				Message: "URL query parameter 'search' longer than 256 characters",
+				Message: "URL query parameter 'cursor' is invalid",
 				Status:  400,
 			}
 		}
+		cursor = decoded
 	}
 
-	formattedRes := []dtoRes.GetEventsRes{}
-
-	managedQuery, managedOk := queryParams["managed"]
-	// 'managed' not present -> get discovery events
-	if !managedOk {
-		if !pageOk {
+	// the total is counted by default in page mode only
+	includeTotal := !cursorOk
+	includeTotalQuery, includeTotalOk := queryParams["includeTotal"]
+	if includeTotalOk {
+		includeTotalBool, err := strconv.ParseBool(includeTotalQuery)
+		if err != nil {
 			return nil, nil, &response.APIError{
 				Code:    response.ErrBadRequest,
-				Message: "Missing required URL query parameter: page",
+				Message: "URL query parameter 'includeTotal' must be boolean",
 				Status:  400,
 			}
 		}
-		res, total, hasNext, err := s.repo.Event.GetDiscoveryEvents(userID, page, size, search, ctx)
+		includeTotal = includeTotalBool
+	}
+
+	includeFacets := false
+	includeFacetsQuery, includeFacetsOk := queryParams["includeFacets"]
+	if includeFacetsOk {
+		includeFacetsBool, err := strconv.ParseBool(includeFacetsQuery)
 		if err != nil {
-			s.logger.Error().Err(err).
-				Str("user_id", userIDStr).
-				Str("function", "EventRepository.GetDiscoveryEvents").
-				Msg(fmt.Sprintf("Internal DB error: %s", err.Error()))
 			return nil, nil, &response.APIError{
-				Code:    response.ErrInternalError,
-				Message: "Internal DB error on getting discovery events",
-				Status:  500,
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'includeFacets' must be boolean",
+				Status:  400,
 			}
 		}
-		s._GetEventsDTOFormat(res, &formattedRes)
-		return &formattedRes, &response.Pagination{
-			Page:     page,
-			PageSize: size,
-			Total:    total,
-			HasNext:  hasNext,
-		}, nil
+		includeFacets = includeFacetsBool
 	}
 
-	// 'managed' present -> parse and get managed or participated events
-	managed, err := strconv.ParseBool(managedQuery)
-	if err != nil {
+	pageParams := entity.GetEventsPageParams{
+		Page:          page,
+		PageSize:      size,
+		UseCursor:     cursorOk,
+		Cursor:        cursor,
+		IncludeTotal:  includeTotal,
+		IncludeFacets: includeFacets,
+	}
+
+	if !pageOk && !cursorOk {
 		return nil, nil, &response.APIError{
 			Code:    response.ErrBadRequest,
-			Message: "URL query parameter 'managed' must be boolean",
+			Message: "Missing required URL query parameter: page or cursor",
 			Status:  400,
 		}
 	}
-	switch managed {
-	case true:
-		res, err := s.repo.Event.GetManagedEvents(userID, search, ctx)
+
+	// 'managed' not present -> get discovery events
+	section := eventsSectionDiscover
+	managedQuery, managedOk := queryParams["managed"]
+	if managedOk {
+		// 'managed' present -> parse and get managed or participated events
+		managed, err := strconv.ParseBool(managedQuery)
 		if err != nil {
-			s.logger.Error().Err(err).
-				Str("user_id", userIDStr).
-				Str("function", "EventRepository.GetManagedEvents").
-				Msg(fmt.Sprintf("Internal DB error: %s", err.Error()))
 			return nil, nil, &response.APIError{
-				Code:    response.ErrInternalError,
-				Message: "Internal DB error on getting managed events",
-				Status:  500,
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'managed' must be boolean",
+				Status:  400,
 			}
 		}
-		s._GetEventsDTOFormat(res, &formattedRes)
-		return &formattedRes, nil, nil
+		section = eventsSectionAttended
+		if managed {
+			section = eventsSectionManaged
+		}
+	}
+
+	eventsPage, err := s._GetEventsSection(section, userID, pageParams, filter, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	formattedRes := []dtoRes.GetEventsRes{}
+	s._GetEventsDTOFormat(eventsPage.Events, &formattedRes)
+	s._AddSearchHighlights(&formattedRes, filter.SearchTerms)
+	return &formattedRes, s._GetEventsMeta(eventsPage, pageParams, filter), nil
+}
+
+// GET /events/home returns the first page of every section so the home screen
+// needs a single request, each section carries a cursor for GET /events
+func (s *service) GetHomeEventsService(userIDStr string, queryParams map[string]string, ctx context.Context) (*dtoRes.GetHomeEventsRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	size := 8
+	sizeQuery, sizeOk := queryParams["pageSize"]
+	if sizeOk {
+		pageSizeInt, err := strconv.Atoi(sizeQuery)
+		if err != nil {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'pageSize' must be int",
+				Status:  400,
+			}
+		}
+		if pageSizeInt < 1 || pageSizeInt > 10 {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'pageSize' must be within range [1, 10]",
+				Status:  400,
+			}
+		}
+		size = pageSizeInt
+	}
+
+	filter, filterErr := s._ParseEventsFilter(queryParams)
+	if filterErr != nil {
+		return nil, filterErr
+	}
+
+	includeTotal := false
+	includeTotalQuery, includeTotalOk := queryParams["includeTotal"]
+	if includeTotalOk {
+		includeTotalBool, err := strconv.ParseBool(includeTotalQuery)
+		if err != nil {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'includeTotal' must be boolean",
+				Status:  400,
+			}
+		}
+		includeTotal = includeTotalBool
+	}
+
+	pageParams := entity.GetEventsPageParams{
+		PageSize:     size,
+		UseCursor:    true,
+		IncludeTotal: includeTotal,
+	}
+
+	home := dtoRes.GetHomeEventsRes{}
+	sections := []struct {
+		name   string
+		target *dtoRes.GetEventsSectionRes
+	}{
+		{eventsSectionManaged, &home.Managed},
+		{eventsSectionAttended, &home.Attended},
+		{eventsSectionDiscover, &home.Discover},
+	}
+	for _, section := range sections {
+		eventsPage, err := s._GetEventsSection(section.name, userID, pageParams, filter, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		events := []dtoRes.GetEventsRes{}
+		s._GetEventsDTOFormat(eventsPage.Events, &events)
+		s._AddSearchHighlights(&events, filter.SearchTerms)
+		meta := s._GetEventsMeta(eventsPage, pageParams, filter)
+
+		*section.target = dtoRes.GetEventsSectionRes{
+			Events:     events,
+			HasNext:    eventsPage.HasNext,
+			Total:      eventsPage.Total,
+			NextCursor: meta.NextCursor,
+		}
+	}
+
+	return &home, nil
+}
+
+const (
+	eventsSectionManaged  = "managed"
+	eventsSectionAttended = "attended"
+	eventsSectionDiscover = "discovery"
+)
+
+func (s *service) _GetEventsSection(section string, userID datatypes.UUID, pageParams entity.GetEventsPageParams, filter entity.GetEventsFilter, ctx context.Context) (*entity.GetEventsPage, *response.APIError) {
+	var (
+		eventsPage *entity.GetEventsPage
+		err        error
+		function   string
+	)
 
+	switch section {
+	case eventsSectionManaged:
+		function = "EventRepository.GetManagedEvents"
+		eventsPage, err = s.repo.Event.GetManagedEvents(userID, pageParams, filter, ctx)
+	case eventsSectionAttended:
+		function = "EventRepository.GetAttendedEvents"
+		eventsPage, err = s.repo.Event.GetAttendedEvents(userID, pageParams, filter, ctx)
 	default:
-		if !pageOk {
-			return nil, nil, &response.APIError{
+		function = "EventRepository.GetDiscoveryEvents"
+		eventsPage, err = s.repo.Event.GetDiscoveryEvents(userID, pageParams, filter, ctx)
+	}
+
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID.String()).
+			Str("function", function).
+			Msg(fmt.Sprintf("Internal DB error: %s", err.Error()))
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: fmt.Sprintf("Internal DB error on getting %s events", section),
+			Status:  500,
+		}
+	}
+
+	return eventsPage, nil
+}
+
+// eventsDateLayout is accepted by 'from' and 'to' alongside RFC 3339;
+// a bare date is a whole day in Bangkok time, so 'to' includes that day
+const eventsDateLayout = "2006-01-02"
+
+var bangkokTime = time.FixedZone("ICT", 7*60*60)
+
+func (s *service) _ParseEventsFilter(queryParams map[string]string) (entity.GetEventsFilter, *response.APIError) {
+	filter := entity.GetEventsFilter{SortColumn: entity.EventSortStartTime}
+
+	searchQuery, searchOk := queryParams["search"]
+	if searchOk {
+		filter.Search = strings.TrimSpace(searchQuery)
+		if utf8.RuneCountInString(filter.Search) > 256 {
+			return filter, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'search' longer than 256 characters",
+				Status:  400,
+			}
+		}
+		filter.SearchTerms = s._SearchTerms(filter.Search)
+		filter.SearchTSQuery = s._SearchTSQuery(filter.SearchTerms)
+	}
+	searching := len(filter.SearchTerms) > 0
+
+	statusQuery, statusOk := queryParams["status"]
+	if statusOk {
+		switch statusQuery {
+		case entity.EventStatusUpcoming, entity.EventStatusOngoing, entity.EventStatusPast:
+			filter.Status = statusQuery
+		default:
+			return filter, &response.APIError{
 				Code:    response.ErrBadRequest,
-				Message: "Missing required URL query parameter: page",
+				Message: "URL query parameter 'status' must be one of upcoming, ongoing, past",
 				Status:  400,
 			}
 		}
-		res, total, hasNext, err := s.repo.Event.GetAttendedEvents(userID, page, size, search, ctx)
+	}
+
+	for _, name := range []string{"from", "to"} {
+		value, ok := queryParams[name]
+		if !ok {
+			continue
+		}
+		parsed, isDate, err := s._ParseEventsTime(value)
 		if err != nil {
-			s.logger.Error().Err(err).
-				Str("user_id", userIDStr).
-				Str("function", "EventRepository.GetAttendedEvents").
-				Msg(fmt.Sprintf("Internal DB error: %s", err.Error()))
-			return nil, nil, &response.APIError{
-				Code:    response.ErrInternalError,
-				Message: "Internal DB error on getting attended events",
-				Status:  500,
+			return filter, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: fmt.Sprintf("URL query parameter '%s' must be a date (YYYY-MM-DD) or RFC 3339 time", name),
+				Status:  400,
+			}
+		}
+		if name == "from" {
+			filter.From = &parsed
+		} else {
+			if isDate {
+				parsed = parsed.AddDate(0, 0, 1)
+			}
+			filter.To = &parsed
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "URL query parameter 'from' must be before 'to'",
+			Status:  400,
+		}
+	}
+
+	organizerQuery, organizerOk := queryParams["organizer"]
+	if organizerOk {
+		filter.Organizer = strings.TrimSpace(organizerQuery)
+		if utf8.RuneCountInString(filter.Organizer) > 256 {
+			return filter, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'organizer' longer than 256 characters",
+				Status:  400,
+			}
+		}
+	}
+
+	tagQuery, tagOk := queryParams["tag"]
+	if tagOk {
+		for _, slug := range strings.Split(tagQuery, ",") {
+			slug = strings.ToLower(strings.TrimSpace(slug))
+			if slug == "" {
+				continue
+			}
+			if !tagSlugPattern.MatchString(slug) {
+				return filter, &response.APIError{
+					Code:    response.ErrBadRequest,
+					Message: "URL query parameter 'tag' must be a comma separated list of tag slugs",
+					Status:  400,
+				}
+			}
+			filter.Tags = append(filter.Tags, slug)
+		}
+		if len(filter.Tags) > maxTagsPerEvent {
+			return filter, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: fmt.Sprintf("URL query parameter 'tag' must not contain more than %d tags", maxTagsPerEvent),
+				Status:  400,
 			}
 		}
-		s._GetEventsDTOFormat(res, &formattedRes)
-		return &formattedRes, &response.Pagination{
-			Page:     page,
-			PageSize: size,
-			Total:    total,
-			HasNext:  hasNext,
-		}, nil
 	}
+
+	// search results are ranked by relevance, best first, unless another sort is requested
+	if searching {
+		filter.SortColumn = entity.EventSortRelevance
+		filter.Descending = true
+	}
+
+	sortQuery, sortOk := queryParams["sort"]
+	if sortOk {
+		switch sortQuery {
+		case entity.EventSortStartTime, entity.EventSortName, entity.EventSortOrganizer:
+			filter.SortColumn = sortQuery
+			filter.Descending = false
+		case entity.EventSortRelevance:
+			if !searching {
+				return filter, &response.APIError{
+					Code:    response.ErrBadRequest,
+					Message: "URL query parameter 'sort' can only be relevance when 'search' is given",
+					Status:  400,
+				}
+			}
+		default:
+			return filter, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'sort' must be one of start_time, name, organizer, relevance",
+				Status:  400,
+			}
+		}
+	}
+
+	orderQuery, orderOk := queryParams["order"]
+	if orderOk {
+		switch strings.ToLower(orderQuery) {
+		case "asc":
+			filter.Descending = false
+		case "desc":
+			filter.Descending = true
+		default:
+			return filter, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'order' must be asc or desc",
+				Status:  400,
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+func (s *service) _ParseEventsTime(value string) (time.Time, bool, error) {
+	if date, err := time.ParseInLocation(eventsDateLayout, value, bangkokTime); err == nil {
+		return date, true, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	return parsed, false, err
+}
+
+func (s *service) _GetEventsMeta(eventsPage *entity.GetEventsPage, params entity.GetEventsPageParams, filter entity.GetEventsFilter) *response.Meta {
+	meta := response.Meta{
+		Pagination: &response.Pagination{
+			PageSize: params.PageSize,
+			Total:    eventsPage.Total,
+			HasNext:  eventsPage.HasNext,
+		},
+	}
+
+	if eventsPage.Facets != nil {
+		tags := []response.TagFacet{}
+		for _, facet := range *eventsPage.Facets {
+			tags = append(tags, response.TagFacet{
+				Slug:   facet.Slug,
+				NameTH: facet.NameTH,
+				NameEN: facet.NameEN,
+				Count:  facet.Count,
+			})
+		}
+		meta.Facets = &response.Facets{Tags: tags}
+	}
+
+	if !params.UseCursor {
+		page := params.Page
+		meta.Pagination.Page = &page
+		return &meta
+	}
+
+	length := len(*eventsPage.Events)
+	if eventsPage.HasNext && length > 0 {
+		nextCursor := s._EncodeEventsCursor((*eventsPage.Events)[length-1], filter)
+		meta.NextCursor = &nextCursor
+	}
+	return &meta
+}
+
+// Cursors are opaque to clients: base64url of the last row's sort value and id.
+// They carry the sort they were issued for and are rejected under any other sort.
+type eventsCursorPayload struct {
+	Sort       string     `json:"s"`
+	Descending bool       `json:"d,omitempty"`
+	Time       *time.Time `json:"t,omitempty"`
+	Text       *string    `json:"v,omitempty"`
+	Number     *float64   `json:"n,omitempty"`
+	ID         string     `json:"id"`
+}
+
+func (s *service) _EncodeEventsCursor(last entity.GetEventsQueryResult, filter entity.GetEventsFilter) string {
+	payload := eventsCursorPayload{
+		Sort:       filter.SortColumn,
+		Descending: filter.Descending,
+		ID:         last.ID.String(),
+	}
+	switch filter.SortColumn {
+	case entity.EventSortName:
+		payload.Text = &last.Name
+	case entity.EventSortOrganizer:
+		payload.Text = &last.Organizer
+	case entity.EventSortRelevance:
+		relevance := 0.0
+		if last.Relevance != nil {
+			relevance = *last.Relevance
+		}
+		payload.Number = &relevance
+	default:
+		startTime := last.StartTime.UTC()
+		payload.Time = &startTime
+	}
+
+	encoded, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func (s *service) _DecodeEventsCursor(encoded string, filter entity.GetEventsFilter) (*entity.GetEventsCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload eventsCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Sort != filter.SortColumn || payload.Descending != filter.Descending {
+		return nil, fmt.Errorf("cursor was issued for a different sort")
+	}
+	if err := uuid.Validate(payload.ID); err != nil {
+		return nil, err
+	}
+
+	cursor := entity.GetEventsCursor{ID: datatypes.UUID(datatypes.BinUUIDFromString(payload.ID))}
+	switch filter.SortColumn {
+	case entity.EventSortName, entity.EventSortOrganizer:
+		if payload.Text == nil {
+			return nil, fmt.Errorf("cursor has no sort value")
+		}
+		cursor.Value = *payload.Text
+	case entity.EventSortRelevance:
+		if payload.Number == nil {
+			return nil, fmt.Errorf("cursor has no sort value")
+		}
+		cursor.Value = *payload.Number
+	default:
+		if payload.Time == nil {
+			return nil, fmt.Errorf("cursor has no sort value")
+		}
+		cursor.Value = *payload.Time
+	}
+
+	return &cursor, nil
 }
 
 func (s *service) _GetEventsDTOFormat(rawResult *[]entity.GetEventsQueryResult, result *[]dtoRes.GetEventsRes) {
@@ -256,6 +731,10 @@ func (s *service) _GetEventsDTOFormat(rawResult *[]entity.GetEventsQueryResult,
 				Location:       (*rawResult)[i].Location,
 				Role:           (*rawResult)[i].Role,
 				EvaluationForm: (*rawResult)[i].EvaluationForm,
+				Relevance:      (*rawResult)[i].Relevance,
+
+				RegistrationStatus: (*rawResult)[i].RegistrationStatus,
+				Attended:           (*rawResult)[i].Attended,
 			})
 		}
 	}
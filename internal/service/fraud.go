@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/datatypes"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+// how far apart two check-ins of one participant at different events may be
+// for DISTANT_SCANS to compare their locations
+const fraudDistantScanWindow = 15 * time.Minute
+
+// the heuristics in the order they are reported, with their default threshold
+var fraudRuleDefaults = []dtoRes.FraudRuleRes{
+	// a scanner's check-in less than threshold seconds after its previous one
+	{Rule: string(entity.FRAUD_RAPID_SCANS), Enabled: true, Threshold: 2, Unit: "seconds"},
+	// check-ins at exactly the same coordinates, more than threshold meters from the venue
+	{Rule: string(entity.FRAUD_REMOTE_LOCATION), Enabled: true, Threshold: 500, Unit: "meters"},
+	// a participant checked in to another event more than threshold meters away at about the same time
+	{Rule: string(entity.FRAUD_DISTANT_SCANS), Enabled: true, Threshold: 1000, Unit: "meters"},
+	// a check-in more than threshold minutes after the event ended
+	{Rule: string(entity.FRAUD_LATE_CHECKIN), Enabled: true, Threshold: 0, Unit: "minutes"},
+}
+
+type FraudService interface {
+	GetFraudRulesService(eventIDStr string, userIDStr string, ctx context.Context) (*[]dtoRes.FraudRuleRes, *response.APIError)
+	UpdateFraudRulesService(eventIDStr string, userIDStr string, req *dtoReq.UpdateFraudRulesReq, ctx context.Context) (*[]dtoRes.FraudRuleRes, *response.APIError)
+	GetFraudFlagsService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.FraudReportRes, *response.APIError)
+}
+
+func (s *service) GetFraudRulesService(eventIDStr string, userIDStr string, ctx context.Context) (*[]dtoRes.FraudRuleRes, *response.APIError) {
+	eventID, roleErr := s._RequireFraudReviewer(eventIDStr, userIDStr, ctx)
+	if roleErr != nil {
+		return nil, roleErr
+	}
+
+	rules, rulesErr := s._FraudRules(eventID, ctx)
+	if rulesErr != nil {
+		return nil, rulesErr
+	}
+	return &rules, nil
+}
+
+func (s *service) UpdateFraudRulesService(eventIDStr string, userIDStr string, req *dtoReq.UpdateFraudRulesReq, ctx context.Context) (*[]dtoRes.FraudRuleRes, *response.APIError) {
+	eventID, roleErr := s._RequireFraudReviewer(eventIDStr, userIDStr, ctx)
+	if roleErr != nil {
+		return nil, roleErr
+	}
+
+	rows := []entity.EventFraudRule{}
+	seen := map[string]bool{}
+	for _, rule := range req.Rules {
+		if seen[rule.Rule] {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'rules' contains a rule more than once",
+				Status:  400,
+			}
+		}
+		seen[rule.Rule] = true
+
+		row := entity.EventFraudRule{EventID: eventID, Enabled: rule.Enabled}
+		switch rule.Rule {
+		case string(entity.FRAUD_RAPID_SCANS):
+			row.Rule = entity.FRAUD_RAPID_SCANS
+		case string(entity.FRAUD_REMOTE_LOCATION):
+			row.Rule = entity.FRAUD_REMOTE_LOCATION
+		case string(entity.FRAUD_DISTANT_SCANS):
+			row.Rule = entity.FRAUD_DISTANT_SCANS
+		case string(entity.FRAUD_LATE_CHECKIN):
+			row.Rule = entity.FRAUD_LATE_CHECKIN
+		default:
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'rule' must be RAPID_SCANS, REMOTE_LOCATION, DISTANT_SCANS or LATE_CHECKIN",
+				Status:  400,
+			}
+		}
+
+		if rule.Threshold == nil {
+			row.Threshold = _FraudRuleDefault(rule.Rule).Threshold
+		} else {
+			row.Threshold = *rule.Threshold
+		}
+		// a zero grace period is meaningful for late check-ins, a zero distance or gap is not
+		if row.Threshold < 0 || (row.Threshold == 0 && row.Rule != entity.FRAUD_LATE_CHECKIN) {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: fmt.Sprintf("'threshold' of %s must be greater than 0", rule.Rule),
+				Status:  400,
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	before, rulesErr := s._FraudRules(eventID, ctx)
+	if rulesErr != nil {
+		return nil, rulesErr
+	}
+	var after []dtoRes.FraudRuleRes
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Fraud.SaveFraudRules(rows, ctx); err != nil {
+			return err
+		}
+		// read in the transaction so it sees the saved rules, its errors are already logged
+		after, rulesErr = s._FraudRules(eventID, ctx)
+		if rulesErr != nil {
+			return errAuditFailed
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventFraudRulesUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     map[string]any{"rules": before},
+			After:      map[string]any{"rules": after},
+		}, ctx)
+	}, ctx)
+	if rulesErr != nil {
+		return nil, rulesErr
+	}
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "FraudRepository.SaveFraudRules").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return &after, nil
+}
+
+// Runs the event's enabled heuristics over its check-ins. Flags are only hints
+// for a manager to follow up on, nothing is changed or blocked.
+func (s *service) GetFraudFlagsService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.FraudReportRes, *response.APIError) {
+	eventID, roleErr := s._RequireFraudReviewer(eventIDStr, userIDStr, ctx)
+	if roleErr != nil {
+		return nil, roleErr
+	}
+
+	event, eventErr := s.repo.Participant.GetEventById(eventID, ctx)
+	if eventErr != nil {
+		s.logger.Error().Err(eventErr).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	rules, rulesErr := s._FraudRules(eventID, ctx)
+	if rulesErr != nil {
+		return nil, rulesErr
+	}
+
+	scans, err := s.repo.Fraud.GetFraudScans(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "FraudRepository.GetFraudScans").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	flags := []dtoRes.FraudFlagRes{}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		switch rule.Rule {
+		case string(entity.FRAUD_RAPID_SCANS):
+			flags = append(flags, _DetectRapidScans(scans, time.Duration(rule.Threshold*float64(time.Second)))...)
+		case string(entity.FRAUD_REMOTE_LOCATION):
+			flags = append(flags, _DetectRemoteLocations(scans, event.SelfCheckinCenter, rule.Threshold)...)
+		case string(entity.FRAUD_DISTANT_SCANS):
+			pairs, pairsErr := s.repo.Fraud.GetConcurrentScans(eventID, fraudDistantScanWindow, ctx)
+			if pairsErr != nil {
+				s.logger.Error().Err(pairsErr).
+					Str("event_id", eventID.String()).
+					Str("function", "FraudRepository.GetConcurrentScans").
+					Msg("Internal DB error")
+				return nil, &response.APIError{
+					Code:    response.ErrInternalError,
+					Message: "Internal DB error",
+					Status:  500,
+				}
+			}
+			flags = append(flags, _DetectDistantScans(pairs, rule.Threshold)...)
+		case string(entity.FRAUD_LATE_CHECKIN):
+			grace := time.Duration(rule.Threshold * float64(time.Minute))
+			flags = append(flags, _DetectLateCheckins(scans, event.EndTime.Add(grace))...)
+		}
+	}
+	sort.SliceStable(flags, func(a, b int) bool {
+		return flags[a].ScannedTimestamp.Before(flags[b].ScannedTimestamp)
+	})
+
+	return &dtoRes.FraudReportRes{Rules: rules, Flags: flags}, nil
+}
+
+func (s *service) _RequireFraudReviewer(eventIDStr string, userIDStr string, ctx context.Context) (datatypes.UUID, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return datatypes.UUID{}, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return datatypes.UUID{}, parseErr
+	}
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return datatypes.UUID{}, roleErr
+	}
+	return eventID, nil
+}
+
+// the event's settings over the defaults, in the order of fraudRuleDefaults
+func (s *service) _FraudRules(eventID datatypes.UUID, ctx context.Context) ([]dtoRes.FraudRuleRes, *response.APIError) {
+	rows, err := s.repo.Fraud.GetFraudRules(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "FraudRepository.GetFraudRules").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	rules := make([]dtoRes.FraudRuleRes, len(fraudRuleDefaults))
+	copy(rules, fraudRuleDefaults)
+	for _, row := range rows {
+		for i := range rules {
+			if rules[i].Rule == string(row.Rule) {
+				rules[i].Enabled = row.Enabled
+				rules[i].Threshold = row.Threshold
+			}
+		}
+	}
+	return rules, nil
+}
+
+func _FraudRuleDefault(rule string) dtoRes.FraudRuleRes {
+	for _, def := range fraudRuleDefaults {
+		if def.Rule == rule {
+			return def
+		}
+	}
+	return dtoRes.FraudRuleRes{}
+}
+
+func _FraudFlag(scan entity.FraudScan, rule string, detail string) dtoRes.FraudFlagRes {
+	flag := dtoRes.FraudFlagRes{
+		Rule:             rule,
+		CheckinID:        scan.ID.String(),
+		ParticipantID:    scan.ParticipantID.String(),
+		ScannedTimestamp: scan.ScannedTimestamp.UTC(),
+		Detail:           detail,
+	}
+	if scan.ScannerID != nil {
+		scanner := scan.ScannerID.String()
+		flag.ScannerID = &scanner
+	}
+	return flag
+}
+
+// scans must be in the order they were scanned
+func _DetectRapidScans(scans []entity.FraudScan, minGap time.Duration) []dtoRes.FraudFlagRes {
+	flags := []dtoRes.FraudFlagRes{}
+	previous := map[datatypes.UUID]time.Time{}
+	for _, scan := range scans {
+		if scan.Method != string(entity.CHECKIN_SCAN) || scan.ScannerID == nil {
+			continue
+		}
+		if last, ok := previous[*scan.ScannerID]; ok {
+			if gap := scan.ScannedTimestamp.Sub(last); gap < minGap {
+				flags = append(flags, _FraudFlag(scan, string(entity.FRAUD_RAPID_SCANS),
+					fmt.Sprintf("Scanned %.1fs after this scanner's previous scan", gap.Seconds())))
+			}
+		}
+		previous[*scan.ScannerID] = scan.ScannedTimestamp
+	}
+	return flags
+}
+
+// The venue is the self check-in geofence center when there is one, otherwise
+// the median of the event's scan locations. Only locations shared by more than
+// one check-in are flagged, a real device rarely reports the same point twice.
+func _DetectRemoteLocations(scans []entity.FraudScan, center *entity.Point, maxMeters float64) []dtoRes.FraudFlagRes {
+	located := []entity.FraudScan{}
+	for _, scan := range scans {
+		if scan.ScannedLocation != nil {
+			located = append(located, scan)
+		}
+	}
+	if len(located) == 0 {
+		return []dtoRes.FraudFlagRes{}
+	}
+
+	venue := center
+	if venue == nil {
+		xs := make([]float64, len(located))
+		ys := make([]float64, len(located))
+		for i, scan := range located {
+			xs[i], ys[i] = scan.ScannedLocation.X, scan.ScannedLocation.Y
+		}
+		venue = &entity.Point{X: _Median(xs), Y: _Median(ys)}
+	}
+
+	shared := map[entity.Point]int{}
+	for _, scan := range located {
+		shared[*scan.ScannedLocation]++
+	}
+
+	flags := []dtoRes.FraudFlagRes{}
+	for _, scan := range located {
+		count := shared[*scan.ScannedLocation]
+		if count < 2 {
+			continue
+		}
+		if distance := _DistanceMeters(*venue, *scan.ScannedLocation); distance > maxMeters {
+			flags = append(flags, _FraudFlag(scan, string(entity.FRAUD_REMOTE_LOCATION),
+				fmt.Sprintf("%d check-ins share this exact location, %.0fm from the venue", count, distance)))
+		}
+	}
+	return flags
+}
+
+// each check-in is flagged once, for the farthest of its concurrent check-ins
+func _DetectDistantScans(pairs []entity.FraudScanPair, maxMeters float64) []dtoRes.FraudFlagRes {
+	farthest := map[datatypes.UUID]int{}
+	distances := make([]float64, len(pairs))
+	order := []datatypes.UUID{}
+	for i, pair := range pairs {
+		distances[i] = _DistanceMeters(pair.ScannedLocation, pair.OtherScannedLocation)
+		if distances[i] <= maxMeters {
+			continue
+		}
+		if j, ok := farthest[pair.ID]; !ok {
+			farthest[pair.ID] = i
+			order = append(order, pair.ID)
+		} else if distances[i] > distances[j] {
+			farthest[pair.ID] = i
+		}
+	}
+
+	flags := []dtoRes.FraudFlagRes{}
+	for _, id := range order {
+		i := farthest[id]
+		pair := pairs[i]
+		gap := pair.OtherScannedTimestamp.Sub(pair.ScannedTimestamp)
+		if gap < 0 {
+			gap = -gap
+		}
+		location := pair.ScannedLocation
+		flags = append(flags, _FraudFlag(entity.FraudScan{
+			ID:               pair.ID,
+			ParticipantID:    pair.ParticipantID,
+			ScannerID:        pair.ScannerID,
+			ScannedTimestamp: pair.ScannedTimestamp,
+			ScannedLocation:  &location,
+		}, string(entity.FRAUD_DISTANT_SCANS),
+			fmt.Sprintf("Also checked in to another event %.0fm away, %s apart", distances[i], gap.Round(time.Second))))
+	}
+	return flags
+}
+
+// A scanned or self check-in is as late as the participant was when scanned, a
+// batch synced after the event still records when they arrived. Manual check-ins
+// have no scan of their own, so their check-in time is used.
+func _DetectLateCheckins(scans []entity.FraudScan, deadline time.Time) []dtoRes.FraudFlagRes {
+	flags := []dtoRes.FraudFlagRes{}
+	for _, scan := range scans {
+		if scan.CheckinTimestamp == nil {
+			continue
+		}
+		arrivedAt := *scan.CheckinTimestamp
+		if scan.Method == string(entity.CHECKIN_SCAN) || scan.Method == string(entity.CHECKIN_SELF) {
+			arrivedAt = scan.ScannedTimestamp
+		}
+		if !arrivedAt.After(deadline) {
+			continue
+		}
+		flags = append(flags, _FraudFlag(scan, string(entity.FRAUD_LATE_CHECKIN),
+			fmt.Sprintf("Checked in %s after the cut-off", arrivedAt.Sub(deadline).Round(time.Second))))
+	}
+	return flags
+}
+
+func _Median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
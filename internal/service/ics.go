@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// Builds an RFC 5545 calendar: CRLF line endings, text escaping and
+// lines folded at 75 octets without splitting UTF-8 characters.
+type icsWriter struct {
+	b strings.Builder
+}
+
+func (w *icsWriter) Line(name string, value string) {
+	line := name + ":" + value
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.b.WriteString(line[:cut] + "\r\n")
+		line = " " + line[cut:]
+	}
+	w.b.WriteString(line + "\r\n")
+}
+
+func (w *icsWriter) Text(name string, value string) {
+	w.Line(name, strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value))
+}
+
+func (w *icsWriter) Time(name string, t time.Time) {
+	w.Line(name, t.UTC().Format(icsTimeLayout))
+}
+
+func (w *icsWriter) Bytes() []byte {
+	return []byte(w.b.String())
+}
+
+// one calendar with a VEVENT per event, agenda slots are listed in the description
+func (s *service) _BuildICS(calendarName string, events []entity.GetEventsQueryResult, agenda []entity.EventAgenda, now time.Time) []byte {
+	agendaByEvent := map[string][]entity.EventAgenda{}
+	for _, slot := range agenda {
+		agendaByEvent[slot.EventID.String()] = append(agendaByEvent[slot.EventID.String()], slot)
+	}
+
+	w := icsWriter{}
+	w.Line("BEGIN", "VCALENDAR")
+	w.Line("VERSION", "2.0")
+	w.Line("PRODID", "-//QuickAttend//Events//EN")
+	w.Line("CALSCALE", "GREGORIAN")
+	w.Line("METHOD", "PUBLISH")
+	if calendarName != "" {
+		w.Text("X-WR-CALNAME", calendarName)
+		w.Line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+		w.Line("X-PUBLISHED-TTL", "PT1H")
+	}
+
+	for _, event := range events {
+		w.Line("BEGIN", "VEVENT")
+		w.Line("UID", event.ID.String()+"@quickattend")
+		w.Time("DTSTAMP", now)
+		w.Time("DTSTART", event.StartTime)
+		w.Time("DTEND", event.EndTime)
+		w.Text("SUMMARY", event.Name)
+		w.Text("LOCATION", event.Location)
+		w.Text("DESCRIPTION", s._ICSDescription(event, agendaByEvent[event.ID.String()]))
+		if event.RegistrationStatus != nil && *event.RegistrationStatus == string(entity.WAITLISTED) {
+			w.Line("STATUS", "TENTATIVE")
+		} else {
+			w.Line("STATUS", "CONFIRMED")
+		}
+		w.Line("END", "VEVENT")
+	}
+
+	w.Line("END", "VCALENDAR")
+	return w.Bytes()
+}
+
+func (s *service) _ICSDescription(event entity.GetEventsQueryResult, agenda []entity.EventAgenda) string {
+	parts := []string{}
+	if event.Description != nil && *event.Description != "" {
+		parts = append(parts, *event.Description)
+	}
+	parts = append(parts, "Organizer: "+event.Organizer)
+
+	if len(agenda) > 0 {
+		lines := []string{"Agenda:"}
+		for _, slot := range agenda {
+			lines = append(lines, fmt.Sprintf("%s-%s %s",
+				slot.StartTime.In(bangkokTime).Format("15:04"),
+				slot.EndTime.In(bangkokTime).Format("15:04"),
+				slot.ActivityName))
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+
+	if event.RegistrationStatus != nil && *event.RegistrationStatus == string(entity.WAITLISTED) {
+		parts = append(parts, "You are on the waitlist for this event.")
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// how many expired idempotency keys are deleted per statement
+const idempotencyPurgeBatchSize = 1000
+
+type IdempotencyService interface {
+	PurgeExpiredIdempotencyKeysService(ctx context.Context)
+}
+
+// Run by the idempotency job. Expired keys are already ignored by the
+// middleware, this deletes them so the table does not keep growing.
+func (s *service) PurgeExpiredIdempotencyKeysService(ctx context.Context) {
+	now := time.Now()
+
+	var purged int64
+	for ctx.Err() == nil {
+		deleted, err := s.repo.Idempotency.DeleteExpiredIdempotencyKeys(now, idempotencyPurgeBatchSize, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("function", "IdempotencyRepository.DeleteExpiredIdempotencyKeys").
+				Msg("Internal DB error")
+			break
+		}
+		purged += deleted
+		if deleted < idempotencyPurgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info().Int64("idempotency_keys", purged).Msg("Expired idempotency keys purged")
+	}
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/notify"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const (
+	// how long a claimed notification is left to its worker before another may
+	// retry it, batches are claimed small enough to be delivered within it
+	notificationLease = 15 * time.Minute
+	// time allowed for one channel to accept one notification
+	notificationSendTimeout = 30 * time.Second
+	// retries wait 1, 2, 4, ... minutes up to this
+	notificationMaxBackoff = time.Hour
+)
+
+// channels in the order preferences list them
+var notificationChannels = []string{notify.CUNEX, notify.EMAIL, notify.LINE}
+
+// the ID the LINE Messaging API gives a user who added the official account
+var lineUserIDPattern = regexp.MustCompile(`^U[0-9a-f]{32}$`)
+
+type NotificationService interface {
+	RunNotificationsService(ctx context.Context)
+	GetNotificationPreferencesService(userIDStr string, ctx context.Context) (*dtoRes.NotificationPreferencesRes, *response.APIError)
+	UpdateNotificationPreferencesService(userIDStr string, req *dtoReq.UpdateNotificationPreferencesReq, ctx context.Context) (*dtoRes.NotificationPreferencesRes, *response.APIError)
+}
+
+// Run by the notification job. Adds the reminders and notices that became due
+// to the outbox, then delivers everything pending one batch at a time; a
+// failed delivery is retried with backoff until NOTIFY_MAX_ATTEMPTS.
+func (s *service) RunNotificationsService(ctx context.Context) {
+	cfg := s.cfg.NotificationConfig
+	now := time.Now()
+
+	added, err := s.repo.Notification.ScheduleNotifications(now, cfg.EvaluationWindow, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "NotificationRepository.ScheduleNotifications").
+			Msg("Internal DB error")
+	} else if added > 0 {
+		s.logger.Info().Int64("notifications", added).Msg("Notifications scheduled")
+	}
+
+	// every channel of every notification in the batch may take the whole send timeout
+	perNotification := time.Duration(max(len(s._AvailableNotificationChannels()), 1)) * notificationSendTimeout
+	batchSize := max(min(cfg.BatchSize, int(notificationLease/perNotification)), 1)
+	for ctx.Err() == nil {
+		pending, err := s.repo.Notification.ClaimNotifications(now, time.Now().Add(notificationLease), batchSize, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("function", "NotificationRepository.ClaimNotifications").
+				Msg("Internal DB error")
+			return
+		}
+
+		for i := range pending {
+			s._DeliverNotification(&pending[i], now, ctx)
+		}
+
+		// claimed and retried notifications are no longer due as of now, so a short batch is the last
+		if len(pending) < batchSize {
+			return
+		}
+	}
+}
+
+func (s *service) GetNotificationPreferencesService(userIDStr string, ctx context.Context) (*dtoRes.NotificationPreferencesRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	preference, err := s.repo.Notification.GetNotificationPreference(userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &dtoRes.NotificationPreferencesRes{
+			Channels:          s.notifier.Defaults,
+			EventReminders:    true,
+			EvaluationNotices: true,
+			StaffNotices:      true,
+			AvailableChannels: s._AvailableNotificationChannels(),
+		}, nil
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "NotificationRepository.GetNotificationPreference").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._NotificationPreferencesDTOFormat(preference), nil
+}
+
+func (s *service) UpdateNotificationPreferencesService(userIDStr string, req *dtoReq.UpdateNotificationPreferencesReq, ctx context.Context) (*dtoRes.NotificationPreferencesRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	available := s._AvailableNotificationChannels()
+	channels := entity.NotificationChannels{}
+	for _, channel := range req.Channels {
+		if !slices.Contains(available, channel) {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'channels' must only contain " + strings.Join(available, ", "),
+				Status:  400,
+			}
+		}
+		if slices.Contains(channels, channel) {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'channels' contains a channel more than once",
+				Status:  400,
+			}
+		}
+		channels = append(channels, channel)
+	}
+
+	previous, err := s.repo.Notification.GetNotificationPreference(userID, ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(err).
+			Str("function", "NotificationRepository.GetNotificationPreference").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	var email *string
+	switch {
+	case req.Email == nil:
+		if previous != nil {
+			email = previous.Email
+		}
+	case strings.TrimSpace(*req.Email) != "":
+		trimmed := strings.TrimSpace(*req.Email)
+		address, err := mail.ParseAddress(trimmed)
+		if err != nil || address.Address != trimmed {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'email' must be an email address",
+				Status:  400,
+			}
+		}
+		email = &trimmed
+	}
+	var lineUserID *string
+	switch {
+	case req.LineUserID == nil:
+		if previous != nil {
+			lineUserID = previous.LineUserID
+		}
+	case strings.TrimSpace(*req.LineUserID) != "":
+		trimmed := strings.TrimSpace(*req.LineUserID)
+		if !lineUserIDPattern.MatchString(trimmed) {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'line_user_id' must be a LINE user ID",
+				Status:  400,
+			}
+		}
+		lineUserID = &trimmed
+	}
+
+	if slices.Contains(channels, notify.EMAIL) && email == nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'email' is required to be notified by email",
+			Status:  400,
+		}
+	}
+	if slices.Contains(channels, notify.LINE) && lineUserID == nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'line_user_id' is required to be notified on LINE",
+			Status:  400,
+		}
+	}
+
+	preference := entity.NotificationPreference{
+		UserID:            userID,
+		Channels:          channels,
+		Email:             email,
+		LineUserID:        lineUserID,
+		EventReminders:    req.EventReminders,
+		EvaluationNotices: req.EvaluationNotices,
+		StaffNotices:      req.StaffNotices,
+		UpdatedAt:         time.Now(),
+	}
+	if err := s.repo.Notification.SaveNotificationPreference(&preference, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "NotificationRepository.SaveNotificationPreference").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._NotificationPreferencesDTOFormat(&preference), nil
+}
+
+// Sends a claimed notification on every channel the user chose that has not
+// accepted it yet. A channel the user has no address for is left out.
+func (s *service) _DeliverNotification(pending *entity.PendingNotification, now time.Time, ctx context.Context) {
+	result := entity.Notification{
+		ID:                pending.ID,
+		Status:            entity.NOTIFICATION_SKIPPED,
+		Attempts:          pending.Attempts,
+		DeliveredChannels: pending.DeliveredChannels,
+		NextAttemptAt:     now,
+	}
+	if result.DeliveredChannels == nil {
+		result.DeliveredChannels = entity.NotificationChannels{}
+	}
+
+	if reason := _NotificationSkipReason(pending, now); reason != "" {
+		result.LastError = &reason
+		s._SaveNotificationAttempt(&result, ctx)
+		return
+	}
+
+	channels := s.notifier.Defaults
+	if pending.HasPreferences {
+		channels = pending.Channels
+	}
+	message := s._NotificationMessage(pending)
+
+	var failures []string
+	for _, name := range channels {
+		channel, enabled := s.notifier.Channels[name]
+		if !enabled || slices.Contains(result.DeliveredChannels, name) {
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+		err := channel.Send(message, sendCtx)
+		cancel()
+		switch {
+		case errors.Is(err, notify.ErrNoAddress):
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		default:
+			result.DeliveredChannels = append(result.DeliveredChannels, name)
+		}
+	}
+
+	result.Attempts++
+	switch {
+	case len(failures) > 0:
+		lastError := strings.Join(failures, "; ")
+		result.LastError = &lastError
+		if result.Attempts >= max(s.cfg.NotificationConfig.MaxAttempts, 1) {
+			result.Status = entity.NOTIFICATION_FAILED
+			s.logger.Warn().
+				Str("notification_id", pending.ID.String()).
+				Str("error", lastError).
+				Msg("Notification delivery failed")
+		} else {
+			result.Status = entity.NOTIFICATION_PENDING
+			backoff := min(time.Minute<<(result.Attempts-1), notificationMaxBackoff)
+			result.NextAttemptAt = now.Add(backoff)
+		}
+	case len(result.DeliveredChannels) > 0:
+		result.Status = entity.NOTIFICATION_SENT
+		result.LastError = nil
+		result.SentAt = &now
+	default:
+		reason := "no channel could reach the user"
+		result.LastError = &reason
+	}
+	s._SaveNotificationAttempt(&result, ctx)
+}
+
+func (s *service) _SaveNotificationAttempt(result *entity.Notification, ctx context.Context) {
+	if err := s.repo.Notification.SaveNotificationAttempt(result, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("notification_id", result.ID.String()).
+			Str("function", "NotificationRepository.SaveNotificationAttempt").
+			Msg("Internal DB error")
+	}
+}
+
+// why a notification should no longer be sent, empty when it should
+func _NotificationSkipReason(pending *entity.PendingNotification, now time.Time) string {
+	switch pending.Kind {
+	case string(entity.NOTIFY_REMINDER_24H), string(entity.NOTIFY_REMINDER_1H):
+		if !pending.EventReminders {
+			return "user turned off event reminders"
+		}
+		if !pending.StartTime.After(now) {
+			return "event already started"
+		}
+		if !_IsRegistered(pending) {
+			return "user is no longer registered"
+		}
+	case string(entity.NOTIFY_EVALUATION_OPEN):
+		if !pending.EvaluationNotices {
+			return "user turned off evaluation notices"
+		}
+	case string(entity.NOTIFY_STAFF_ASSIGNED):
+		if !pending.StaffNotices {
+			return "user turned off staff notices"
+		}
+		if pending.Role == nil {
+			return "user is no longer on the event's staff"
+		}
+	case string(entity.NOTIFY_ANNOUNCEMENT):
+		// sent whatever the toggles, organizers announce what attendees need to know
+		if pending.Announcement == nil {
+			return "announcement no longer exists"
+		}
+	case string(entity.NOTIFY_WAITLIST_PROMOTED):
+		// sent whatever the toggles, the user has to know their seat is confirmed
+		if !pending.EndTime.After(now) {
+			return "event already ended"
+		}
+		if !_IsRegistered(pending) {
+			return "user is no longer registered"
+		}
+	}
+	return ""
+}
+
+// reminders and promotions are queued for a registration the user may have cancelled since
+func _IsRegistered(pending *entity.PendingNotification) bool {
+	return pending.RegistrationStatus != nil && *pending.RegistrationStatus == string(entity.REGISTERED)
+}
+
+func (s *service) _NotificationMessage(pending *entity.PendingNotification) notify.Message {
+	message := notify.Message{
+		To: notify.Recipient{
+			UserID:     pending.UserID.String(),
+			RefID:      s.FormatRefIdToStr(pending.RefID),
+			Email:      pending.Email,
+			LineUserID: pending.LineUserID,
+		},
+	}
+	start := pending.StartTime.In(bangkokTime)
+
+	switch pending.Kind {
+	case string(entity.NOTIFY_REMINDER_24H):
+		message.Title = "Reminder: " + pending.EventName
+		message.Body = fmt.Sprintf("%s starts on %s at %s, %s.",
+			pending.EventName, start.Format("Mon 2 Jan"), start.Format("15:04"), pending.Location)
+	case string(entity.NOTIFY_REMINDER_1H):
+		message.Title = "Starting soon: " + pending.EventName
+		message.Body = fmt.Sprintf("%s starts at %s, %s.", pending.EventName, start.Format("15:04"), pending.Location)
+	case string(entity.NOTIFY_EVALUATION_OPEN):
+		message.Title = "How was " + pending.EventName + "?"
+		message.Body = fmt.Sprintf("Thanks for attending %s. Let the organizers know how it went.", pending.EventName)
+		if pending.EvaluationForm != nil {
+			message.Link = *pending.EvaluationForm
+		}
+	case string(entity.NOTIFY_STAFF_ASSIGNED):
+		role := strings.ToLower(*pending.Role)
+		message.Title = "You are " + role + " of " + pending.EventName
+		message.Body = fmt.Sprintf("You were added as %s of %s on %s at %s, %s.",
+			role, pending.EventName, start.Format("Mon 2 Jan"), start.Format("15:04"), pending.Location)
+	case string(entity.NOTIFY_ANNOUNCEMENT):
+		message.Title = "Announcement: " + pending.EventName
+		message.Body = *pending.Announcement
+	case string(entity.NOTIFY_WAITLIST_PROMOTED):
+		message.Title = "You are registered for " + pending.EventName
+		message.Body = fmt.Sprintf("A seat opened up, you are now registered for %s on %s at %s, %s.",
+			pending.EventName, start.Format("Mon 2 Jan"), start.Format("15:04"), pending.Location)
+	}
+	return message
+}
+
+// the channels this server delivers through
+func (s *service) _AvailableNotificationChannels() []string {
+	available := []string{}
+	for _, name := range notificationChannels {
+		if _, ok := s.notifier.Channels[name]; ok {
+			available = append(available, name)
+		}
+	}
+	return available
+}
+
+func (s *service) _NotificationPreferencesDTOFormat(preference *entity.NotificationPreference) *dtoRes.NotificationPreferencesRes {
+	updatedAt := preference.UpdatedAt.UTC()
+	return &dtoRes.NotificationPreferencesRes{
+		Channels:          preference.Channels,
+		Email:             preference.Email,
+		LineConnected:     preference.LineUserID != nil,
+		EventReminders:    preference.EventReminders,
+		EvaluationNotices: preference.EvaluationNotices,
+		StaffNotices:      preference.StaffNotices,
+		AvailableChannels: s._AvailableNotificationChannels(),
+		UpdatedAt:         &updatedAt,
+	}
+}
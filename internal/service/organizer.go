@@ -0,0 +1,645 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+type OrganizerService interface {
+	CreateOrganizerService(userIDStr string, req *dtoReq.CreateOrganizerReq, ctx context.Context) (*dtoRes.OrganizerRes, *response.APIError)
+	GetMyOrganizersService(userIDStr string, ctx context.Context) (*[]dtoRes.OrganizerRes, *response.APIError)
+	GetOrganizerService(organizerIDStr string, userIDStr string, ctx context.Context) (*dtoRes.OrganizerRes, *response.APIError)
+	UpdateOrganizerService(organizerIDStr string, userIDStr string, req *dtoReq.UpdateOrganizerReq, ctx context.Context) (*dtoRes.OrganizerRes, *response.APIError)
+	SetEventOrganizerService(eventIDStr string, userIDStr string, req *dtoReq.SetEventOrganizerReq, ctx context.Context) *response.APIError
+	GetOrganizerReportService(organizerIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*dtoRes.OrganizerReportRes, *response.APIError)
+	GetOrganizerReportCSVService(organizerIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) ([]byte, *response.APIError)
+}
+
+// the creator becomes the organizer's owner
+func (s *service) CreateOrganizerService(userIDStr string, req *dtoReq.CreateOrganizerReq, ctx context.Context) (*dtoRes.OrganizerRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'name' is required",
+			Status:  400,
+		}
+	}
+	members, membersErr := s._ParseOrganizerMembers(req.Members, userID)
+	if membersErr != nil {
+		return nil, membersErr
+	}
+
+	organizer := &entity.Organizer{Name: name, Description: req.Description}
+	rows := append([]entity.OrganizerMember{{UserID: userID, Role: entity.OWNER}}, members...)
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Organizer.CreateOrganizer(organizer, rows, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditOrganizerCreate,
+			TargetType: auditTargetOrganizer,
+			TargetID:   organizer.ID.String(),
+			After: map[string]any{
+				"name":        organizer.Name,
+				"description": organizer.Description,
+				"members":     _AuditOrganizerMembers(rows),
+			},
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "An organizer with this name already exists",
+			Status:  409,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "OrganizerRepository.CreateOrganizer").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s.GetOrganizerService(organizer.ID.String(), userIDStr, ctx)
+}
+
+func (s *service) GetMyOrganizersService(userIDStr string, ctx context.Context) (*[]dtoRes.OrganizerRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	organizers, err := s.repo.Organizer.GetMyOrganizers(userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "OrganizerRepository.GetMyOrganizers").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := []dtoRes.OrganizerRes{}
+	for _, organizer := range *organizers {
+		role := organizer.Role
+		res = append(res, dtoRes.OrganizerRes{
+			ID:          organizer.ID.String(),
+			Name:        organizer.Name,
+			Description: organizer.Description,
+			Role:        &role,
+		})
+	}
+	return &res, nil
+}
+
+// only the organizer's members can see it
+func (s *service) GetOrganizerService(organizerIDStr string, userIDStr string, ctx context.Context) (*dtoRes.OrganizerRes, *response.APIError) {
+	organizerID, parseErr := s._ParseOrganizerID(organizerIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	role, roleErr := s._RequireOrganizerRole(organizerID, userID, []string{string(entity.OWNER), string(entity.MANAGER), string(entity.STAFF)}, ctx)
+	if roleErr != nil {
+		return nil, roleErr
+	}
+
+	organizer, err := s.repo.Organizer.GetOrganizerById(organizerID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	members, err := s.repo.Organizer.GetOrganizerMembers(organizerID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerMembers").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := dtoRes.OrganizerRes{
+		ID:          organizer.ID.String(),
+		Name:        organizer.Name,
+		Description: organizer.Description,
+		Role:        &role,
+		Members:     []dtoRes.OrganizerMemberRes{},
+	}
+	for _, member := range *members {
+		res.Members = append(res.Members, dtoRes.OrganizerMemberRes{
+			UserID:      member.UserID.String(),
+			Role:        member.Role,
+			RefID:       s.FormatRefIdToStr(member.RefID),
+			FirstnameTH: member.FirstnameTH,
+			SurnameTH:   member.SurnameTH,
+			FirstnameEN: member.FirstnameEN,
+			SurnameEN:   member.SurnameEN,
+		})
+	}
+	return &res, nil
+}
+
+// owners and managers can edit the organizer, only owners can change its members
+func (s *service) UpdateOrganizerService(organizerIDStr string, userIDStr string, req *dtoReq.UpdateOrganizerReq, ctx context.Context) (*dtoRes.OrganizerRes, *response.APIError) {
+	organizerID, parseErr := s._ParseOrganizerID(organizerIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	role, roleErr := s._RequireOrganizerRole(organizerID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx)
+	if roleErr != nil {
+		return nil, roleErr
+	}
+	if req.Members != nil && role != string(entity.OWNER) {
+		return nil, &response.APIError{
+			Code:    response.ErrForbidden,
+			Message: "Only owners can change the organizer's members",
+			Status:  403,
+		}
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'name' is required",
+			Status:  400,
+		}
+	}
+	var members *[]entity.OrganizerMember
+	if req.Members != nil {
+		parsed, membersErr := s._ParseOrganizerMembers(*req.Members, userID)
+		if membersErr != nil {
+			return nil, membersErr
+		}
+		members = &parsed
+	}
+
+	before, err := s.repo.Organizer.GetOrganizerById(organizerID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	var beforeMembers *[]entity.GetOrganizerMember
+	if members != nil {
+		beforeMembers, err = s.repo.Organizer.GetOrganizerMembers(organizerID, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("organizer_id", organizerID.String()).
+				Str("function", "OrganizerRepository.GetOrganizerMembers").
+				Msg("Internal DB error")
+			return nil, &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Internal DB error",
+				Status:  500,
+			}
+		}
+	}
+
+	err = s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Organizer.UpdateOrganizer(organizerID, name, req.Description, members, ctx); err != nil {
+			return err
+		}
+		auditErr := s._Audit(auditEntry{
+			Action:     auditOrganizerUpdate,
+			TargetType: auditTargetOrganizer,
+			TargetID:   organizerID.String(),
+			Before:     map[string]any{"name": before.Name, "description": before.Description},
+			After:      map[string]any{"name": name, "description": req.Description},
+		}, ctx)
+		if auditErr != nil || members == nil {
+			return auditErr
+		}
+		previous := []auditMember{}
+		for _, member := range *beforeMembers {
+			if member.Role != string(entity.OWNER) {
+				previous = append(previous, auditMember{UserID: member.UserID.String(), Role: member.Role})
+			}
+		}
+		return s._Audit(auditEntry{
+			Action:     auditOrganizerMembersUpdate,
+			TargetType: auditTargetOrganizer,
+			TargetID:   organizerID.String(),
+			Before:     map[string]any{"members": previous},
+			After:      map[string]any{"members": _AuditOrganizerMembers(*members)},
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "An organizer with this name already exists",
+			Status:  409,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.UpdateOrganizer").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s.GetOrganizerService(organizerIDStr, userIDStr, ctx)
+}
+
+// Links the event to an organizer, which then shows as its Organizer. Takes an
+// owner of the event who is also an owner or manager of the organizer.
+func (s *service) SetEventOrganizerService(eventIDStr string, userIDStr string, req *dtoReq.SetEventOrganizerReq, ctx context.Context) *response.APIError {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER)}, ctx); roleErr != nil {
+		return roleErr
+	}
+
+	var organizer *entity.Organizer
+	if req.OrganizerID != nil {
+		if uuid.Validate(*req.OrganizerID) != nil {
+			return &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'organizer_id' must be a UUID",
+				Status:  400,
+			}
+		}
+		organizerID := datatypes.UUID(datatypes.BinUUIDFromString(*req.OrganizerID))
+		if _, roleErr := s._RequireOrganizerRole(organizerID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+			return roleErr
+		}
+
+		var err error
+		organizer, err = s.repo.Organizer.GetOrganizerById(organizerID, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("organizer_id", organizerID.String()).
+				Str("function", "OrganizerRepository.GetOrganizerById").
+				Msg("Internal DB error")
+			return &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Internal DB error",
+				Status:  500,
+			}
+		}
+	}
+
+	event, err := s.repo.Participant.GetEventById(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	var beforeOrganizerID *string
+	if event.OrganizerID != nil {
+		id := event.OrganizerID.String()
+		beforeOrganizerID = &id
+	}
+	before := map[string]any{"organizer_id": beforeOrganizerID, "organizer": event.Organizer}
+	after := map[string]any{"organizer_id": nil, "organizer": event.Organizer}
+	if organizer != nil {
+		after = map[string]any{"organizer_id": organizer.ID.String(), "organizer": organizer.Name}
+	}
+
+	err = s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Organizer.SetEventOrganizer(eventID, organizer, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventOrganizerUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     before,
+			After:      after,
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "OrganizerRepository.SetEventOrganizer").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return nil
+}
+
+// Trends across the organizer's events that started between the optional from
+// and to, given as dates in Bangkok time or RFC 3339 timestamps
+func (s *service) GetOrganizerReportService(organizerIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*dtoRes.OrganizerReportRes, *response.APIError) {
+	organizerID, parseErr := s._ParseOrganizerID(organizerIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireOrganizerRole(organizerID, userID, []string{string(entity.OWNER), string(entity.MANAGER), string(entity.STAFF)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	var from, to *time.Time
+	if fromQuery, ok := queryParams["from"]; ok {
+		parsed, _, err := s._ParseEventsTime(fromQuery)
+		if err != nil {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'from' must be a date or an RFC 3339 timestamp",
+				Status:  400,
+			}
+		}
+		from = &parsed
+	}
+	if toQuery, ok := queryParams["to"]; ok {
+		parsed, dateOnly, err := s._ParseEventsTime(toQuery)
+		if err != nil {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'to' must be a date or an RFC 3339 timestamp",
+				Status:  400,
+			}
+		}
+		// a date includes the whole day
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		to = &parsed
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "URL query parameter 'from' must be before 'to'",
+			Status:  400,
+		}
+	}
+
+	report, err := s.repo.Organizer.GetOrganizerReport(organizerID, from, to, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerReport").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := dtoRes.OrganizerReportRes{
+		EventCount:      report.Events,
+		Checkins:        report.Checkins,
+		UniqueAttendees: report.UniqueAttendees,
+		RepeatAttendees: report.RepeatAttendees,
+		Frequency:       []dtoRes.OrganizerFrequencyRes{},
+		Faculties:       []dtoRes.AnalyticsCountRes{},
+		RatingAverage:   report.RatingAverage,
+		Ratings:         report.Ratings,
+		Events:          []dtoRes.OrganizerReportEventRes{},
+	}
+	if from != nil {
+		utc := from.UTC()
+		res.From = &utc
+	}
+	if to != nil {
+		utc := to.UTC()
+		res.To = &utc
+	}
+	if report.UniqueAttendees > 0 {
+		rate := float64(report.RepeatAttendees) / float64(report.UniqueAttendees)
+		res.RepeatRate = &rate
+	}
+	for _, row := range report.Frequency {
+		res.Frequency = append(res.Frequency, dtoRes.OrganizerFrequencyRes{
+			EventsAttended: row.EventsAttended,
+			Attendees:      row.Attendees,
+		})
+	}
+	for _, row := range report.Faculties {
+		res.Faculties = append(res.Faculties, dtoRes.AnalyticsCountRes{Key: row.Key, Count: row.Count})
+	}
+	for _, row := range report.EventRows {
+		res.Events = append(res.Events, dtoRes.OrganizerReportEventRes{
+			ID:            row.ID.String(),
+			Name:          row.Name,
+			StartTime:     row.StartTime.UTC(),
+			EndTime:       row.EndTime.UTC(),
+			Registered:    row.Registered,
+			Checkins:      row.Checkins,
+			RatingAverage: row.RatingAverage,
+			Ratings:       row.Ratings,
+		})
+	}
+	return &res, nil
+}
+
+// the report's per-event rows as CSV, one line per event
+func (s *service) GetOrganizerReportCSVService(organizerIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) ([]byte, *response.APIError) {
+	report, reportErr := s.GetOrganizerReportService(organizerIDStr, userIDStr, queryParams, ctx)
+	if reportErr != nil {
+		return nil, reportErr
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	rows := [][]string{{"event_id", "name", "start_time", "end_time", "registered", "checkins", "rating_average", "ratings"}}
+	for _, event := range report.Events {
+		average := ""
+		if event.RatingAverage != nil {
+			average = strconv.FormatFloat(*event.RatingAverage, 'f', 2, 64)
+		}
+		rows = append(rows, []string{
+			event.ID,
+			_CSVCell(event.Name),
+			event.StartTime.Format(time.RFC3339),
+			event.EndTime.Format(time.RFC3339),
+			strconv.FormatInt(event.Registered, 10),
+			strconv.FormatInt(event.Checkins, 10),
+			average,
+			strconv.FormatInt(event.Ratings, 10),
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write organizer report CSV")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to build report",
+			Status:  500,
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Spreadsheets run a cell starting with one of these as a formula, so free
+// text written to CSV is prefixed with ' to keep it plain text.
+func _CSVCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (s *service) _ParseOrganizerID(organizerIDStr string) (datatypes.UUID, *response.APIError) {
+	if err := uuid.Validate(organizerIDStr); err != nil {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'id'",
+			Status:  400,
+		}
+	}
+	return datatypes.UUID(datatypes.BinUUIDFromString(organizerIDStr)), nil
+}
+
+// returns the user's role in the organizer, or 403 unless it is one of roles
+func (s *service) _RequireOrganizerRole(organizerID datatypes.UUID, userID datatypes.UUID, roles []string, ctx context.Context) (string, *response.APIError) {
+	role, err := s.repo.Organizer.GetOrganizerUserRole(organizerID, userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerUserRole").
+			Msg("Internal DB error")
+		return "", &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if role == nil || !slices.Contains(roles, *role) {
+		return "", &response.APIError{
+			Code:    response.ErrForbidden,
+			Message: "You do not have permission to do this for this organizer",
+			Status:  403,
+		}
+	}
+	return *role, nil
+}
+
+func (s *service) _ParseOrganizerMembers(req []dtoReq.OrganizerMemberReq, ownerID datatypes.UUID) ([]entity.OrganizerMember, *response.APIError) {
+	members := []entity.OrganizerMember{}
+	seen := map[string]bool{ownerID.String(): true}
+
+	for _, member := range req {
+		if uuid.Validate(member.UserID) != nil {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'members' contains an invalid 'user_id'",
+				Status:  400,
+			}
+		}
+		if seen[member.UserID] {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'members' contains a user more than once or the owner",
+				Status:  400,
+			}
+		}
+		seen[member.UserID] = true
+
+		row := entity.OrganizerMember{UserID: datatypes.UUID(datatypes.BinUUIDFromString(member.UserID))}
+		switch member.Role {
+		case string(entity.MANAGER):
+			row.Role = entity.MANAGER
+		case string(entity.STAFF):
+			row.Role = entity.STAFF
+		default:
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'members' role must be MANAGER or STAFF",
+				Status:  400,
+			}
+		}
+		members = append(members, row)
+	}
+
+	return members, nil
+}
+
+func _AuditOrganizerMembers(members []entity.OrganizerMember) []auditMember {
+	res := []auditMember{}
+	for _, member := range members {
+		res = append(res, auditMember{UserID: member.UserID.String(), Role: string(member.Role)})
+	}
+	return res
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+type ParticipantService interface {
+	GetEventParticipantsService(eventIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.EventParticipantRes, *response.Pagination, *response.APIError)
+	ManualCheckInService(eventIDStr string, userIDStr string, req *dtoReq.ManualCheckinReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError)
+	CorrectCheckInService(eventIDStr string, participantIDStr string, userIDStr string, req *dtoReq.CorrectCheckinReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError)
+	RevokeCheckInService(eventIDStr string, participantIDStr string, userIDStr string, req *dtoReq.RevokeCheckinReq, ctx context.Context) *response.APIError
+}
+
+// Lists the event's check-ins for its staff. Identity fields follow the event's
+// RevealedFields for every role; an owner or manager may pass reveal=all to see
+// everything, which is logged.
+func (s *service) GetEventParticipantsService(eventIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.EventParticipantRes, *response.Pagination, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+
+	role, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER), string(entity.STAFF)}, ctx)
+	if roleErr != nil {
+		return nil, nil, roleErr
+	}
+
+	page := 0
+	if pageQuery, ok := queryParams["page"]; ok {
+		pageInt, err := strconv.Atoi(pageQuery)
+		if err != nil || pageInt < 0 {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'page' must be a non-negative int",
+				Status:  400,
+			}
+		}
+		page = pageInt
+	}
+	pageSize := 50
+	if sizeQuery, ok := queryParams["pageSize"]; ok {
+		sizeInt, err := strconv.Atoi(sizeQuery)
+		if err != nil || sizeInt < 1 || sizeInt > 100 {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'pageSize' must be an int from 1 to 100",
+				Status:  400,
+			}
+		}
+		pageSize = sizeInt
+	}
+	var since *time.Time
+	if sinceQuery, ok := queryParams["since"]; ok {
+		sinceTime, err := time.Parse(time.RFC3339Nano, sinceQuery)
+		if err != nil {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'since' must be an RFC 3339 timestamp",
+				Status:  400,
+			}
+		}
+		since = &sinceTime
+	}
+
+	event, eventErr := s.repo.Participant.GetEventById(eventID, ctx)
+	if eventErr != nil {
+		s.logger.Error().Err(eventErr).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	projection := _RevealedProjection(event.RevealedFields)
+	if reveal, ok := queryParams["reveal"]; ok {
+		if reveal != "all" {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'reveal' must be 'all'",
+				Status:  400,
+			}
+		}
+		if role != string(entity.OWNER) && role != string(entity.MANAGER) {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrForbidden,
+				Message: "Only owners and managers can reveal all participant fields",
+				Status:  403,
+			}
+		}
+		if hidden := projection._Hidden(); len(hidden) > 0 {
+			s.logger.Warn().
+				Str("event_id", eventID.String()).
+				Str("user_id", userID.String()).
+				Str("role", role).
+				Strs("overridden_fields", hidden).
+				Msg("Revealed fields overridden")
+			if err := s._Audit(auditEntry{
+				Action:     auditParticipantReveal,
+				TargetType: auditTargetParticipant,
+				EventID:    &eventID,
+				After:      map[string]any{"role": role, "overridden_fields": hidden},
+			}, ctx); err != nil {
+				return nil, nil, _AuditFailedError()
+			}
+		}
+		projection = fullProjection
+	}
+
+	participants, total, err := s.repo.Participant.GetEventParticipants(eventID, since, page, pageSize, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventParticipants").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	now := time.Now()
+	res := []dtoRes.EventParticipantRes{}
+	for _, row := range *participants {
+		var checkin *time.Time
+		if row.CheckinTimestamp != nil {
+			utc := row.CheckinTimestamp.UTC()
+			checkin = &utc
+		}
+		res = append(res, dtoRes.EventParticipantRes{
+			ID:               row.ID.String(),
+			ParticipantID:    row.ParticipantID.String(),
+			CheckinTimestamp: checkin,
+			ScannedTimestamp: row.ScannedTimestamp.UTC(),
+			Method:           row.Method,
+			Comment:          row.Comment,
+			RevealedParticipantRes: s._ProjectParticipant(projection, participantIdentity{
+				UserID:      row.ParticipantID,
+				RefID:       row.RefID,
+				FirstnameTH: row.FirstnameTH,
+				SurnameTH:   row.SurnameTH,
+				FirstnameEN: row.FirstnameEN,
+				SurnameEN:   row.SurnameEN,
+				HasPhoto:    row.HasPhoto,
+			}, now),
+		})
+	}
+
+	return &res, &response.Pagination{
+		Page:     &page,
+		PageSize: pageSize,
+		Total:    &total,
+		HasNext:  int64((page+1)*pageSize) < total,
+	}, nil
+}
+
+// Checks a participant in by their ref ID, for when they cannot show a QR code.
+// The check-in has no location, is attributed to the acting user and keeps the
+// required reason in its comment.
+func (s *service) ManualCheckInService(eventIDStr string, userIDStr string, req *dtoReq.ManualCheckinReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	refID, err := strconv.ParseUint(strings.TrimSpace(req.RefID), 10, 64)
+	if err != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'ref_id' must be a numeric ref ID",
+			Status:  400,
+		}
+	}
+	reason, reasonErr := _CheckInReason(req.Comment)
+	if reasonErr != nil {
+		return nil, reasonErr
+	}
+
+	event, eventErr := s._GetEventForScanner(eventID, userID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER), string(entity.STAFF)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	user, userErr := s.repo.Auth.GetUserByRefId(refID, ctx)
+	if errors.Is(userErr, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Participant not found",
+			Status:  404,
+		}
+	}
+	if userErr != nil {
+		s.logger.Error().Err(userErr).
+			Str("function", "AuthRepository.GetUserByRefId").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	now := time.Now()
+	participant, checkinErr := s._CheckIn(event, &user, &entity.EventParticipants{
+		EventID:          event.ID,
+		CheckinTimestamp: &now,
+		ScannedTimestamp: now,
+		Comment:          &reason,
+		ParticipantID:    user.ID,
+		Organization:     s._ParticipantOrganization(user.RefID),
+		Method:           entity.CHECKIN_MANUAL,
+		ScannerID:        &userID,
+	}, req.AcceptPrivacyNotice, ctx)
+	if checkinErr != nil {
+		return nil, checkinErr
+	}
+
+	return s._ScanDTOFormat(event, participant, ctx), nil
+}
+
+// Changes when a participant checked in. A participant who was not there is
+// revoked instead, so a check-in row always means attendance. The reason
+// replaces the comment and is kept in the audit log.
+func (s *service) CorrectCheckInService(eventIDStr string, participantIDStr string, userIDStr string, req *dtoReq.CorrectCheckinReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError) {
+	event, participant, lookupErr := s._GetCheckInForManager(eventIDStr, participantIDStr, userIDStr, ctx)
+	if lookupErr != nil {
+		return nil, lookupErr
+	}
+	reason, reasonErr := _CheckInReason(req.Comment)
+	if reasonErr != nil {
+		return nil, reasonErr
+	}
+	if req.CheckinTimestamp == nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'checkin_timestamp' is required, revoke the check-in if the participant was not there",
+			Status:  400,
+		}
+	}
+	if req.CheckinTimestamp.After(time.Now()) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'checkin_timestamp' must not be in the future",
+			Status:  400,
+		}
+	}
+
+	before := _AuditParticipant(participant)
+	corrected := *participant
+	corrected.CheckinTimestamp = req.CheckinTimestamp
+	corrected.Comment = &reason
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Participant.CorrectParticipant(participant.ID, *req.CheckinTimestamp, &reason, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditParticipantUpdate,
+			TargetType: auditTargetParticipant,
+			TargetID:   participant.ID.String(),
+			EventID:    &event.ID,
+			Before:     before,
+			After:      _AuditParticipant(&corrected),
+			Reason:     &reason,
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", event.ID.String()).
+			Str("function", "ParticipantRepository.CorrectParticipant").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._ScanDTOFormat(event, &corrected, ctx), nil
+}
+
+// Removes a check-in made by mistake. The record is deleted so the participant
+// can be checked in again, the audit log keeps what it was and why it went.
+func (s *service) RevokeCheckInService(eventIDStr string, participantIDStr string, userIDStr string, req *dtoReq.RevokeCheckinReq, ctx context.Context) *response.APIError {
+	event, participant, lookupErr := s._GetCheckInForManager(eventIDStr, participantIDStr, userIDStr, ctx)
+	if lookupErr != nil {
+		return lookupErr
+	}
+	reason, reasonErr := _CheckInReason(req.Comment)
+	if reasonErr != nil {
+		return reasonErr
+	}
+
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		deleted, err := s.repo.Participant.DeleteParticipant(event.ID, participant.ID, ctx)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return s._Audit(auditEntry{
+			Action:     auditParticipantRevoke,
+			TargetType: auditTargetParticipant,
+			TargetID:   participant.ID.String(),
+			EventID:    &event.ID,
+			Before:     _AuditParticipant(participant),
+			Reason:     &reason,
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Check-in not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", event.ID.String()).
+			Str("function", "ParticipantRepository.DeleteParticipant").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return nil
+}
+
+// loads a check-in of the event for an owner or manager about to change it
+func (s *service) _GetCheckInForManager(eventIDStr string, participantIDStr string, userIDStr string, ctx context.Context) (*entity.Event, *entity.EventParticipants, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+	if err := uuid.Validate(participantIDStr); err != nil {
+		return nil, nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'participantId'",
+			Status:  400,
+		}
+	}
+	participantID := datatypes.UUID(datatypes.BinUUIDFromString(participantIDStr))
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+
+	event, eventErr := s._GetEventForScanner(eventID, userID, ctx)
+	if eventErr != nil {
+		return nil, nil, eventErr
+	}
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, nil, roleErr
+	}
+
+	participant, err := s.repo.Participant.GetParticipantByID(eventID, participantID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Check-in not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetParticipantByID").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return event, participant, nil
+}
+
+func _CheckInReason(comment string) (string, *response.APIError) {
+	reason := strings.TrimSpace(comment)
+	if reason == "" {
+		return "", &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'comment' is required and must give the reason",
+			Status:  400,
+		}
+	}
+	return reason, nil
+}
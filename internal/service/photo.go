@@ -0,0 +1,343 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/storage"
+	"gorm.io/gorm"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const (
+	ErrInvalidPhoto  = "INVALID_PHOTO"
+	ErrPhotoTooLarge = "PHOTO_TOO_LARGE"
+)
+
+// Rejects decompression bombs before the pixels are allocated. The decoded
+// source is held in memory while it is resized, 16M pixels is at most 64MB.
+const photoMaxSourcePixels = 16_000_000
+
+type PhotoService interface {
+	UploadPhotoService(userIDStr string, data []byte, ctx context.Context) (*dtoRes.PhotoRes, *response.APIError)
+	GetMyPhotoService(userIDStr string, ctx context.Context) (*dtoRes.PhotoRes, *response.APIError)
+	DeletePhotoService(userIDStr string, ctx context.Context) *response.APIError
+	GetSignedPhotoService(userIDStr string, expiresStr string, signature string, ctx context.Context) ([]byte, *response.APIError)
+}
+
+// decodes the upload, shrinks it to PHOTO_MAX_DIMENSION and stores it as JPEG
+func (s *service) UploadPhotoService(userIDStr string, data []byte, ctx context.Context) (*dtoRes.PhotoRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if len(data) > s.cfg.PhotoConfig.MaxUploadBytes {
+		return nil, &response.APIError{
+			Code:    ErrPhotoTooLarge,
+			Message: "Photo must not exceed " + strconv.Itoa(s.cfg.PhotoConfig.MaxUploadBytes) + " bytes",
+			Status:  413,
+		}
+	}
+	invalidPhoto := &response.APIError{
+		Code:    ErrInvalidPhoto,
+		Message: "Photo must be a JPEG, PNG or GIF image",
+		Status:  400,
+	}
+	config, _, configErr := image.DecodeConfig(bytes.NewReader(data))
+	if configErr != nil || config.Width == 0 || config.Height == 0 {
+		return nil, invalidPhoto
+	}
+	if config.Width*config.Height > photoMaxSourcePixels {
+		return nil, &response.APIError{
+			Code:    ErrPhotoTooLarge,
+			Message: "Photo dimensions are too large",
+			Status:  413,
+		}
+	}
+	src, _, decodeErr := image.Decode(bytes.NewReader(data))
+	if decodeErr != nil {
+		return nil, invalidPhoto
+	}
+
+	resized := _ResizeImage(src, s.cfg.PhotoConfig.MaxDimension)
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, resized, &jpeg.Options{Quality: 85}); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to encode photo")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to process photo",
+			Status:  500,
+		}
+	}
+
+	// the key changes with the content, so a cached old photo is never served for a new one
+	sum := sha256.Sum256(encoded.Bytes())
+	photo := &entity.UserPhoto{
+		UserID:    userID,
+		ObjectKey: "photos/" + userID.String() + "/" + hex.EncodeToString(sum[:8]) + ".jpg",
+		Width:     resized.Bounds().Dx(),
+		Height:    resized.Bounds().Dy(),
+		UpdatedAt: time.Now(),
+	}
+
+	previous, previousErr := s.repo.Photo.GetUserPhoto(userID, ctx)
+	if previousErr != nil && !errors.Is(previousErr, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(previousErr).
+			Str("function", "PhotoRepository.GetUserPhoto").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if err := s.blob.Put(photo.ObjectKey, encoded.Bytes(), "image/jpeg", ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "BlobStore.Put").
+			Msg("Failed to store photo")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to store photo",
+			Status:  500,
+		}
+	}
+	if err := s.repo.Photo.SaveUserPhoto(photo, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "PhotoRepository.SaveUserPhoto").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if previous != nil && previous.ObjectKey != photo.ObjectKey {
+		s._DeletePhotoObject(previous.ObjectKey, ctx)
+	}
+
+	return s._PhotoDTOFormat(photo, time.Now()), nil
+}
+
+func (s *service) GetMyPhotoService(userIDStr string, ctx context.Context) (*dtoRes.PhotoRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	photo, err := s.repo.Photo.GetUserPhoto(userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "You have not uploaded a photo",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "PhotoRepository.GetUserPhoto").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._PhotoDTOFormat(photo, time.Now()), nil
+}
+
+func (s *service) DeletePhotoService(userIDStr string, ctx context.Context) *response.APIError {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	photo, err := s.repo.Photo.GetUserPhoto(userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "You have not uploaded a photo",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "PhotoRepository.GetUserPhoto").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if _, err := s.repo.Photo.DeleteUserPhoto(userID, ctx); err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "PhotoRepository.DeleteUserPhoto").
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	s._DeletePhotoObject(photo.ObjectKey, ctx)
+	return nil
+}
+
+// Serves a photo through a URL from _SignPhotoURL. The signature takes the
+// place of the JWT, so the scanner app can load it in a plain <img> tag.
+func (s *service) GetSignedPhotoService(userIDStr string, expiresStr string, signature string, ctx context.Context) ([]byte, *response.APIError) {
+	forbidden := &response.APIError{
+		Code:    response.ErrForbidden,
+		Message: "Photo link is invalid or expired",
+		Status:  403,
+	}
+	expires, parseErr := strconv.ParseInt(expiresStr, 10, 64)
+	if parseErr != nil || time.Now().Unix() > expires {
+		return nil, forbidden
+	}
+	given, decodeErr := hex.DecodeString(signature)
+	if decodeErr != nil || !hmac.Equal(given, s._PhotoSignature(userIDStr, expires)) {
+		return nil, forbidden
+	}
+	userID, userErr := s._ParseUserID(userIDStr)
+	if userErr != nil {
+		return nil, forbidden
+	}
+
+	notFound := &response.APIError{
+		Code:    response.ErrNotFound,
+		Message: "Photo not found",
+		Status:  404,
+	}
+	photo, err := s.repo.Photo.GetUserPhoto(userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "PhotoRepository.GetUserPhoto").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	data, blobErr := s.blob.Get(photo.ObjectKey, ctx)
+	if errors.Is(blobErr, storage.ErrObjectNotFound) {
+		return nil, notFound
+	}
+	if blobErr != nil {
+		s.logger.Error().Err(blobErr).
+			Str("function", "BlobStore.Get").
+			Msg("Failed to read photo")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to read photo",
+			Status:  500,
+		}
+	}
+	return data, nil
+}
+
+func (s *service) _SignPhotoURL(userIDStr string, now time.Time) string {
+	expires := now.Add(s.cfg.PhotoConfig.URLTTL).Unix()
+	return strings.TrimSuffix(s.cfg.PublicURL, "/") + "/api/photos/" + userIDStr +
+		"?expires=" + strconv.FormatInt(expires, 10) +
+		"&signature=" + hex.EncodeToString(s._PhotoSignature(userIDStr, expires))
+}
+
+func (s *service) _PhotoSignature(userIDStr string, expires int64) []byte {
+	mac := hmac.New(sha256.New, []byte(s.cfg.JWTSecret))
+	mac.Write([]byte("photo:" + userIDStr + ":" + strconv.FormatInt(expires, 10)))
+	return mac.Sum(nil)
+}
+
+// a leftover object only wastes space, so failures are logged and not returned
+func (s *service) _DeletePhotoObject(key string, ctx context.Context) {
+	if err := s.blob.Delete(key, ctx); err != nil {
+		s.logger.Warn().Err(err).
+			Str("object_key", key).
+			Str("function", "BlobStore.Delete").
+			Msg("Failed to delete photo object")
+	}
+}
+
+func (s *service) _PhotoDTOFormat(photo *entity.UserPhoto, now time.Time) *dtoRes.PhotoRes {
+	return &dtoRes.PhotoRes{
+		URL:       s._SignPhotoURL(photo.UserID.String(), now),
+		Width:     photo.Width,
+		Height:    photo.Height,
+		UpdatedAt: photo.UpdatedAt.UTC(),
+	}
+}
+
+// Scales src down so neither side exceeds maxDimension, averaging the source
+// pixels that fall into each destination pixel. Smaller images keep their size.
+// The source is read in place and only the result is allocated, JPEG has no
+// alpha so each averaged pixel is flattened onto white.
+func _ResizeImage(src image.Image, maxDimension int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if maxDimension > 0 && (srcW > maxDimension || srcH > maxDimension) {
+		dstW, dstH = maxDimension, maxDimension
+		if srcW > srcH {
+			dstH = max(1, srcH*maxDimension/srcW)
+		} else {
+			dstW = max(1, srcW*maxDimension/srcH)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := range dstH {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := range dstW {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+
+			// RGBA returns 16-bit alpha-premultiplied channels
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			r, g, b, a = r/n, g/n, b/n, a/n
+
+			// over white, what the alpha leaves uncovered shows white
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8((r + 0xffff - a) >> 8)
+			dst.Pix[i+1] = uint8((g + 0xffff - a) >> 8)
+			dst.Pix[i+2] = uint8((b + 0xffff - a) >> 8)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
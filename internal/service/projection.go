@@ -0,0 +1,98 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"gorm.io/datatypes"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+// Which identity fields of a participant a response may carry. Every response
+// that shows a participant to scanners or staff is built through
+// _ProjectParticipant, so a field can only appear when the projection allows it.
+type participantProjection struct {
+	Name         bool
+	Organization bool
+	RefID        bool
+	Photo        bool
+}
+
+// everything known about a participant, before projection
+type participantIdentity struct {
+	UserID       datatypes.UUID
+	RefID        uint64
+	FirstnameTH  string
+	SurnameTH    string
+	FirstnameEN  string
+	SurnameEN    string
+	Organization string
+	HasPhoto     bool
+}
+
+// used for explicit OWNER/MANAGER overrides, which are logged
+var fullProjection = participantProjection{Name: true, Organization: true, RefID: true, Photo: true}
+
+// builds the projection from the RevealedFields of an event or series
+func _RevealedProjection[F ~[]T, T ~string](revealedFields F) participantProjection {
+	projection := participantProjection{}
+	for _, field := range revealedFields {
+		switch string(field) {
+		case string(entity.NAME):
+			projection.Name = true
+		case string(entity.ORGANIZATION):
+			projection.Organization = true
+		case string(entity.REFID):
+			projection.RefID = true
+		case string(entity.PHOTO):
+			projection.Photo = true
+		}
+	}
+	return projection
+}
+
+// the fields this projection keeps hidden, in RevealedFields spelling
+func (p participantProjection) _Hidden() []string {
+	hidden := []string{}
+	if !p.Name {
+		hidden = append(hidden, string(entity.NAME))
+	}
+	if !p.Organization {
+		hidden = append(hidden, string(entity.ORGANIZATION))
+	}
+	if !p.RefID {
+		hidden = append(hidden, string(entity.REFID))
+	}
+	if !p.Photo {
+		hidden = append(hidden, string(entity.PHOTO))
+	}
+	return hidden
+}
+
+func (s *service) _ProjectParticipant(projection participantProjection, identity participantIdentity, now time.Time) dtoRes.RevealedParticipantRes {
+	res := dtoRes.RevealedParticipantRes{}
+	if projection.RefID {
+		refID := s.FormatRefIdToStr(identity.RefID)
+		res.RefID = &refID
+	}
+	if projection.Name {
+		nameTH := strings.TrimSpace(identity.FirstnameTH + " " + identity.SurnameTH)
+		nameEN := strings.TrimSpace(identity.FirstnameEN + " " + identity.SurnameEN)
+		res.NameTH = &nameTH
+		res.NameEN = &nameEN
+	}
+	if projection.Organization {
+		organization := identity.Organization
+		if organization == "" {
+			organization = s._ParticipantOrganization(identity.RefID)
+		}
+		res.Organization = &organization
+	}
+	if projection.Photo && identity.HasPhoto {
+		url := s._SignPhotoURL(identity.UserID.String(), now)
+		res.PhotoURL = &url
+	}
+	return res
+}
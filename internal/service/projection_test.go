@@ -0,0 +1,167 @@
+package service
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/config"
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"gorm.io/datatypes"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+var testIdentity = participantIdentity{
+	UserID:      datatypes.UUID(datatypes.BinUUIDFromString("7d0b2f6e-3f4c-4a8e-9b1d-2c5e6f7a8b9c")),
+	RefID:       6530000121,
+	FirstnameTH: "สมชาย",
+	SurnameTH:   "ใจดี",
+	FirstnameEN: "Somchai",
+	SurnameEN:   "Jaidee",
+	HasPhoto:    true,
+}
+
+// the JSON keys each revealed field may add, and values that must not leak without it
+type fieldLeak struct {
+	field  string
+	keys   []string
+	values []string
+}
+
+var fieldLeaks = []fieldLeak{
+	{field: "NAME", keys: []string{"name_th", "name_en"}, values: []string{"สมชาย", "ใจดี", "Somchai", "Jaidee"}},
+	{field: "ORGANIZATION", keys: []string{"organization"}, values: []string{`"21"`}},
+	{field: "REFID", keys: []string{"ref_id"}, values: []string{"6530000121"}},
+	{field: "PHOTO", keys: []string{"photo_url"}, values: []string{"/api/photos/"}},
+}
+
+func newProjectionTestService() *service {
+	return &service{cfg: &config.Config{
+		JWTSecret:   "test-secret",
+		PublicURL:   "https://quickattend.test",
+		PhotoConfig: config.PhotoConfig{URLTTL: time.Minute},
+	}}
+}
+
+// every combination of NAME, ORGANIZATION, REFID and PHOTO
+func allRevealedFieldSets() [][]string {
+	var sets [][]string
+	for mask := range 1 << len(fieldLeaks) {
+		set := []string{}
+		for i, leak := range fieldLeaks {
+			if mask&(1<<i) != 0 {
+				set = append(set, leak.field)
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+func eventWithRevealedFields(fields []string) entity.Event {
+	event := entity.Event{}
+	for _, field := range fields {
+		switch field {
+		case "NAME":
+			event.RevealedFields = append(event.RevealedFields, entity.NAME)
+		case "ORGANIZATION":
+			event.RevealedFields = append(event.RevealedFields, entity.ORGANIZATION)
+		case "REFID":
+			event.RevealedFields = append(event.RevealedFields, entity.REFID)
+		case "PHOTO":
+			event.RevealedFields = append(event.RevealedFields, entity.PHOTO)
+		}
+	}
+	return event
+}
+
+func assertOnlyRevealed(t *testing.T, revealed []string, payload any) {
+	t.Helper()
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var keys map[string]any
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	for _, leak := range fieldLeaks {
+		isRevealed := slices.Contains(revealed, leak.field)
+		for _, key := range leak.keys {
+			if _, present := keys[key]; present != isRevealed {
+				t.Errorf("revealed %v: key %q present = %v, want %v\n%s", revealed, key, present, isRevealed, raw)
+			}
+		}
+		if isRevealed {
+			continue
+		}
+		for _, value := range leak.values {
+			if strings.Contains(string(raw), value) {
+				t.Errorf("revealed %v: hidden %s value %q found in JSON\n%s", revealed, leak.field, value, raw)
+			}
+		}
+	}
+}
+
+func TestProjectionHidesUnrevealedFields(t *testing.T) {
+	s := newProjectionTestService()
+	now := time.Now()
+
+	for _, revealed := range allRevealedFieldSets() {
+		event := eventWithRevealedFields(revealed)
+		projected := s._ProjectParticipant(_RevealedProjection(event.RevealedFields), testIdentity, now)
+
+		assertOnlyRevealed(t, revealed, dtoRes.ScanRes{
+			ID:                     "scan-id",
+			ParticipantID:          testIdentity.UserID.String(),
+			ScannedTimestamp:       now,
+			RevealedParticipantRes: projected,
+		})
+		assertOnlyRevealed(t, revealed, dtoRes.EventParticipantRes{
+			ID:                     "scan-id",
+			ParticipantID:          testIdentity.UserID.String(),
+			ScannedTimestamp:       now,
+			RevealedParticipantRes: projected,
+		})
+		assertOnlyRevealed(t, revealed, dtoRes.SeriesAttendanceRes{
+			UserID:                 testIdentity.UserID.String(),
+			RevealedParticipantRes: projected,
+			Attended:               3,
+			Held:                   4,
+		})
+	}
+}
+
+func TestProjectionOmitsPhotoWhenParticipantHasNone(t *testing.T) {
+	s := newProjectionTestService()
+	identity := testIdentity
+	identity.HasPhoto = false
+
+	event := eventWithRevealedFields([]string{"PHOTO"})
+	projected := s._ProjectParticipant(_RevealedProjection(event.RevealedFields), identity, time.Now())
+	if projected.PhotoURL != nil {
+		t.Fatalf("photo_url = %q, want none for a participant without a photo", *projected.PhotoURL)
+	}
+}
+
+func TestProjectionHiddenListsOverriddenFields(t *testing.T) {
+	event := eventWithRevealedFields([]string{"NAME", "PHOTO"})
+	hidden := _RevealedProjection(event.RevealedFields)._Hidden()
+	if want := []string{"ORGANIZATION", "REFID"}; !slices.Equal(hidden, want) {
+		t.Fatalf("hidden = %v, want %v", hidden, want)
+	}
+	if hidden := fullProjection._Hidden(); len(hidden) != 0 {
+		t.Fatalf("full projection hides %v", hidden)
+	}
+}
+
+func TestFullProjectionRevealsEverything(t *testing.T) {
+	s := newProjectionTestService()
+	projected := s._ProjectParticipant(fullProjection, testIdentity, time.Now())
+	assertOnlyRevealed(t, []string{"NAME", "ORGANIZATION", "REFID", "PHOTO"}, projected)
+}
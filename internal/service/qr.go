@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// QR codes are HS256 tokens carrying "sub" instead of "user_id",
+// so they can never be accepted by the AuthRequired middleware
+const participantQRType = "participant_qr"
+
+func (s *service) _SignParticipantQR(userID datatypes.UUID, now time.Time) (string, time.Time, error) {
+	if s.cfg.JWTSecret == "" {
+		return "", time.Time{}, errors.New("JWT signing key not configured")
+	}
+
+	expiresAt := now.Add(s.cfg.ScanConfig.QRCodeTTL)
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256,
+		jwt.MapClaims{
+			"sub": userID.String(),
+			"typ": participantQRType,
+			"iat": now.Unix(),
+			"exp": expiresAt.Unix(),
+		})
+
+	code, err := t.SignedString([]byte(s.cfg.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return code, expiresAt, nil
+}
+
+// Verifies a participant QR code as of scannedAt rather than the current time,
+// so that codes captured offline are still valid when they are synced later.
+// A scannedAt before the code was issued is rejected, so a backdated scan
+// cannot claim a time the code was not yet shown.
+func (s *service) _VerifyParticipantQR(code string, scannedAt time.Time) (datatypes.UUID, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(
+		code,
+		claims,
+		func(t *jwt.Token) (any, error) {
+			return []byte(s.cfg.JWTSecret), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithTimeFunc(func() time.Time { return scannedAt }),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil || token == nil || !token.Valid {
+		return datatypes.UUID{}, fmt.Errorf("invalid participant QR code: %w", err)
+	}
+
+	if typ, _ := claims["typ"].(string); typ != participantQRType {
+		return datatypes.UUID{}, errors.New("token is not a participant QR code")
+	}
+
+	sub, _ := claims["sub"].(string)
+	if uuid.Validate(sub) != nil {
+		return datatypes.UUID{}, errors.New("participant QR code has invalid subject")
+	}
+
+	return datatypes.UUID(datatypes.BinUUIDFromString(sub)), nil
+}
+
+// Event QR codes are shown by the organizer for self check-in. Every display
+// of the event shows the same code during a rotation window, and a code is
+// accepted until the end of the window after it, so a participant who scans
+// just before it rotates is not turned away but a shared photo soon expires.
+const eventQRType = "event_qr"
+
+func (s *service) _SignEventQR(eventID datatypes.UUID, rotation time.Duration, now time.Time) (string, time.Time, time.Time, error) {
+	if s.cfg.JWTSecret == "" {
+		return "", time.Time{}, time.Time{}, errors.New("JWT signing key not configured")
+	}
+
+	windowStart := now.Truncate(rotation)
+	rotatesAt := windowStart.Add(rotation)
+	expiresAt := rotatesAt.Add(rotation)
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256,
+		jwt.MapClaims{
+			"sub": eventID.String(),
+			"typ": eventQRType,
+			"iat": windowStart.Unix(),
+			"exp": expiresAt.Unix(),
+		})
+
+	code, err := t.SignedString([]byte(s.cfg.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	return code, rotatesAt, expiresAt, nil
+}
+
+func (s *service) _VerifyEventQR(code string, eventID datatypes.UUID, now time.Time) error {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(
+		code,
+		claims,
+		func(t *jwt.Token) (any, error) {
+			return []byte(s.cfg.JWTSecret), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || token == nil || !token.Valid {
+		return fmt.Errorf("invalid event QR code: %w", err)
+	}
+
+	if typ, _ := claims["typ"].(string); typ != eventQRType {
+		return errors.New("token is not an event QR code")
+	}
+	if sub, _ := claims["sub"].(string); sub != eventID.String() {
+		return errors.New("event QR code is for another event")
+	}
+	return nil
+}
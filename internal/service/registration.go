@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const (
+	ErrAlreadyRegistered = "ALREADY_REGISTERED"
+	ErrEventFull         = "EVENT_FULL"
+)
+
+type RegistrationService interface {
+	RegisterService(eventIDStr string, userIDStr string, req *dtoReq.RegisterReq, ctx context.Context) (*dtoRes.RegistrationRes, *response.APIError)
+	CancelRegistrationService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.RegistrationRes, *response.APIError)
+}
+
+func (s *service) RegisterService(eventIDStr string, userIDStr string, req *dtoReq.RegisterReq, ctx context.Context) (*dtoRes.RegistrationRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	event, eventErr := s._GetOpenEvent(eventID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+
+	user, userErr := s.repo.Auth.GetUserById(userID, ctx)
+	if userErr != nil {
+		s.logger.Error().Err(userErr).
+			Str("function", "AuthRepository.GetUserById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if eligibleErr := s._CheckEligibility(event, &user, ctx); eligibleErr != nil {
+		return nil, eligibleErr
+	}
+	if consentErr := s._RequireConsent(event, userID, req.AcceptPrivacyNotice, string(entity.CONSENT_REGISTRATION), ctx); consentErr != nil {
+		return nil, consentErr
+	}
+
+	registration, err := s.repo.Registration.Register(eventID, userID, ctx)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    ErrAlreadyRegistered,
+			Message: "You are already registered or waitlisted for this event",
+			Status:  409,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "RegistrationRepository.Register").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._RegistrationDTOFormat(registration, ctx)
+}
+
+func (s *service) CancelRegistrationService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.RegistrationRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, eventErr := s._GetOpenEvent(eventID, ctx); eventErr != nil {
+		return nil, eventErr
+	}
+
+	cancelled, promoted, err := s.repo.Registration.CancelRegistration(eventID, userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "You are not registered for this event",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "RegistrationRepository.CancelRegistration").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	for _, registration := range promoted {
+		s.logger.Info().
+			Str("event_id", eventID.String()).
+			Str("user_id", registration.UserID.String()).
+			Msg("Promoted from waitlist")
+	}
+
+	return s._RegistrationDTOFormat(cancelled, ctx)
+}
+
+// loads an event that can still be registered for or cancelled
+func (s *service) _GetOpenEvent(eventID datatypes.UUID, ctx context.Context) (*entity.Event, *response.APIError) {
+	event, err := s.repo.Participant.GetEventById(eventID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Event with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if event.EndTime.Before(time.Now()) {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Event has already ended",
+			Status:  409,
+		}
+	}
+	return event, nil
+}
+
+// seats left for a capacity limited event, nil when the event has no capacity
+func (s *service) _RemainingSeats(eventID datatypes.UUID, capacity *uint32, ctx context.Context) (*int64, *response.APIError) {
+	if capacity == nil {
+		return nil, nil
+	}
+
+	taken, err := s.repo.Registration.CountTakenSeats(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "RegistrationRepository.CountTakenSeats").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	remaining := max(int64(*capacity)-taken, 0)
+	return &remaining, nil
+}
+
+// registered vs. attended counts for the organizers
+func (s *service) _RegistrationStats(eventID datatypes.UUID, ctx context.Context) (*dtoRes.RegistrationStatsRes, *response.APIError) {
+	stats, err := s.repo.Registration.GetRegistrationStats(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "RegistrationRepository.GetRegistrationStats").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := dtoRes.RegistrationStatsRes{
+		Registered: stats.Registered,
+		Attended:   stats.Attended,
+		NoShow:     stats.Registered - stats.Attended,
+		Waitlisted: stats.Waitlisted,
+		WalkIns:    stats.WalkIns,
+	}
+	if stats.Registered > 0 {
+		ratio := float64(res.NoShow) / float64(stats.Registered)
+		res.NoShowRatio = &ratio
+	}
+	return &res, nil
+}
+
+func (s *service) _RegistrationDTOFormat(registration *entity.EventRegistration, ctx context.Context) (*dtoRes.RegistrationRes, *response.APIError) {
+	res := dtoRes.RegistrationRes{
+		EventID:      registration.EventID.String(),
+		Status:       string(registration.Status),
+		RegisteredAt: registration.RegisteredAt.UTC(),
+	}
+
+	if registration.Status == entity.WAITLISTED {
+		position, err := s.repo.Registration.GetWaitlistPosition(registration.EventID, registration.UserID, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("event_id", registration.EventID.String()).
+				Str("function", "RegistrationRepository.GetWaitlistPosition").
+				Msg("Internal DB error")
+			return nil, &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Internal DB error",
+				Status:  500,
+			}
+		}
+		res.WaitlistPosition = &position
+	}
+
+	return &res, nil
+}
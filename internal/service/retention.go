@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/gorm"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+type RetentionService interface {
+	PurgeExpiredRetentionService(ctx context.Context)
+	GetRetentionPurgesService(queryParams map[string]string, ctx context.Context) (*[]dtoRes.RetentionPurgeRes, *response.Pagination, *response.APIError)
+}
+
+// Run by the retention job. Anonymises every event whose retention period has
+// ended, one batch at a time; a failed event is logged and retried next run.
+func (s *service) PurgeExpiredRetentionService(ctx context.Context) {
+	now := time.Now()
+	batchSize := max(s.cfg.RetentionConfig.BatchSize, 1)
+
+	for ctx.Err() == nil {
+		events, err := s.repo.Retention.GetEventsDueForPurge(now, batchSize, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("function", "RetentionRepository.GetEventsDueForPurge").
+				Msg("Internal DB error")
+			return
+		}
+
+		purged := 0
+		for _, event := range *events {
+			var purge *entity.RetentionPurge
+			purgeErr := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+				var err error
+				purge, err = s.repo.Retention.PurgeEventPersonalData(event.ID, now, ctx)
+				if err != nil {
+					return err
+				}
+				return s._Audit(auditEntry{
+					Action:     auditEventPurge,
+					TargetType: auditTargetEvent,
+					TargetID:   event.ID.String(),
+					EventID:    &event.ID,
+					After: map[string]any{
+						"retention_days": purge.RetentionDays,
+						"participants":   purge.Participants,
+						"registrations":  purge.Registrations,
+						"consents":       purge.Consents,
+					},
+				}, ctx)
+			}, ctx)
+			if errors.Is(purgeErr, gorm.ErrRecordNotFound) {
+				continue
+			}
+			// the purge was rolled back with its audit row, the next run retries it
+			if errors.Is(purgeErr, errAuditFailed) {
+				continue
+			}
+			if purgeErr != nil {
+				s.logger.Error().Err(purgeErr).
+					Str("event_id", event.ID.String()).
+					Str("function", "RetentionRepository.PurgeEventPersonalData").
+					Msg("Internal DB error")
+				continue
+			}
+			purged++
+			s.logger.Info().
+				Str("event_id", event.ID.String()).
+				Int64("participants", purge.Participants).
+				Int64("registrations", purge.Registrations).
+				Int64("consents", purge.Consents).
+				Msg("Event personal data purged")
+		}
+
+		// a short or entirely failing batch means there is nothing more to do this run
+		if len(*events) < batchSize || purged == 0 {
+			return
+		}
+	}
+}
+
+func (s *service) GetRetentionPurgesService(queryParams map[string]string, ctx context.Context) (*[]dtoRes.RetentionPurgeRes, *response.Pagination, *response.APIError) {
+	page := 0
+	if pageQuery, ok := queryParams["page"]; ok {
+		pageInt, err := strconv.Atoi(pageQuery)
+		if err != nil || pageInt < 0 {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'page' must be a non-negative int",
+				Status:  400,
+			}
+		}
+		page = pageInt
+	}
+	pageSize := 20
+	if sizeQuery, ok := queryParams["pageSize"]; ok {
+		sizeInt, err := strconv.Atoi(sizeQuery)
+		if err != nil || sizeInt < 1 || sizeInt > 100 {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'pageSize' must be an int from 1 to 100",
+				Status:  400,
+			}
+		}
+		pageSize = sizeInt
+	}
+
+	purges, total, err := s.repo.Retention.GetRetentionPurges(page, pageSize, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "RetentionRepository.GetRetentionPurges").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := []dtoRes.RetentionPurgeRes{}
+	for _, purge := range *purges {
+		var eventID *string
+		if purge.EventID != nil {
+			id := purge.EventID.String()
+			eventID = &id
+		}
+		res = append(res, dtoRes.RetentionPurgeRes{
+			ID:            purge.ID.String(),
+			EventID:       eventID,
+			EventName:     purge.EventName,
+			EventEndTime:  purge.EventEndTime.UTC(),
+			RetentionDays: purge.RetentionDays,
+			Participants:  purge.Participants,
+			Registrations: purge.Registrations,
+			Consents:      purge.Consents,
+			PurgedAt:      purge.PurgedAt.UTC(),
+		})
+	}
+
+	return &res, &response.Pagination{
+		Page:     &page,
+		PageSize: pageSize,
+		Total:    &total,
+		HasNext:  int64((page+1)*pageSize) < total,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxSeriesOccurrences = 100
+
+// The supported RFC 5545 RRULE subset: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL,
+// BYDAY (weekly only, plain weekdays such as MO,WE) and exactly one of COUNT or UNTIL.
+type recurrenceRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    *time.Time
+	ByDay    []time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+func (s *service) _ParseRRule(rule string) (*recurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, errors.New("rule is empty")
+	}
+
+	parsed := recurrenceRule{Interval: 1}
+	seen := map[string]bool{}
+	for part := range strings.SplitSeq(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is repeated", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return nil, fmt.Errorf("FREQ=%s is not supported", value)
+			}
+			parsed.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > 365 {
+				return nil, errors.New("INTERVAL must be between 1 and 365")
+			}
+			parsed.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 || count > maxSeriesOccurrences {
+				return nil, fmt.Errorf("COUNT must be between 1 and %d", maxSeriesOccurrences)
+			}
+			parsed.Count = count
+		case "UNTIL":
+			until, err := s._ParseRRuleUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			parsed.Until = &until
+		case "BYDAY":
+			for day := range strings.SplitSeq(value, ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("BYDAY=%s is not supported", day)
+				}
+				if !slices.Contains(parsed.ByDay, weekday) {
+					parsed.ByDay = append(parsed.ByDay, weekday)
+				}
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("%s is not supported", name)
+		}
+	}
+
+	if parsed.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if (parsed.Count == 0) == (parsed.Until == nil) {
+		return nil, errors.New("exactly one of COUNT or UNTIL is required")
+	}
+	if len(parsed.ByDay) > 0 && parsed.Freq != "WEEKLY" {
+		return nil, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+
+	return &parsed, nil
+}
+
+// UNTIL is either a UTC date-time (20261231T235959Z) or a date, which includes the whole day in Bangkok time
+func (s *service) _ParseRRuleUntil(value string) (time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, nil
+	}
+	if date, err := time.ParseInLocation("20060102", value, bangkokTime); err == nil {
+		return date.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, errors.New("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+// Expands the rule into occurrence start times, the first one being start itself.
+// Weekdays and month days are taken in Bangkok time so that an evening meeting does not
+// shift to another day in UTC. Fails if start does not match BYDAY or if the rule gives
+// more than maxSeriesOccurrences occurrences.
+func (s *service) _ExpandRRule(rule *recurrenceRule, start time.Time) ([]time.Time, error) {
+	start = start.In(bangkokTime)
+	byDay := rule.ByDay
+	if rule.Freq == "WEEKLY" && len(byDay) == 0 {
+		byDay = []time.Weekday{start.Weekday()}
+	}
+	if rule.Freq == "WEEKLY" && !slices.Contains(byDay, start.Weekday()) {
+		return nil, errors.New("start time must fall on one of the BYDAY weekdays")
+	}
+
+	occurrences := []time.Time{}
+	// returns false once the rule is exhausted
+	add := func(t time.Time) (bool, error) {
+		if rule.Until != nil && t.After(*rule.Until) {
+			return false, nil
+		}
+		if len(occurrences) == maxSeriesOccurrences {
+			return false, fmt.Errorf("rule gives more than %d occurrences", maxSeriesOccurrences)
+		}
+		occurrences = append(occurrences, t)
+		return rule.Count == 0 || len(occurrences) < rule.Count, nil
+	}
+
+	switch rule.Freq {
+	case "DAILY":
+		for i := 0; ; i++ {
+			more, err := add(start.AddDate(0, 0, i*rule.Interval))
+			if err != nil || !more {
+				return occurrences, err
+			}
+		}
+
+	case "WEEKLY":
+		// weeks start on Monday
+		offsets := make([]int, len(byDay))
+		for i, day := range byDay {
+			offsets[i] = (int(day) + 6) % 7
+		}
+		slices.Sort(offsets)
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+
+		for week := 0; ; week++ {
+			for _, offset := range offsets {
+				t := weekStart.AddDate(0, 0, week*7*rule.Interval+offset)
+				if t.Before(start) {
+					continue
+				}
+				more, err := add(t)
+				if err != nil || !more {
+					return occurrences, err
+				}
+			}
+		}
+
+	case "MONTHLY":
+		// months without the start's day of month are skipped, as in RFC 5545
+		for i := 0; i < maxSeriesOccurrences*12; i++ {
+			t := time.Date(start.Year(), start.Month()+time.Month(i*rule.Interval), start.Day(),
+				start.Hour(), start.Minute(), start.Second(), 0, bangkokTime)
+			if t.Day() != start.Day() {
+				continue
+			}
+			more, err := add(t)
+			if err != nil || !more {
+				return occurrences, err
+			}
+		}
+		return occurrences, nil
+	}
+
+	return nil, fmt.Errorf("FREQ=%s is not supported", rule.Freq)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func bangkokDate(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, bangkokTime)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestExpandRRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		// occurrences in Bangkok time, checked when wantCount is 0
+		want      []string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:  "weekly BYDAY with INTERVAL skips every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=5",
+			start: "2026-01-07 18:00",
+			want:  []string{"2026-01-07 18:00", "2026-01-19 18:00", "2026-01-21 18:00", "2026-02-02 18:00", "2026-02-04 18:00"},
+		},
+		{
+			name:  "weekly BYDAY order does not matter",
+			rule:  "FREQ=WEEKLY;BYDAY=FR,TU;COUNT=3",
+			start: "2026-01-06 09:00",
+			want:  []string{"2026-01-06 09:00", "2026-01-09 09:00", "2026-01-13 09:00"},
+		},
+		{
+			name:    "weekly start must fall on a BYDAY weekday",
+			rule:    "FREQ=WEEKLY;BYDAY=MO;COUNT=3",
+			start:   "2026-01-07 18:00",
+			wantErr: true,
+		},
+		{
+			name:  "monthly on the 31st skips shorter months",
+			rule:  "FREQ=MONTHLY;COUNT=4",
+			start: "2026-01-31 09:00",
+			want:  []string{"2026-01-31 09:00", "2026-03-31 09:00", "2026-05-31 09:00", "2026-07-31 09:00"},
+		},
+		{
+			name:  "monthly with INTERVAL and UNTIL",
+			rule:  "FREQ=MONTHLY;INTERVAL=3;UNTIL=20261231",
+			start: "2026-01-15 13:00",
+			want:  []string{"2026-01-15 13:00", "2026-04-15 13:00", "2026-07-15 13:00", "2026-10-15 13:00"},
+		},
+		{
+			name:  "date-only UNTIL includes the whole day in Bangkok time",
+			rule:  "FREQ=DAILY;UNTIL=20260103",
+			start: "2026-01-01 23:30",
+			want:  []string{"2026-01-01 23:30", "2026-01-02 23:30", "2026-01-03 23:30"},
+		},
+		{
+			name:  "date-only UNTIL ends at midnight in Bangkok, not in UTC",
+			rule:  "FREQ=DAILY;UNTIL=20260103",
+			start: "2026-01-01 03:00",
+			want:  []string{"2026-01-01 03:00", "2026-01-02 03:00", "2026-01-03 03:00"},
+		},
+		{
+			name:  "UTC date-time UNTIL is exact",
+			rule:  "FREQ=DAILY;UNTIL=20260102T110000Z",
+			start: "2026-01-01 18:00",
+			want:  []string{"2026-01-01 18:00", "2026-01-02 18:00"},
+		},
+		{
+			name:      "COUNT may reach the occurrence cap",
+			rule:      "FREQ=DAILY;COUNT=100",
+			start:     "2026-01-01 09:00",
+			wantCount: maxSeriesOccurrences,
+		},
+		{
+			name:    "COUNT above the occurrence cap is rejected",
+			rule:    "FREQ=DAILY;COUNT=101",
+			start:   "2026-01-01 09:00",
+			wantErr: true,
+		},
+		{
+			name:    "UNTIL giving more occurrences than the cap is rejected",
+			rule:    "FREQ=DAILY;UNTIL=20271231",
+			start:   "2026-01-01 09:00",
+			wantErr: true,
+		},
+		{
+			name:    "BYDAY is only supported weekly",
+			rule:    "FREQ=DAILY;BYDAY=MO;COUNT=3",
+			start:   "2026-01-05 09:00",
+			wantErr: true,
+		},
+	}
+
+	s := &service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := s._ParseRRule(tt.rule)
+			var occurrences []time.Time
+			if err == nil {
+				occurrences, err = s._ExpandRRule(rule, bangkokDate(tt.start))
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("%s: want an error, got %d occurrences", tt.rule, len(occurrences))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", tt.rule, err)
+			}
+
+			if tt.wantCount > 0 {
+				if len(occurrences) != tt.wantCount {
+					t.Fatalf("%s: %d occurrences, want %d", tt.rule, len(occurrences), tt.wantCount)
+				}
+				return
+			}
+			got := []string{}
+			for _, occurrence := range occurrences {
+				got = append(got, occurrence.In(bangkokTime).Format("2006-01-02 15:04"))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("%s:\ngot  %v\nwant %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,618 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/repository"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const (
+	ErrInvalidSignature = "INVALID_SIGNATURE"
+	ErrNotEligible      = "NOT_ELIGIBLE"
+	ErrAlreadyCheckedIn = "ALREADY_CHECKED_IN"
+	ErrOutsideEventTime = "OUTSIDE_EVENT_TIME"
+)
+
+// per-item statuses reported by POST /events/:id/scans/batch
+const (
+	batchScanAccepted         = "ACCEPTED"
+	batchScanAlreadySynced    = "ALREADY_SYNCED"
+	batchScanDuplicate        = "DUPLICATE"
+	batchScanIneligible       = "INELIGIBLE"
+	batchScanInvalidSignature = "INVALID_SIGNATURE"
+	batchScanInvalid          = "INVALID"
+
+	batchScanKeptExisting = "KEPT_EXISTING"
+	batchScanKeptEarliest = "KEPT_EARLIEST"
+)
+
+type ScanService interface {
+	ScanParticipantService(eventIDStr string, scannerIDStr string, req *dtoReq.ScanReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError)
+	BatchScanService(eventIDStr string, scannerIDStr string, req *dtoReq.BatchScanReq, ctx context.Context) (*dtoRes.BatchScanRes, *response.APIError)
+}
+
+// a single scan, either live or captured offline
+type scanAttempt struct {
+	Code           string
+	ScannedAt      time.Time
+	Location       entity.Point
+	IdempotencyKey *string
+	// the participant accepted the event's privacy notice at the scanner
+	AcceptedNotice bool
+}
+
+func (s *service) ScanParticipantService(eventIDStr string, scannerIDStr string, req *dtoReq.ScanReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	scannerID, parseErr := s._ParseUserID(scannerIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	event, eventErr := s._GetEventForScanner(eventID, scannerID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+
+	participant, scanErr := s._ApplyScan(event, scannerID, scanAttempt{
+		Code:           strings.TrimSpace(req.Code),
+		ScannedAt:      time.Now(),
+		Location:       entity.Point{X: req.ScannedLocation.X, Y: req.ScannedLocation.Y},
+		AcceptedNotice: req.AcceptPrivacyNotice,
+	}, ctx)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	return s._ScanDTOFormat(event, participant, ctx), nil
+}
+
+func (s *service) BatchScanService(eventIDStr string, scannerIDStr string, req *dtoReq.BatchScanReq, ctx context.Context) (*dtoRes.BatchScanRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	scannerID, parseErr := s._ParseUserID(scannerIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if len(req.Scans) == 0 {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'scans' must contain at least one item",
+			Status:  400,
+		}
+	}
+	if len(req.Scans) > s.cfg.ScanConfig.MaxBatchLength {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: fmt.Sprintf("'scans' must not contain more than %d items", s.cfg.ScanConfig.MaxBatchLength),
+			Status:  400,
+		}
+	}
+
+	event, eventErr := s._GetEventForScanner(eventID, scannerID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+
+	// apply scans in the order they were captured, so the earliest scan
+	// of a participant wins when several devices scanned them offline
+	order := make([]int, len(req.Scans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Scans[order[a]].ScannedTimestamp.Before(req.Scans[order[b]].ScannedTimestamp)
+	})
+
+	results := make([]dtoRes.BatchScanItemRes, len(req.Scans))
+	seenKeys := make(map[string]bool, len(req.Scans))
+	now := time.Now()
+
+	for _, i := range order {
+		item := req.Scans[i]
+		key := strings.TrimSpace(item.IdempotencyKey)
+		results[i] = s._ApplyBatchScanItem(event, scannerID, item, key, seenKeys[key], now, ctx)
+		seenKeys[key] = true
+	}
+
+	summary := dtoRes.BatchScanSummary{}
+	for _, result := range results {
+		switch result.Status {
+		case batchScanAccepted:
+			summary.Accepted++
+		case batchScanAlreadySynced:
+			summary.AlreadySynced++
+		case batchScanDuplicate:
+			summary.Duplicate++
+		case batchScanIneligible:
+			summary.Ineligible++
+		case batchScanInvalidSignature:
+			summary.InvalidSignature++
+		default:
+			summary.Invalid++
+		}
+	}
+
+	return &dtoRes.BatchScanRes{
+		Summary: summary,
+		Results: results,
+	}, nil
+}
+
+func (s *service) _ApplyBatchScanItem(event *entity.Event, scannerID datatypes.UUID, item dtoReq.BatchScanItem, key string, seenInBatch bool, now time.Time, ctx context.Context) dtoRes.BatchScanItemRes {
+	result := dtoRes.BatchScanItemRes{IdempotencyKey: key}
+
+	if key == "" || len(key) > 128 {
+		result.Status = batchScanInvalid
+		result.Message = "'idempotency_key' is required and must not exceed 128 characters"
+		return result
+	}
+	if seenInBatch {
+		result.Status = batchScanInvalid
+		result.Message = "'idempotency_key' is repeated within the batch"
+		return result
+	}
+	if item.ScannedTimestamp.IsZero() {
+		result.Status = batchScanInvalid
+		result.Message = "'scanned_timestamp' is required"
+		return result
+	}
+	if item.ScannedTimestamp.After(now.Add(s.cfg.ScanConfig.MaxClockSkew)) {
+		result.Status = batchScanInvalid
+		result.Message = "'scanned_timestamp' is in the future"
+		return result
+	}
+	if item.ScannedTimestamp.Before(now.Add(-s.cfg.ScanConfig.MaxOfflineAge)) {
+		result.Status = batchScanInvalid
+		result.Message = fmt.Sprintf("'scanned_timestamp' is more than %s ago", s.cfg.ScanConfig.MaxOfflineAge)
+		return result
+	}
+
+	// a retried sync of an item that was already applied
+	synced, syncedErr := s.repo.Participant.GetParticipantByIdempotencyKey(event.ID, key, ctx)
+	if syncedErr == nil {
+		result.Status = batchScanAlreadySynced
+		result.Scan = s._ScanDTOFormat(event, synced, ctx)
+		return result
+	}
+	if !errors.Is(syncedErr, gorm.ErrRecordNotFound) {
+		s.logger.Error().Err(syncedErr).
+			Str("event_id", event.ID.String()).
+			Str("function", "ParticipantRepository.GetParticipantByIdempotencyKey").
+			Msg("Internal DB error")
+		result.Status = batchScanInvalid
+		result.Message = "Internal DB error"
+		return result
+	}
+
+	location := entity.Point{X: item.ScannedLocation.X, Y: item.ScannedLocation.Y}
+	participant, scanErr := s._ApplyScan(event, scannerID, scanAttempt{
+		Code:           strings.TrimSpace(item.Code),
+		ScannedAt:      item.ScannedTimestamp,
+		Location:       location,
+		IdempotencyKey: &key,
+		AcceptedNotice: item.AcceptPrivacyNotice,
+	}, ctx)
+	if scanErr == nil {
+		result.Status = batchScanAccepted
+		result.Scan = s._ScanDTOFormat(event, participant, ctx)
+		return result
+	}
+
+	result.Message = scanErr.Message
+	switch scanErr.Code {
+	case ErrInvalidSignature:
+		result.Status = batchScanInvalidSignature
+	case ErrNotEligible, ErrEventFull, ErrConsentRequired:
+		result.Status = batchScanIneligible
+	case ErrAlreadyCheckedIn:
+		result.Status = batchScanDuplicate
+		result.Resolution, result.Scan = s._ResolveDuplicateScan(event, scannerID, item, location, ctx)
+	default:
+		result.Status = batchScanInvalid
+	}
+	return result
+}
+
+// Keeps the earliest scan of a participant: an offline scan that happened
+// before the stored one replaces its timestamp, location and scanner. Only
+// owners and managers may replace a scan, anyone else's duplicate leaves the
+// stored one as it is.
+func (s *service) _ResolveDuplicateScan(event *entity.Event, scannerID datatypes.UUID, item dtoReq.BatchScanItem, location entity.Point, ctx context.Context) (string, *dtoRes.ScanRes) {
+	participantID, verifyErr := s._VerifyParticipantQR(strings.TrimSpace(item.Code), item.ScannedTimestamp)
+	if verifyErr != nil {
+		return batchScanKeptExisting, nil
+	}
+
+	existing, getErr := s.repo.Participant.GetParticipant(event.ID, participantID, ctx)
+	if getErr != nil {
+		if !errors.Is(getErr, gorm.ErrRecordNotFound) {
+			s.logger.Error().Err(getErr).
+				Str("event_id", event.ID.String()).
+				Str("function", "ParticipantRepository.GetParticipant").
+				Msg("Internal DB error")
+		}
+		return batchScanKeptExisting, nil
+	}
+
+	if !item.ScannedTimestamp.Before(existing.ScannedTimestamp) {
+		return batchScanKeptExisting, s._ScanDTOFormat(event, existing, ctx)
+	}
+
+	role, roleErr := s.repo.Participant.GetEventUserRole(event.ID, scannerID, ctx)
+	if roleErr != nil {
+		s.logger.Error().Err(roleErr).
+			Str("event_id", event.ID.String()).
+			Str("function", "ParticipantRepository.GetEventUserRole").
+			Msg("Internal DB error")
+		return batchScanKeptExisting, s._ScanDTOFormat(event, existing, ctx)
+	}
+	if role == nil || (*role != string(entity.OWNER) && *role != string(entity.MANAGER)) {
+		return batchScanKeptExisting, s._ScanDTOFormat(event, existing, ctx)
+	}
+	before := _AuditParticipant(existing)
+
+	updated := *existing
+	updated.ScannedTimestamp = item.ScannedTimestamp
+	updated.ScannedLocation = &location
+	updated.ScannerID = &scannerID
+	updateErr := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Participant.UpdateParticipantScan(existing.ID, item.ScannedTimestamp, location, &scannerID, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditParticipantUpdate,
+			TargetType: auditTargetParticipant,
+			TargetID:   existing.ID.String(),
+			EventID:    &event.ID,
+			Before:     before,
+			After:      _AuditParticipant(&updated),
+		}, ctx)
+	}, ctx)
+	// the audit failure is already logged, the stored scan stays as it was
+	if errors.Is(updateErr, errAuditFailed) {
+		return batchScanKeptExisting, s._ScanDTOFormat(event, existing, ctx)
+	}
+	if updateErr != nil {
+		s.logger.Error().Err(updateErr).
+			Str("event_id", event.ID.String()).
+			Str("function", "ParticipantRepository.UpdateParticipantScan").
+			Msg("Internal DB error")
+		return batchScanKeptExisting, s._ScanDTOFormat(event, existing, ctx)
+	}
+	return batchScanKeptEarliest, s._ScanDTOFormat(event, &updated, ctx)
+}
+
+// loads the event and checks that the user is allowed to scan participants into it
+func (s *service) _GetEventForScanner(eventID datatypes.UUID, scannerID datatypes.UUID, ctx context.Context) (*entity.Event, *response.APIError) {
+	event, eventErr := s._GetEventForCheckIn(eventID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+
+	if event.AllowAllToScan {
+		return event, nil
+	}
+
+	role, roleErr := s.repo.Participant.GetEventUserRole(eventID, scannerID, ctx)
+	if roleErr != nil {
+		s.logger.Error().Err(roleErr).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventUserRole").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if role == nil {
+		return nil, &response.APIError{
+			Code:    response.ErrForbidden,
+			Message: "You are not allowed to scan participants for this event",
+			Status:  403,
+		}
+	}
+
+	return event, nil
+}
+
+// loads an event that still accepts check-ins, i.e. one whose data was not purged
+func (s *service) _GetEventForCheckIn(eventID datatypes.UUID, ctx context.Context) (*entity.Event, *response.APIError) {
+	event, err := s.repo.Participant.GetEventById(eventID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Event with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if event.RetentionPurgedAt != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Personal data of this event has been purged, it no longer accepts check-ins",
+			Status:  409,
+		}
+	}
+
+	return event, nil
+}
+
+// Scans and self check-ins are only accepted around the event's time, within
+// SCAN_WINDOW_GRACE of its start and end. Manual check-ins by organizers are not limited.
+func (s *service) _CheckCheckInTime(event *entity.Event, at time.Time) *response.APIError {
+	grace := s.cfg.ScanConfig.WindowGrace
+	if at.Before(event.StartTime.Add(-grace)) || at.After(event.EndTime.Add(grace)) {
+		return &response.APIError{
+			Code:    ErrOutsideEventTime,
+			Message: "Check-ins are only accepted around the time of the event",
+			Status:  409,
+		}
+	}
+	return nil
+}
+
+func (s *service) _ApplyScan(event *entity.Event, scannerID datatypes.UUID, attempt scanAttempt, ctx context.Context) (*entity.EventParticipants, *response.APIError) {
+	if attempt.Code == "" {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'code' is required",
+			Status:  400,
+		}
+	}
+
+	if windowErr := s._CheckCheckInTime(event, attempt.ScannedAt); windowErr != nil {
+		return nil, windowErr
+	}
+
+	participantID, verifyErr := s._VerifyParticipantQR(attempt.Code, attempt.ScannedAt)
+	if verifyErr != nil {
+		return nil, &response.APIError{
+			Code:    ErrInvalidSignature,
+			Message: "QR code is invalid or expired",
+			Status:  400,
+		}
+	}
+
+	user, userErr := s.repo.Auth.GetUserById(participantID, ctx)
+	if errors.Is(userErr, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Participant not found",
+			Status:  404,
+		}
+	}
+	if userErr != nil {
+		s.logger.Error().Err(userErr).
+			Str("function", "AuthRepository.GetUserById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	now := time.Now()
+	return s._CheckIn(event, &user, &entity.EventParticipants{
+		EventID:          event.ID,
+		CheckinTimestamp: &now,
+		ScannedTimestamp: attempt.ScannedAt,
+		ParticipantID:    user.ID,
+		Organization:     s._ParticipantOrganization(user.RefID),
+		ScannedLocation:  &attempt.Location,
+		Method:           entity.CHECKIN_SCAN,
+		ScannerID:        &scannerID,
+		IdempotencyKey:   attempt.IdempotencyKey,
+	}, attempt.AcceptedNotice, ctx)
+}
+
+// Checks the participant in after eligibility and privacy notice checks, within
+// the event's capacity. Shared by QR scans and manual check-ins.
+func (s *service) _CheckIn(event *entity.Event, user *entity.User, participant *entity.EventParticipants, acceptedNotice bool, ctx context.Context) (*entity.EventParticipants, *response.APIError) {
+	if eligibleErr := s._CheckEligibility(event, user, ctx); eligibleErr != nil {
+		return nil, eligibleErr
+	}
+	if consentErr := s._RequireConsent(event, user.ID, acceptedNotice, string(entity.CONSENT_SCAN), ctx); consentErr != nil {
+		return nil, consentErr
+	}
+
+	var (
+		created  *entity.EventParticipants
+		function = "ParticipantRepository.CreateParticipant"
+	)
+	createErr := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		var err error
+		if event.Capacity != nil {
+			function = "RegistrationRepository.CreateParticipantWithinCapacity"
+			created, err = s.repo.Registration.CreateParticipantWithinCapacity(participant, ctx)
+		} else {
+			created, err = s.repo.Participant.CreateParticipant(participant, ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := auditEntry{
+			Action:     auditParticipantCreate,
+			TargetType: auditTargetParticipant,
+			TargetID:   created.ID.String(),
+			EventID:    &event.ID,
+			After:      _AuditParticipant(created),
+		}
+		if created.Method == entity.CHECKIN_MANUAL {
+			entry.Reason = created.Comment
+		}
+		return s._Audit(entry, ctx)
+	}, ctx)
+	if errors.Is(createErr, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(createErr, repository.ErrEventFull) {
+		return nil, &response.APIError{
+			Code:    ErrEventFull,
+			Message: "Event is full",
+			Status:  409,
+		}
+	}
+	if errors.Is(createErr, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    ErrAlreadyCheckedIn,
+			Message: "Participant has already checked in to this event",
+			Status:  409,
+		}
+	}
+	if createErr != nil {
+		s.logger.Error().Err(createErr).
+			Str("event_id", event.ID.String()).
+			Str("function", function).
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return created, nil
+}
+
+// checks the participant against the event's attendance type
+func (s *service) _CheckEligibility(event *entity.Event, user *entity.User, ctx context.Context) *response.APIError {
+	var (
+		eligible bool
+		err      error
+		function string
+	)
+
+	switch event.AttendenceType {
+	case entity.ALL:
+		return nil
+	case entity.WHITELIST:
+		function = "ParticipantRepository.IsWhitelisted"
+		eligible, err = s.repo.Participant.IsWhitelisted(event.ID, user.RefID, ctx)
+	case entity.FACULTIES:
+		facultyNO, isStudent := s._FacultyNOFromRefID(user.RefID)
+		if !isStudent {
+			break
+		}
+		function = "ParticipantRepository.IsFacultyAllowed"
+		eligible, err = s.repo.Participant.IsFacultyAllowed(event.ID, facultyNO, ctx)
+	}
+
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", event.ID.String()).
+			Str("function", function).
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if !eligible {
+		return &response.APIError{
+			Code:    ErrNotEligible,
+			Message: "Participant is not eligible to attend this event",
+			Status:  403,
+		}
+	}
+	return nil
+}
+
+// student ref ids have 10 digits and end with the faculty number,
+// staff ref ids have 8 digits and belong to no faculty
+func (s *service) _FacultyNOFromRefID(refID uint64) (uint8, bool) {
+	if len(s.FormatRefIdToStr(refID)) != 10 {
+		return 0, false
+	}
+	return uint8(refID % 100), true
+}
+
+func (s *service) _ParticipantOrganization(refID uint64) string {
+	facultyNO, isStudent := s._FacultyNOFromRefID(refID)
+	if !isStudent {
+		return "STAFF"
+	}
+	return fmt.Sprintf("%02d", facultyNO)
+}
+
+// the participant's identity is projected onto the event's revealed fields,
+// a lookup that fails only drops the fields it was needed for
+func (s *service) _ScanDTOFormat(event *entity.Event, participant *entity.EventParticipants, ctx context.Context) *dtoRes.ScanRes {
+	projection := _RevealedProjection(event.RevealedFields)
+	identity := participantIdentity{
+		UserID:       participant.ParticipantID,
+		Organization: participant.Organization,
+	}
+
+	if projection.Name || projection.RefID {
+		user, err := s.repo.Auth.GetUserById(participant.ParticipantID, ctx)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("event_id", event.ID.String()).
+				Str("function", "AuthRepository.GetUserById").
+				Msg("Internal DB error")
+			projection.Name, projection.RefID = false, false
+		} else {
+			identity.RefID = user.RefID
+			identity.FirstnameTH, identity.SurnameTH = user.FirstnameTH, user.SurnameTH
+			identity.FirstnameEN, identity.SurnameEN = user.FirstnameEN, user.SurnameEN
+		}
+	}
+	if projection.Photo {
+		_, err := s.repo.Photo.GetUserPhoto(participant.ParticipantID, ctx)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error().Err(err).
+				Str("event_id", event.ID.String()).
+				Str("function", "PhotoRepository.GetUserPhoto").
+				Msg("Internal DB error")
+		}
+		identity.HasPhoto = err == nil
+	}
+
+	var checkin *time.Time
+	if participant.CheckinTimestamp != nil {
+		utc := participant.CheckinTimestamp.UTC()
+		checkin = &utc
+	}
+	return &dtoRes.ScanRes{
+		ID:                     participant.ID.String(),
+		ParticipantID:          participant.ParticipantID.String(),
+		CheckinTimestamp:       checkin,
+		ScannedTimestamp:       participant.ScannedTimestamp.UTC(),
+		Method:                 string(participant.Method),
+		Comment:                participant.Comment,
+		RevealedParticipantRes: s._ProjectParticipant(projection, identity, time.Now()),
+	}
+}
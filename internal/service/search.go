@@ -0,0 +1,138 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const maxSearchTerms = 8
+
+// Splits a search query into terms on whitespace and punctuation, and also where the
+// script changes between Thai and anything else, so "ค่ายCU อาสา" gives "ค่าย", "CU", "อาสา".
+// Thai itself has no spaces between words, so a Thai run stays a single term and is
+// matched as a substring instead of as a word.
+func (s *service) _SearchTerms(search string) []string {
+	terms := []string{}
+	seen := map[string]bool{}
+
+	var current []rune
+	currentIsThai := false
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		term := string(current)
+		key := strings.ToLower(term)
+		if !seen[key] && len(terms) < maxSearchTerms {
+			seen[key] = true
+			terms = append(terms, term)
+		}
+		current = current[:0]
+	}
+
+	for _, r := range search {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r) {
+			flush()
+			continue
+		}
+		isThai := unicode.Is(unicode.Thai, r)
+		if len(current) > 0 && isThai != currentIsThai {
+			flush()
+		}
+		currentIsThai = isThai
+		current = append(current, r)
+	}
+	flush()
+
+	return terms
+}
+
+// Builds a prefix tsquery over the non-Thai terms, e.g. "volunteer:* | cu:*".
+// Thai terms are left to the substring and trigram matching, since the 'simple'
+// parser keeps a whole Thai run as one lexeme. Returns nil if nothing is left.
+func (s *service) _SearchTSQuery(terms []string) *string {
+	lexemes := []string{}
+	for _, term := range terms {
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.Is(unicode.Thai, r) }) >= 0 {
+			continue
+		}
+		lexemes = append(lexemes, strings.ToLower(term)+":*")
+	}
+	if len(lexemes) == 0 {
+		return nil
+	}
+
+	tsQuery := strings.Join(lexemes, " | ")
+	return &tsQuery
+}
+
+// marks where each search term occurs in the returned fields, as rune offsets
+func (s *service) _AddSearchHighlights(result *[]dtoRes.GetEventsRes, terms []string) {
+	if len(terms) == 0 {
+		return
+	}
+
+	for i := range *result {
+		event := &(*result)[i]
+		fields := map[string]string{
+			"name":      event.Name,
+			"organizer": event.Organizer,
+			"location":  event.Location,
+		}
+		if event.Description != nil {
+			fields["description"] = *event.Description
+		}
+
+		highlights := map[string][][2]int{}
+		for field, text := range fields {
+			if ranges := s._MatchRanges(text, terms); len(ranges) > 0 {
+				highlights[field] = ranges
+			}
+		}
+		if len(highlights) > 0 {
+			event.Highlights = highlights
+		}
+	}
+}
+
+// case-insensitive occurrences of the terms in text as merged [start, end) rune ranges
+func (s *service) _MatchRanges(text string, terms []string) [][2]int {
+	haystack := []rune(strings.ToLower(text))
+	if len(haystack) != len([]rune(text)) {
+		// lowercasing changed the rune count, offsets would not line up
+		return nil
+	}
+
+	ranges := [][2]int{}
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for start := 0; start+len(needle) <= len(haystack); start++ {
+			if string(haystack[start:start+len(needle)]) == string(needle) {
+				ranges = append(ranges, [2]int{start, start + len(needle)})
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(a, b int) bool { return ranges[a][0] < ranges[b][0] })
+	merged := [][2]int{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1] {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
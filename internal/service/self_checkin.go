@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+const (
+	ErrSelfCheckinDisabled = "SELF_CHECKIN_DISABLED"
+	ErrOutsideGeofence     = "OUTSIDE_GEOFENCE"
+)
+
+const (
+	minSelfCheckinRotation = 10
+	maxSelfCheckinRotation = 3600
+	maxSelfCheckinRadius   = 10000
+	earthRadiusMeters      = 6371000.0
+)
+
+type SelfCheckinService interface {
+	UpdateSelfCheckinService(eventIDStr string, userIDStr string, req *dtoReq.UpdateSelfCheckinReq, ctx context.Context) (*dtoRes.SelfCheckinSettingsRes, *response.APIError)
+	GetEventQRCodeService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.EventQRCodeRes, *response.APIError)
+	SelfCheckInService(eventIDStr string, userIDStr string, req *dtoReq.SelfCheckinReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError)
+}
+
+// turns self check-in on or off, enabling it requires a geofence
+func (s *service) UpdateSelfCheckinService(eventIDStr string, userIDStr string, req *dtoReq.UpdateSelfCheckinReq, ctx context.Context) (*dtoRes.SelfCheckinSettingsRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	if (req.Center == nil) != (req.RadiusMeters == nil) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'center' and 'radius_meters' must be given together",
+			Status:  400,
+		}
+	}
+	if req.Enabled && req.Center == nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'center' and 'radius_meters' are required to enable self check-in",
+			Status:  400,
+		}
+	}
+	var center *entity.Point
+	if req.Center != nil {
+		if locationErr := _ValidateLocation(*req.Center, "center"); locationErr != nil {
+			return nil, locationErr
+		}
+		center = &entity.Point{X: req.Center.X, Y: req.Center.Y}
+	}
+	if req.RadiusMeters != nil && (*req.RadiusMeters == 0 || *req.RadiusMeters > maxSelfCheckinRadius) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'radius_meters' must be from 1 to 10000",
+			Status:  400,
+		}
+	}
+	if req.RotationSeconds != nil && (*req.RotationSeconds < minSelfCheckinRotation || *req.RotationSeconds > maxSelfCheckinRotation) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'rotation_seconds' must be from 10 to 3600",
+			Status:  400,
+		}
+	}
+
+	event, eventErr := s.repo.Participant.GetEventById(eventID, ctx)
+	if eventErr != nil {
+		s.logger.Error().Err(eventErr).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	rotation := event.SelfCheckinRotationSeconds
+	if req.RotationSeconds != nil {
+		rotation = *req.RotationSeconds
+	}
+
+	res := &dtoRes.SelfCheckinSettingsRes{
+		Enabled:         req.Enabled,
+		RadiusMeters:    req.RadiusMeters,
+		RotationSeconds: rotation,
+	}
+	if center != nil {
+		res.Center = &dtoRes.ScanLocationRes{X: center.X, Y: center.Y}
+	}
+	before := &dtoRes.SelfCheckinSettingsRes{
+		Enabled:         event.SelfCheckinEnabled,
+		RadiusMeters:    event.SelfCheckinRadiusMeters,
+		RotationSeconds: event.SelfCheckinRotationSeconds,
+	}
+	if event.SelfCheckinCenter != nil {
+		before.Center = &dtoRes.ScanLocationRes{X: event.SelfCheckinCenter.X, Y: event.SelfCheckinCenter.Y}
+	}
+
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.SelfCheckin.UpdateSelfCheckin(eventID, req.Enabled, center, req.RadiusMeters, rotation, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventSelfCheckinUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     before,
+			After:      res,
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "SelfCheckinRepository.UpdateSelfCheckin").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return res, nil
+}
+
+// the code for the organizer's display, which fetches the next one at rotates_at
+func (s *service) GetEventQRCodeService(eventIDStr string, userIDStr string, ctx context.Context) (*dtoRes.EventQRCodeRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER), string(entity.STAFF)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+	event, eventErr := s._GetSelfCheckinEvent(eventID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+
+	rotation := time.Duration(event.SelfCheckinRotationSeconds) * time.Second
+	code, rotatesAt, expiresAt, signErr := s._SignEventQR(eventID, rotation, time.Now())
+	if signErr != nil {
+		s.logger.Error().Err(signErr).Str("event_id", eventID.String()).Msg("failed to sign event QR code")
+		return nil, &response.APIError{
+			Code:    "QR_SIGN_FAIL",
+			Message: "failed to sign QR code",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.EventQRCodeRes{
+		Code:      code,
+		RotatesAt: rotatesAt.UTC(),
+		ExpiresAt: expiresAt.UTC(),
+	}, nil
+}
+
+// The participant checks themselves in by scanning the event QR code. There is
+// no scanner, so the location is the participant's device and must be inside
+// the event's geofence.
+func (s *service) SelfCheckInService(eventIDStr string, userIDStr string, req *dtoReq.SelfCheckinReq, ctx context.Context) (*dtoRes.ScanRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'code' is required",
+			Status:  400,
+		}
+	}
+	if locationErr := _ValidateLocation(req.Location, "location"); locationErr != nil {
+		return nil, locationErr
+	}
+
+	event, eventErr := s._GetSelfCheckinEvent(eventID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+
+	now := time.Now()
+	if windowErr := s._CheckCheckInTime(event, now); windowErr != nil {
+		return nil, windowErr
+	}
+	if err := s._VerifyEventQR(code, eventID, now); err != nil {
+		return nil, &response.APIError{
+			Code:    ErrInvalidSignature,
+			Message: "Event QR code is invalid or expired",
+			Status:  400,
+		}
+	}
+
+	location := entity.Point{X: req.Location.X, Y: req.Location.Y}
+	if event.SelfCheckinCenter == nil || event.SelfCheckinRadiusMeters == nil ||
+		_DistanceMeters(*event.SelfCheckinCenter, location) > float64(*event.SelfCheckinRadiusMeters) {
+		return nil, &response.APIError{
+			Code:    ErrOutsideGeofence,
+			Message: "You must be at the event to check in",
+			Status:  403,
+		}
+	}
+
+	user, userErr := s.repo.Auth.GetUserById(userID, ctx)
+	if errors.Is(userErr, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "User not found",
+			Status:  404,
+		}
+	}
+	if userErr != nil {
+		s.logger.Error().Err(userErr).
+			Str("function", "AuthRepository.GetUserById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	participant, checkinErr := s._CheckIn(event, &user, &entity.EventParticipants{
+		EventID:          event.ID,
+		CheckinTimestamp: &now,
+		ScannedTimestamp: now,
+		ParticipantID:    user.ID,
+		Organization:     s._ParticipantOrganization(user.RefID),
+		ScannedLocation:  &location,
+		Method:           entity.CHECKIN_SELF,
+	}, req.AcceptPrivacyNotice, ctx)
+	if checkinErr != nil {
+		return nil, checkinErr
+	}
+
+	return s._ScanDTOFormat(event, participant, ctx), nil
+}
+
+func (s *service) _GetSelfCheckinEvent(eventID datatypes.UUID, ctx context.Context) (*entity.Event, *response.APIError) {
+	event, eventErr := s._GetEventForCheckIn(eventID, ctx)
+	if eventErr != nil {
+		return nil, eventErr
+	}
+	if !event.SelfCheckinEnabled {
+		return nil, &response.APIError{
+			Code:    ErrSelfCheckinDisabled,
+			Message: "Self check-in is not enabled for this event",
+			Status:  409,
+		}
+	}
+	return event, nil
+}
+
+func _ValidateLocation(location dtoReq.ScanLocation, field string) *response.APIError {
+	if location.X < -180 || location.X > 180 || location.Y < -90 || location.Y > 90 {
+		return &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'" + field + "' must have x as longitude and y as latitude",
+			Status:  400,
+		}
+	}
+	return nil
+}
+
+// great-circle distance between two points with x as longitude and y as latitude
+func _DistanceMeters(a entity.Point, b entity.Point) float64 {
+	lat1, lat2 := a.Y*math.Pi/180, b.Y*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.X - a.X) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
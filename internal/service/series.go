@@ -0,0 +1,818 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+type SeriesService interface {
+	CreateSeriesService(userIDStr string, req *dtoReq.CreateSeriesReq, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError)
+	GetSeriesService(seriesIDStr string, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError)
+	UpdateSeriesService(seriesIDStr string, userIDStr string, req *dtoReq.UpdateSeriesReq, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError)
+	UpdateOccurrenceService(seriesIDStr string, eventIDStr string, userIDStr string, req *dtoReq.UpdateOccurrenceReq, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError)
+	GetSeriesAttendanceService(seriesIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.SeriesAttendanceRes, *response.Pagination, *response.APIError)
+}
+
+func (s *service) CreateSeriesService(userIDStr string, req *dtoReq.CreateSeriesReq, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	series := entity.EventSeries{
+		Name:           strings.TrimSpace(req.Name),
+		Organizer:      strings.TrimSpace(req.Organizer),
+		Description:    req.Description,
+		Location:       strings.TrimSpace(req.Location),
+		RRule:          strings.TrimPrefix(strings.TrimSpace(req.RRule), "RRULE:"),
+		FirstStartTime: req.StartTime,
+		FirstEndTime:   req.EndTime,
+		AllowAllToScan: req.AllowAllToScan,
+		EvaluationForm: req.EvaluationForm,
+		Capacity:       req.Capacity,
+	}
+	if series.Name == "" || series.Organizer == "" || series.Location == "" {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'name', 'organizer' and 'location' are required",
+			Status:  400,
+		}
+	}
+	if req.StartTime.IsZero() || !req.EndTime.After(req.StartTime) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'start_time' is required and 'end_time' must be after it",
+			Status:  400,
+		}
+	}
+	if validateErr := s._SetSeriesAccess(&series, req.AttendenceType, req.RevealedFields); validateErr != nil {
+		return nil, validateErr
+	}
+	if req.Capacity != nil && *req.Capacity == 0 {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'capacity' must be greater than 0",
+			Status:  400,
+		}
+	}
+
+	rule, ruleErr := s._ParseRRule(series.RRule)
+	if ruleErr != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: fmt.Sprintf("Invalid 'rrule': %s", ruleErr.Error()),
+			Status:  400,
+		}
+	}
+	starts, expandErr := s._ExpandRRule(rule, req.StartTime)
+	if expandErr != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: fmt.Sprintf("Invalid 'rrule': %s", expandErr.Error()),
+			Status:  400,
+		}
+	}
+
+	staff, staffErr := s._ParseSeriesStaff(req.Staff, userID)
+	if staffErr != nil {
+		return nil, staffErr
+	}
+	staff = append([]entity.EventSeriesUser{{UserID: userID, Role: entity.OWNER}}, staff...)
+
+	duration := req.EndTime.Sub(req.StartTime)
+	occurrences := make([]entity.Event, len(starts))
+	for i, start := range starts {
+		occurrences[i] = entity.Event{
+			Name:           series.Name,
+			Organizer:      series.Organizer,
+			Description:    series.Description,
+			StartTime:      start,
+			EndTime:        start.Add(duration),
+			Location:       series.Location,
+			AttendenceType: series.AttendenceType,
+			AllowAllToScan: series.AllowAllToScan,
+			EvaluationForm: series.EvaluationForm,
+			RevealedFields: series.RevealedFields,
+			Capacity:       series.Capacity,
+		}
+	}
+
+	whitelist := req.Whitelist
+	if whitelist == nil {
+		whitelist = []uint64{}
+	}
+	faculties, facultiesErr := s._ParseFaculties(req.Faculties)
+	if facultiesErr != nil {
+		return nil, facultiesErr
+	}
+
+	auditFaculties := req.Faculties
+	if auditFaculties == nil {
+		auditFaculties = []int{}
+	}
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Series.CreateSeries(&series, occurrences, staff, whitelist, faculties, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditSeriesCreate,
+			TargetType: auditTargetSeries,
+			TargetID:   series.ID.String(),
+			SeriesID:   &series.ID,
+			After: map[string]any{
+				"series":      _AuditSeries(&series),
+				"members":     _AuditSeriesMembers(staff),
+				"whitelist":   map[string]int{"size": len(whitelist)},
+				"faculties":   auditFaculties,
+				"occurrences": len(occurrences),
+			},
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'whitelist' or 'staff' references a user that does not exist",
+			Status:  400,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "SeriesRepository.CreateSeries").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._SeriesDTOFormat(&series, &occurrences), nil
+}
+
+func (s *service) GetSeriesService(seriesIDStr string, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError) {
+	seriesID, parseErr := s._ParseSeriesID(seriesIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return s._GetSeries(seriesID, ctx)
+}
+
+func (s *service) UpdateSeriesService(seriesIDStr string, userIDStr string, req *dtoReq.UpdateSeriesReq, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError) {
+	seriesID, parseErr := s._ParseSeriesID(seriesIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireSeriesRole(seriesID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	auditMembers := req.Staff != nil
+	auditAccess := req.Whitelist != nil || req.Faculties != nil
+	before, snapshotErr := s._SeriesAuditSnapshot(seriesID, auditMembers, auditAccess, ctx)
+	if snapshotErr != nil {
+		return nil, snapshotErr
+	}
+
+	update := entity.SeriesUpdate{Details: map[string]any{}, Access: map[string]any{}}
+	for column, value := range map[string]*string{"name": req.Name, "organizer": req.Organizer, "location": req.Location} {
+		if value == nil {
+			continue
+		}
+		if strings.TrimSpace(*value) == "" {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: fmt.Sprintf("'%s' must not be empty", column),
+				Status:  400,
+			}
+		}
+		update.Details[column] = strings.TrimSpace(*value)
+	}
+	if req.Description != nil {
+		update.Details["description"] = *req.Description
+	}
+	if req.EvaluationForm != nil {
+		update.Details["evaluation_form"] = *req.EvaluationForm
+	}
+	if req.Capacity != nil {
+		if *req.Capacity == 0 {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'capacity' must be greater than 0",
+				Status:  400,
+			}
+		}
+		update.Details["capacity"] = *req.Capacity
+	}
+
+	if req.AttendenceType != nil || req.RevealedFields != nil {
+		current, getErr := s.repo.Series.GetSeriesById(seriesID, ctx)
+		if getErr != nil {
+			s.logger.Error().Err(getErr).
+				Str("function", "SeriesRepository.GetSeriesById").
+				Msg("Internal DB error")
+			return nil, &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Internal DB error",
+				Status:  500,
+			}
+		}
+
+		attendenceType := string(current.AttendenceType)
+		if req.AttendenceType != nil {
+			attendenceType = *req.AttendenceType
+		}
+		revealed := []string{}
+		for _, field := range current.RevealedFields {
+			revealed = append(revealed, string(field))
+		}
+		if req.RevealedFields != nil {
+			revealed = *req.RevealedFields
+		}
+
+		if validateErr := s._SetSeriesAccess(current, attendenceType, revealed); validateErr != nil {
+			return nil, validateErr
+		}
+		update.Access["attendence_type"] = current.AttendenceType
+		update.Access["revealed_fields"] = current.RevealedFields
+	}
+	if req.AllowAllToScan != nil {
+		update.Access["allow_all_to_scan"] = *req.AllowAllToScan
+	}
+
+	update.Whitelist = req.Whitelist
+	if req.Faculties != nil {
+		faculties, facultiesErr := s._ParseFaculties(*req.Faculties)
+		if facultiesErr != nil {
+			return nil, facultiesErr
+		}
+		update.Faculties = &faculties
+	}
+	if req.Staff != nil {
+		staff, staffErr := s._ParseSeriesStaff(*req.Staff, userID)
+		if staffErr != nil {
+			return nil, staffErr
+		}
+		update.Staff = &staff
+	}
+
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Series.UpdateSeries(seriesID, update, ctx); err != nil {
+			return err
+		}
+
+		// read in the transaction so it sees the update, its errors are already logged
+		var after *seriesAuditSnapshot
+		after, snapshotErr = s._SeriesAuditSnapshot(seriesID, auditMembers, auditAccess, ctx)
+		if snapshotErr != nil {
+			return errAuditFailed
+		}
+		entries := []auditEntry{{
+			Action:     auditSeriesUpdate,
+			TargetType: auditTargetSeries,
+			TargetID:   seriesID.String(),
+			SeriesID:   &seriesID,
+			Before:     _AuditSeries(before.Series),
+			After:      _AuditSeries(after.Series),
+		}}
+		if auditMembers {
+			entries = append(entries, auditEntry{
+				Action:     auditSeriesMembersUpdate,
+				TargetType: auditTargetSeries,
+				TargetID:   seriesID.String(),
+				SeriesID:   &seriesID,
+				Before:     map[string]any{"members": before.Members},
+				After:      map[string]any{"members": after.Members},
+			})
+		}
+		if auditAccess {
+			beforeWhitelist, afterWhitelist := _AuditWhitelist(before.Whitelist, after.Whitelist)
+			entries = append(entries, auditEntry{
+				Action:     auditSeriesAccessUpdate,
+				TargetType: auditTargetSeries,
+				TargetID:   seriesID.String(),
+				SeriesID:   &seriesID,
+				Before:     map[string]any{"whitelist": beforeWhitelist, "faculties": before.Faculties},
+				After:      map[string]any{"whitelist": afterWhitelist, "faculties": after.Faculties},
+			})
+		}
+		for _, entry := range entries {
+			if err := s._Audit(entry, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, ctx)
+	if snapshotErr != nil {
+		return nil, snapshotErr
+	}
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) || errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'whitelist' or 'staff' references a user that does not exist or already owns the series",
+			Status:  400,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("series_id", seriesID.String()).
+			Str("function", "SeriesRepository.UpdateSeries").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._GetSeries(seriesID, ctx)
+}
+
+func (s *service) UpdateOccurrenceService(seriesIDStr string, eventIDStr string, userIDStr string, req *dtoReq.UpdateOccurrenceReq, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError) {
+	seriesID, parseErr := s._ParseSeriesID(seriesIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if uuid.Validate(eventIDStr) != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'eventId'",
+			Status:  400,
+		}
+	}
+	eventID := datatypes.UUID(datatypes.BinUUIDFromString(eventIDStr))
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireSeriesRole(seriesID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	event, eventErr := s.repo.Participant.GetEventById(eventID, ctx)
+	if errors.Is(eventErr, gorm.ErrRecordNotFound) || (eventErr == nil && (event.SeriesID == nil || *event.SeriesID != seriesID)) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Occurrence with this id not found in the series",
+			Status:  404,
+		}
+	}
+	if eventErr != nil {
+		s.logger.Error().Err(eventErr).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	updates := map[string]any{}
+	for column, value := range map[string]*string{"name": req.Name, "location": req.Location} {
+		if value == nil {
+			continue
+		}
+		if strings.TrimSpace(*value) == "" {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: fmt.Sprintf("'%s' must not be empty", column),
+				Status:  400,
+			}
+		}
+		updates[column] = strings.TrimSpace(*value)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.EvaluationForm != nil {
+		updates["evaluation_form"] = *req.EvaluationForm
+	}
+	if req.Capacity != nil {
+		if *req.Capacity == 0 {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'capacity' must be greater than 0",
+				Status:  400,
+			}
+		}
+		updates["capacity"] = *req.Capacity
+	}
+
+	startTime, endTime := event.StartTime, event.EndTime
+	if req.StartTime != nil {
+		startTime = *req.StartTime
+		updates["start_time"] = startTime
+	}
+	if req.EndTime != nil {
+		endTime = *req.EndTime
+		updates["end_time"] = endTime
+	}
+	if !endTime.After(startTime) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'end_time' must be after 'start_time'",
+			Status:  400,
+		}
+	}
+
+	function := "SeriesRepository.UpdateOccurrence"
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Series.UpdateOccurrence(seriesID, eventID, updates, ctx); err != nil {
+			return err
+		}
+		function = "ParticipantRepository.GetEventById"
+		updated, err := s.repo.Participant.GetEventById(eventID, ctx)
+		if err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     _AuditEvent(event),
+			After:      _AuditEvent(updated),
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("series_id", seriesID.String()).
+			Str("function", function).
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._GetSeries(seriesID, ctx)
+}
+
+// how many of the series' occurrences held so far each participant attended
+func (s *service) GetSeriesAttendanceService(seriesIDStr string, userIDStr string, queryParams map[string]string, ctx context.Context) (*[]dtoRes.SeriesAttendanceRes, *response.Pagination, *response.APIError) {
+	seriesID, parseErr := s._ParseSeriesID(seriesIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, nil, parseErr
+	}
+
+	if _, roleErr := s._RequireSeriesRole(seriesID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, nil, roleErr
+	}
+
+	page := 0
+	if pageQuery, ok := queryParams["page"]; ok {
+		pageInt, err := strconv.Atoi(pageQuery)
+		if err != nil || pageInt < 0 {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'page' must be a non-negative int",
+				Status:  400,
+			}
+		}
+		page = pageInt
+	}
+	pageSize := 20
+	if sizeQuery, ok := queryParams["pageSize"]; ok {
+		sizeInt, err := strconv.Atoi(sizeQuery)
+		if err != nil || sizeInt < 1 || sizeInt > 100 {
+			return nil, nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "URL query parameter 'pageSize' must be an int from 1 to 100",
+				Status:  400,
+			}
+		}
+		pageSize = sizeInt
+	}
+
+	series, seriesErr := s.repo.Series.GetSeriesById(seriesID, ctx)
+	if seriesErr != nil {
+		s.logger.Error().Err(seriesErr).
+			Str("function", "SeriesRepository.GetSeriesById").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	attendance, total, held, err := s.repo.Series.GetSeriesAttendance(seriesID, page, pageSize, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("series_id", seriesID.String()).
+			Str("function", "SeriesRepository.GetSeriesAttendance").
+			Msg("Internal DB error")
+		return nil, nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	projection := _RevealedProjection(series.RevealedFields)
+	now := time.Now()
+	res := []dtoRes.SeriesAttendanceRes{}
+	for _, row := range *attendance {
+		res = append(res, dtoRes.SeriesAttendanceRes{
+			UserID: row.UserID.String(),
+			RevealedParticipantRes: s._ProjectParticipant(projection, participantIdentity{
+				UserID:      row.UserID,
+				RefID:       row.RefID,
+				FirstnameTH: row.FirstnameTH,
+				SurnameTH:   row.SurnameTH,
+				FirstnameEN: row.FirstnameEN,
+				SurnameEN:   row.SurnameEN,
+				HasPhoto:    row.HasPhoto,
+			}, now),
+			Attended: row.Attended,
+			Held:     held,
+		})
+	}
+
+	return &res, &response.Pagination{
+		Page:     &page,
+		PageSize: pageSize,
+		Total:    &total,
+		HasNext:  int64((page+1)*pageSize) < total,
+	}, nil
+}
+
+// what an audited series edit may change, the lists are only loaded when asked for
+type seriesAuditSnapshot struct {
+	Series    *entity.EventSeries
+	Members   []auditMember
+	Whitelist []uint64
+	Faculties []int
+}
+
+func (s *service) _SeriesAuditSnapshot(seriesID datatypes.UUID, members bool, access bool, ctx context.Context) (*seriesAuditSnapshot, *response.APIError) {
+	dbErr := func(err error, function string) *response.APIError {
+		s.logger.Error().Err(err).
+			Str("series_id", seriesID.String()).
+			Str("function", function).
+			Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	series, err := s.repo.Series.GetSeriesById(seriesID, ctx)
+	if err != nil {
+		return nil, dbErr(err, "SeriesRepository.GetSeriesById")
+	}
+	snapshot := &seriesAuditSnapshot{Series: series}
+
+	if members {
+		seriesMembers, err := s.repo.Audit.GetSeriesMembers(seriesID, ctx)
+		if err != nil {
+			return nil, dbErr(err, "AuditRepository.GetSeriesMembers")
+		}
+		snapshot.Members = _AuditSeriesMembers(*seriesMembers)
+	}
+	if access {
+		whitelist, faculties, err := s.repo.Audit.GetSeriesAccessLists(seriesID, ctx)
+		if err != nil {
+			return nil, dbErr(err, "AuditRepository.GetSeriesAccessLists")
+		}
+		snapshot.Whitelist, snapshot.Faculties = whitelist, faculties
+	}
+	return snapshot, nil
+}
+
+func (s *service) _ParseSeriesID(seriesIDStr string) (datatypes.UUID, *response.APIError) {
+	if err := uuid.Validate(seriesIDStr); err != nil {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'id'",
+			Status:  400,
+		}
+	}
+	return datatypes.UUID(datatypes.BinUUIDFromString(seriesIDStr)), nil
+}
+
+// returns the user's role in the series, or 403 unless it is one of roles
+func (s *service) _RequireSeriesRole(seriesID datatypes.UUID, userID datatypes.UUID, roles []string, ctx context.Context) (string, *response.APIError) {
+	role, err := s.repo.Series.GetSeriesUserRole(seriesID, userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("series_id", seriesID.String()).
+			Str("function", "SeriesRepository.GetSeriesUserRole").
+			Msg("Internal DB error")
+		return "", &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if role == nil || !slices.Contains(roles, *role) {
+		return "", &response.APIError{
+			Code:    response.ErrForbidden,
+			Message: "You do not have permission to do this for this series",
+			Status:  403,
+		}
+	}
+	return *role, nil
+}
+
+// validates and sets the attendance type and revealed fields of the series
+func (s *service) _SetSeriesAccess(series *entity.EventSeries, attendenceType string, revealedFields []string) *response.APIError {
+	switch attendenceType {
+	case string(entity.ALL):
+		series.AttendenceType = entity.ALL
+	case string(entity.WHITELIST):
+		series.AttendenceType = entity.WHITELIST
+	case string(entity.FACULTIES):
+		series.AttendenceType = entity.FACULTIES
+	default:
+		return &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'attendance_type' must be one of ALL, WHITELIST, FACULTIES",
+			Status:  400,
+		}
+	}
+
+	series.RevealedFields = series.RevealedFields[:0]
+	for _, field := range revealedFields {
+		switch field {
+		case string(entity.NAME):
+			series.RevealedFields = append(series.RevealedFields, entity.NAME)
+		case string(entity.ORGANIZATION):
+			series.RevealedFields = append(series.RevealedFields, entity.ORGANIZATION)
+		case string(entity.REFID):
+			series.RevealedFields = append(series.RevealedFields, entity.REFID)
+		case string(entity.PHOTO):
+			series.RevealedFields = append(series.RevealedFields, entity.PHOTO)
+		default:
+			return &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'revealed_fields' must only contain NAME, ORGANIZATION, REFID, PHOTO",
+				Status:  400,
+			}
+		}
+	}
+	return nil
+}
+
+// faculty numbers are the last two digits of a student ref id
+func (s *service) _ParseFaculties(req []int) ([]uint8, *response.APIError) {
+	faculties := []uint8{}
+	for _, facultyNO := range req {
+		if facultyNO < 1 || facultyNO > 99 {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'faculties' must only contain numbers from 1 to 99",
+				Status:  400,
+			}
+		}
+		if !slices.Contains(faculties, uint8(facultyNO)) {
+			faculties = append(faculties, uint8(facultyNO))
+		}
+	}
+	return faculties, nil
+}
+
+// the series owner is added separately, so staff may only be managers or staff
+func (s *service) _ParseSeriesStaff(req []dtoReq.SeriesStaffReq, ownerID datatypes.UUID) ([]entity.EventSeriesUser, *response.APIError) {
+	staff := []entity.EventSeriesUser{}
+	seen := map[string]bool{ownerID.String(): true}
+
+	for _, member := range req {
+		if uuid.Validate(member.UserID) != nil {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'staff' contains an invalid 'user_id'",
+				Status:  400,
+			}
+		}
+		if seen[member.UserID] {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'staff' contains a user more than once or the owner",
+				Status:  400,
+			}
+		}
+		seen[member.UserID] = true
+
+		row := entity.EventSeriesUser{UserID: datatypes.UUID(datatypes.BinUUIDFromString(member.UserID))}
+		switch member.Role {
+		case string(entity.MANAGER):
+			row.Role = entity.MANAGER
+		case string(entity.STAFF):
+			row.Role = entity.STAFF
+		default:
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'staff' role must be MANAGER or STAFF",
+				Status:  400,
+			}
+		}
+		staff = append(staff, row)
+	}
+
+	return staff, nil
+}
+
+func (s *service) _GetSeries(seriesID datatypes.UUID, ctx context.Context) (*dtoRes.SeriesRes, *response.APIError) {
+	series, err := s.repo.Series.GetSeriesById(seriesID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Series with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "SeriesRepository.GetSeriesById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	occurrences, occurrencesErr := s.repo.Series.GetSeriesOccurrences(seriesID, ctx)
+	if occurrencesErr != nil {
+		s.logger.Error().Err(occurrencesErr).
+			Str("function", "SeriesRepository.GetSeriesOccurrences").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._SeriesDTOFormat(series, occurrences), nil
+}
+
+func (s *service) _SeriesDTOFormat(series *entity.EventSeries, occurrences *[]entity.Event) *dtoRes.SeriesRes {
+	revealed := []string{}
+	for _, field := range series.RevealedFields {
+		revealed = append(revealed, string(field))
+	}
+
+	res := dtoRes.SeriesRes{
+		ID:             series.ID.String(),
+		Name:           series.Name,
+		Organizer:      series.Organizer,
+		Description:    series.Description,
+		Location:       series.Location,
+		RRule:          series.RRule,
+		AttendenceType: string(series.AttendenceType),
+		AllowAllToScan: series.AllowAllToScan,
+		EvaluationForm: series.EvaluationForm,
+		RevealedFields: revealed,
+		Capacity:       series.Capacity,
+		Occurrences:    []dtoRes.SeriesOccurrenceRes{},
+	}
+	for _, occurrence := range *occurrences {
+		res.Occurrences = append(res.Occurrences, dtoRes.SeriesOccurrenceRes{
+			ID:        occurrence.ID.String(),
+			Name:      occurrence.Name,
+			StartTime: occurrence.StartTime.UTC(),
+			EndTime:   occurrence.EndTime.UTC(),
+			Location:  occurrence.Location,
+			Detached:  occurrence.SeriesDetached,
+		})
+	}
+	return &res
+}
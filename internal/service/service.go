@@ -1,33 +1,133 @@
 package service
 
 import (
+	"context"
+	"slices"
+
 	"github.com/cunex-club/quickattend-backend/internal/config"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/notify"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/storage"
 	"github.com/cunex-club/quickattend-backend/internal/repository"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"gorm.io/datatypes"
 )
 
 type service struct {
-	repo   repository.AllRepo
-	cfg    *config.Config
-	logger *zerolog.Logger
+	repo     repository.AllRepo
+	cfg      *config.Config
+	logger   *zerolog.Logger
+	blob     storage.BlobStore
+	notifier *notify.Notifier
 }
 
 type AllOfService struct {
-	HealthCheck HealthCheckService
-	Auth        AuthService
-	Event       EventService
+	HealthCheck  HealthCheckService
+	Auth         AuthService
+	Event        EventService
+	Scan         ScanService
+	Tag          TagService
+	Registration RegistrationService
+	Series       SeriesService
+	Template     TemplateService
+	Calendar     CalendarService
+	Photo        PhotoService
+	Participant  ParticipantService
+	Account      AccountService
+	Consent      ConsentService
+	Retention    RetentionService
+	Audit        AuditService
+	SelfCheckin  SelfCheckinService
+	Fraud        FraudService
+	Analytics    AnalyticsService
+	Organizer    OrganizerService
+	Evaluation   EvaluationService
+	Transcript   TranscriptService
+	Notification NotificationService
+	Announcement AnnouncementService
+	Idempotency  IdempotencyService
 }
 
-func NewService(repo repository.AllRepo, cfg *config.Config, logger *zerolog.Logger) AllOfService {
+func NewService(repo repository.AllRepo, cfg *config.Config, logger *zerolog.Logger, blob storage.BlobStore, notifier *notify.Notifier) AllOfService {
 	srv := &service{
-		repo:   repo,
-		cfg:    cfg,
-		logger: logger,
+		repo:     repo,
+		cfg:      cfg,
+		logger:   logger,
+		blob:     blob,
+		notifier: notifier,
 	}
 
 	return AllOfService{
-		HealthCheck: srv,
-		Auth:        srv,
-		Event:       srv,
+		HealthCheck:  srv,
+		Auth:         srv,
+		Event:        srv,
+		Scan:         srv,
+		Tag:          srv,
+		Registration: srv,
+		Series:       srv,
+		Template:     srv,
+		Calendar:     srv,
+		Photo:        srv,
+		Participant:  srv,
+		Account:      srv,
+		Consent:      srv,
+		Retention:    srv,
+		Audit:        srv,
+		SelfCheckin:  srv,
+		Fraud:        srv,
+		Analytics:    srv,
+		Organizer:    srv,
+		Evaluation:   srv,
+		Transcript:   srv,
+		Notification: srv,
+		Announcement: srv,
+		Idempotency:  srv,
+	}
+}
+
+func (s *service) _ParseEventID(eventIDStr string) (datatypes.UUID, *response.APIError) {
+	if err := uuid.Validate(eventIDStr); err != nil {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'id'",
+			Status:  400,
+		}
+	}
+	return datatypes.UUID(datatypes.BinUUIDFromString(eventIDStr)), nil
+}
+
+func (s *service) _ParseUserID(userIDStr string) (datatypes.UUID, *response.APIError) {
+	if err := uuid.Validate(userIDStr); err != nil {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Invalid user_id from JWT claim",
+			Status:  500,
+		}
+	}
+	return datatypes.UUID(datatypes.BinUUIDFromString(userIDStr)), nil
+}
+
+// returns the user's role in the event, or 403 unless it is one of roles
+func (s *service) _RequireEventRole(eventID datatypes.UUID, userID datatypes.UUID, roles []string, ctx context.Context) (string, *response.APIError) {
+	role, err := s.repo.Participant.GetEventUserRole(eventID, userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventUserRole").
+			Msg("Internal DB error")
+		return "", &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if role == nil || !slices.Contains(roles, *role) {
+		return "", &response.APIError{
+			Code:    response.ErrForbidden,
+			Message: "You do not have permission to do this for this event",
+			Status:  403,
+		}
 	}
+	return *role, nil
 }
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+var tagSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const maxTagsPerEvent = 10
+
+type TagService interface {
+	GetTagsService(ctx context.Context) (*[]dtoRes.TagRes, *response.APIError)
+	CreateTagService(req *dtoReq.TagReq, ctx context.Context) (*dtoRes.TagRes, *response.APIError)
+	UpdateTagService(tagIDStr string, req *dtoReq.TagReq, ctx context.Context) (*dtoRes.TagRes, *response.APIError)
+	DeleteTagService(tagIDStr string, ctx context.Context) *response.APIError
+	SetEventTagsService(eventIDStr string, userIDStr string, userRole string, req *dtoReq.SetEventTagsReq, ctx context.Context) (*[]dtoRes.TagRes, *response.APIError)
+}
+
+func (s *service) GetTagsService(ctx context.Context) (*[]dtoRes.TagRes, *response.APIError) {
+	tags, err := s.repo.Tag.GetTags(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TagRepository.GetTags").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._TagsDTOFormat(tags), nil
+}
+
+func (s *service) CreateTagService(req *dtoReq.TagReq, ctx context.Context) (*dtoRes.TagRes, *response.APIError) {
+	tag, validateErr := s._ValidateTagReq(req)
+	if validateErr != nil {
+		return nil, validateErr
+	}
+
+	var created *entity.Tag
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		var err error
+		if created, err = s.repo.Tag.CreateTag(tag, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditTagCreate,
+			TargetType: auditTargetTag,
+			TargetID:   created.ID.String(),
+			After:      _AuditTag(created),
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Tag with this slug already exists",
+			Status:  409,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TagRepository.CreateTag").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := s._TagDTOFormat(created)
+	return &res, nil
+}
+
+func (s *service) UpdateTagService(tagIDStr string, req *dtoReq.TagReq, ctx context.Context) (*dtoRes.TagRes, *response.APIError) {
+	if uuid.Validate(tagIDStr) != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'id'",
+			Status:  400,
+		}
+	}
+	tagID := datatypes.UUID(datatypes.BinUUIDFromString(tagIDStr))
+
+	tag, validateErr := s._ValidateTagReq(req)
+	if validateErr != nil {
+		return nil, validateErr
+	}
+	tag.ID = tagID
+
+	before, getErr := s.repo.Tag.GetTagById(tagID, ctx)
+	if getErr != nil {
+		if errors.Is(getErr, gorm.ErrRecordNotFound) {
+			return nil, &response.APIError{
+				Code:    response.ErrNotFound,
+				Message: "Tag with this id not found",
+				Status:  404,
+			}
+		}
+		s.logger.Error().Err(getErr).Str("function", "TagRepository.GetTagById").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Tag.UpdateTag(tag, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditTagUpdate,
+			TargetType: auditTargetTag,
+			TargetID:   tagID.String(),
+			Before:     _AuditTag(before),
+			After:      _AuditTag(tag),
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Tag with this slug already exists",
+			Status:  409,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TagRepository.UpdateTag").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := s._TagDTOFormat(tag)
+	return &res, nil
+}
+
+func (s *service) DeleteTagService(tagIDStr string, ctx context.Context) *response.APIError {
+	if uuid.Validate(tagIDStr) != nil {
+		return &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'id'",
+			Status:  400,
+		}
+	}
+	tagID := datatypes.UUID(datatypes.BinUUIDFromString(tagIDStr))
+
+	before, getErr := s.repo.Tag.GetTagById(tagID, ctx)
+	if errors.Is(getErr, gorm.ErrRecordNotFound) {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Tag with this id not found",
+			Status:  404,
+		}
+	}
+	if getErr != nil {
+		s.logger.Error().Err(getErr).Str("function", "TagRepository.GetTagById").Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		deleted, err := s.repo.Tag.DeleteTag(tagID, ctx)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return s._Audit(auditEntry{
+			Action:     auditTagDelete,
+			TargetType: auditTargetTag,
+			TargetID:   tagID.String(),
+			Before:     _AuditTag(before),
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Tag with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TagRepository.DeleteTag").Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return nil
+}
+
+// admins can tag any event, otherwise only the event's OWNER or MANAGER
+func (s *service) SetEventTagsService(eventIDStr string, userIDStr string, userRole string, req *dtoReq.SetEventTagsReq, ctx context.Context) (*[]dtoRes.TagRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, eventErr := s.repo.Participant.GetEventById(eventID, ctx); eventErr != nil {
+		if errors.Is(eventErr, gorm.ErrRecordNotFound) {
+			return nil, &response.APIError{
+				Code:    response.ErrNotFound,
+				Message: "Event with this id not found",
+				Status:  404,
+			}
+		}
+		s.logger.Error().Err(eventErr).Str("function", "ParticipantRepository.GetEventById").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	if userRole != entity.ADMIN {
+		if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+			return nil, roleErr
+		}
+	}
+
+	slugs := []string{}
+	seen := map[string]bool{}
+	for _, slug := range req.Tags {
+		slug = strings.ToLower(strings.TrimSpace(slug))
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) > maxTagsPerEvent {
+		return nil, &response.APIError{
+			Code:    response.ErrValidation,
+			Message: fmt.Sprintf("An event can have at most %d tags", maxTagsPerEvent),
+			Status:  400,
+		}
+	}
+
+	current, currentErr := s.repo.Tag.GetEventTags(eventID, ctx)
+	if currentErr != nil {
+		s.logger.Error().Err(currentErr).Str("function", "TagRepository.GetEventTags").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	tags := &[]entity.Tag{}
+	if len(slugs) > 0 {
+		found, getErr := s.repo.Tag.GetTagsBySlugs(slugs, ctx)
+		if getErr != nil {
+			s.logger.Error().Err(getErr).Str("function", "TagRepository.GetTagsBySlugs").Msg("Internal DB error")
+			return nil, &response.APIError{
+				Code:    response.ErrInternalError,
+				Message: "Internal DB error",
+				Status:  500,
+			}
+		}
+		if len(*found) != len(slugs) {
+			return nil, &response.APIError{
+				Code:    response.ErrValidation,
+				Message: "Some tags do not exist",
+				Status:  400,
+			}
+		}
+		tags = found
+	}
+
+	tagIDs := make([]datatypes.UUID, len(*tags))
+	for i, tag := range *tags {
+		tagIDs[i] = tag.ID
+	}
+	tagSlugs := func(tags *[]entity.Tag) map[string][]string {
+		slugs := []string{}
+		for _, tag := range *tags {
+			slugs = append(slugs, tag.Slug)
+		}
+		slices.Sort(slugs)
+		return map[string][]string{"tags": slugs}
+	}
+	setErr := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Tag.SetEventTags(eventID, tagIDs, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventTagsUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     tagSlugs(current),
+			After:      tagSlugs(tags),
+		}, ctx)
+	}, ctx)
+	if errors.Is(setErr, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if setErr != nil {
+		s.logger.Error().Err(setErr).Str("function", "TagRepository.SetEventTags").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._TagsDTOFormat(tags), nil
+}
+
+func (s *service) _ValidateTagReq(req *dtoReq.TagReq) (*entity.Tag, *response.APIError) {
+	tag := entity.Tag{
+		Slug:   strings.ToLower(strings.TrimSpace(req.Slug)),
+		NameTH: strings.TrimSpace(req.NameTH),
+		NameEN: strings.TrimSpace(req.NameEN),
+	}
+
+	if len(tag.Slug) > 64 || !tagSlugPattern.MatchString(tag.Slug) {
+		return nil, &response.APIError{
+			Code:    response.ErrValidation,
+			Message: "'slug' must be lowercase letters, digits and dashes, at most 64 characters",
+			Status:  400,
+		}
+	}
+	if tag.NameTH == "" || tag.NameEN == "" || utf8.RuneCountInString(tag.NameTH) > 64 || utf8.RuneCountInString(tag.NameEN) > 64 {
+		return nil, &response.APIError{
+			Code:    response.ErrValidation,
+			Message: "'name_th' and 'name_en' are required and must not exceed 64 characters",
+			Status:  400,
+		}
+	}
+
+	return &tag, nil
+}
+
+func (s *service) _TagDTOFormat(tag *entity.Tag) dtoRes.TagRes {
+	return dtoRes.TagRes{
+		ID:     tag.ID.String(),
+		Slug:   tag.Slug,
+		NameTH: tag.NameTH,
+		NameEN: tag.NameEN,
+	}
+}
+
+func (s *service) _TagsDTOFormat(tags *[]entity.Tag) *[]dtoRes.TagRes {
+	result := []dtoRes.TagRes{}
+	for i := range *tags {
+		result = append(result, s._TagDTOFormat(&(*tags)[i]))
+	}
+	return &result
+}
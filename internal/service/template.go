@@ -0,0 +1,507 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+type TemplateService interface {
+	DuplicateEventService(eventIDStr string, userIDStr string, req *dtoReq.DuplicateEventReq, ctx context.Context) (*dtoRes.GetOneEventRes, *response.APIError)
+	GetTemplatesService(userIDStr string, ctx context.Context) (*[]dtoRes.TemplateRes, *response.APIError)
+	GetTemplateService(templateIDStr string, userIDStr string, ctx context.Context) (*dtoRes.TemplateRes, *response.APIError)
+	CreateTemplateService(userIDStr string, req *dtoReq.CreateTemplateReq, ctx context.Context) (*dtoRes.TemplateRes, *response.APIError)
+	DeleteTemplateService(templateIDStr string, userIDStr string, ctx context.Context) *response.APIError
+	CreateEventFromTemplateService(templateIDStr string, userIDStr string, req *dtoReq.CreateEventFromTemplateReq, ctx context.Context) (*dtoRes.GetOneEventRes, *response.APIError)
+}
+
+func (s *service) DuplicateEventService(eventIDStr string, userIDStr string, req *dtoReq.DuplicateEventReq, ctx context.Context) (*dtoRes.GetOneEventRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	data, snapshotErr := s._SnapshotEvent(eventID, req.Copy, ctx)
+	if snapshotErr != nil {
+		return nil, snapshotErr
+	}
+
+	newEventID, createErr := s._CreateEventFromSnapshot(data, req.StartTime, req.Name, userID, ctx)
+	if createErr != nil {
+		return nil, createErr
+	}
+
+	return s.GetOneEventService(newEventID.String(), userIDStr, ctx)
+}
+
+func (s *service) GetTemplatesService(userIDStr string, ctx context.Context) (*[]dtoRes.TemplateRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	templates, err := s.repo.Template.GetTemplates(userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TemplateRepository.GetTemplates").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := []dtoRes.TemplateRes{}
+	for i := range *templates {
+		res = append(res, *s._TemplateDTOFormat(&(*templates)[i]))
+	}
+	return &res, nil
+}
+
+func (s *service) GetTemplateService(templateIDStr string, userIDStr string, ctx context.Context) (*dtoRes.TemplateRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	template, getErr := s._GetTemplate(templateIDStr, userID, ctx)
+	if getErr != nil {
+		return nil, getErr
+	}
+	return s._TemplateDTOFormat(template), nil
+}
+
+// saves a template from one of the user's managed events
+func (s *service) CreateTemplateService(userIDStr string, req *dtoReq.CreateTemplateReq, ctx context.Context) (*dtoRes.TemplateRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'name' is required and must not exceed 100 characters",
+			Status:  400,
+		}
+	}
+	if uuid.Validate(req.EventID) != nil {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'event_id' must be a valid UUID",
+			Status:  400,
+		}
+	}
+	eventID := datatypes.UUID(datatypes.BinUUIDFromString(req.EventID))
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	data, snapshotErr := s._SnapshotEvent(eventID, req.Copy, ctx)
+	if snapshotErr != nil {
+		return nil, snapshotErr
+	}
+
+	now := time.Now()
+	created, err := s.repo.Template.CreateTemplate(&entity.EventTemplate{
+		OwnerID:   userID,
+		Name:      name,
+		Data:      datatypes.NewJSONType(*data),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TemplateRepository.CreateTemplate").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return s._TemplateDTOFormat(created), nil
+}
+
+func (s *service) DeleteTemplateService(templateIDStr string, userIDStr string, ctx context.Context) *response.APIError {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+	templateID, parseErr := s._ParseTemplateID(templateIDStr)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	deleted, err := s.repo.Template.DeleteTemplate(templateID, userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TemplateRepository.DeleteTemplate").Msg("Internal DB error")
+		return &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if deleted == 0 {
+		return &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Template with this id not found",
+			Status:  404,
+		}
+	}
+	return nil
+}
+
+// the user becomes the owner of the new event
+func (s *service) CreateEventFromTemplateService(templateIDStr string, userIDStr string, req *dtoReq.CreateEventFromTemplateReq, ctx context.Context) (*dtoRes.GetOneEventRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	template, getErr := s._GetTemplate(templateIDStr, userID, ctx)
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	data := template.Data.Data()
+	newEventID, createErr := s._CreateEventFromSnapshot(&data, req.StartTime, req.Name, userID, ctx)
+	if createErr != nil {
+		return nil, createErr
+	}
+
+	return s.GetOneEventService(newEventID.String(), userIDStr, ctx)
+}
+
+// copies an event into template data, leaving out what the options turn off
+func (s *service) _SnapshotEvent(eventID datatypes.UUID, options dtoReq.CopyOptionsReq, ctx context.Context) (*entity.EventTemplateData, *response.APIError) {
+	source, err := s.repo.Template.GetEventCopySource(eventID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Event with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "TemplateRepository.GetEventCopySource").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	event := source.Event
+	data := entity.EventTemplateData{
+		Name:             event.Name,
+		Organizer:        event.Organizer,
+		Description:      event.Description,
+		Location:         event.Location,
+		DurationSec:      int64(event.EndTime.Sub(event.StartTime) / time.Second),
+		AttendenceType:   string(event.AttendenceType),
+		AllowAllToScan:   event.AllowAllToScan,
+		EvaluationForm:   event.EvaluationForm,
+		RevealedFields:   []string{},
+		Capacity:         event.Capacity,
+		PrivacyNotice:    event.PrivacyNotice,
+		RetentionDays:    event.RetentionDays,
+		ActivityHours:    event.ActivityHours,
+		ActivityCategory: event.ActivityCategory,
+		Agenda:           []entity.EventTemplateAgenda{},
+		Whitelist:        []uint64{},
+		Faculties:        []int{},
+		Staff:            []entity.EventTemplateStaff{},
+		TagIDs:           []string{},
+	}
+	if event.OrganizerID != nil {
+		organizerID := event.OrganizerID.String()
+		data.OrganizerID = &organizerID
+	}
+	for _, field := range event.RevealedFields {
+		data.RevealedFields = append(data.RevealedFields, string(field))
+	}
+
+	copyAll := func(option *bool) bool { return option == nil || *option }
+	if copyAll(options.Agenda) {
+		for _, slot := range source.Agenda {
+			data.Agenda = append(data.Agenda, entity.EventTemplateAgenda{
+				ActivityName:   slot.ActivityName,
+				StartOffsetSec: int64(slot.StartTime.Sub(event.StartTime) / time.Second),
+				EndOffsetSec:   int64(slot.EndTime.Sub(event.StartTime) / time.Second),
+			})
+		}
+	}
+	if copyAll(options.Whitelist) {
+		data.Whitelist = append(data.Whitelist, source.Whitelist...)
+	}
+	if copyAll(options.Faculties) {
+		for _, facultyNO := range source.Faculties {
+			data.Faculties = append(data.Faculties, int(facultyNO))
+		}
+	}
+	if copyAll(options.Staff) {
+		for _, member := range source.Staff {
+			data.Staff = append(data.Staff, entity.EventTemplateStaff{UserID: member.UserID.String(), Role: string(member.Role)})
+		}
+	}
+	if copyAll(options.Tags) {
+		for _, tagID := range source.TagIDs {
+			data.TagIDs = append(data.TagIDs, tagID.String())
+		}
+	}
+
+	return &data, nil
+}
+
+// Creates an event from template data starting at startTime, the agenda keeps its
+// offsets from the start. ownerID is the only OWNER: copied owners become MANAGER,
+// so nobody is made owner of an event they did not create, and other copied
+// staff keep their roles. The organizer link is kept only while ownerID may
+// still link events to that organizer, otherwise just its name is copied.
+func (s *service) _CreateEventFromSnapshot(data *entity.EventTemplateData, startTime time.Time, name *string, ownerID datatypes.UUID, ctx context.Context) (datatypes.UUID, *response.APIError) {
+	if startTime.IsZero() {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'start_time' is required",
+			Status:  400,
+		}
+	}
+
+	event := entity.Event{
+		Name:             data.Name,
+		Organizer:        data.Organizer,
+		Description:      data.Description,
+		StartTime:        startTime,
+		EndTime:          startTime.Add(time.Duration(data.DurationSec) * time.Second),
+		Location:         data.Location,
+		AllowAllToScan:   data.AllowAllToScan,
+		EvaluationForm:   data.EvaluationForm,
+		Capacity:         data.Capacity,
+		PrivacyNotice:    data.PrivacyNotice,
+		RetentionDays:    data.RetentionDays,
+		ActivityHours:    data.ActivityHours,
+		ActivityCategory: data.ActivityCategory,
+	}
+	if name != nil {
+		event.Name = strings.TrimSpace(*name)
+		if event.Name == "" {
+			return datatypes.UUID{}, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: "'name' must not be empty",
+				Status:  400,
+			}
+		}
+	}
+
+	if data.OrganizerID != nil && uuid.Validate(*data.OrganizerID) == nil {
+		organizer, organizerErr := s._TemplateOrganizer(datatypes.UUID(datatypes.BinUUIDFromString(*data.OrganizerID)), ownerID, ctx)
+		if organizerErr != nil {
+			return datatypes.UUID{}, organizerErr
+		}
+		if organizer != nil {
+			event.OrganizerID = &organizer.ID
+			event.Organizer = organizer.Name
+		}
+	}
+
+	// the access settings are validated the same way as for a series
+	access := entity.EventSeries{}
+	if accessErr := s._SetSeriesAccess(&access, data.AttendenceType, data.RevealedFields); accessErr != nil {
+		return datatypes.UUID{}, accessErr
+	}
+	event.AttendenceType = access.AttendenceType
+	event.RevealedFields = access.RevealedFields
+
+	agenda := []entity.EventAgenda{}
+	for _, slot := range data.Agenda {
+		agenda = append(agenda, entity.EventAgenda{
+			ActivityName: slot.ActivityName,
+			StartTime:    startTime.Add(time.Duration(slot.StartOffsetSec) * time.Second),
+			EndTime:      startTime.Add(time.Duration(slot.EndOffsetSec) * time.Second),
+		})
+	}
+
+	staff := []entity.EventUser{{UserID: ownerID, Role: entity.OWNER}}
+	for _, member := range data.Staff {
+		if uuid.Validate(member.UserID) != nil || member.UserID == ownerID.String() {
+			continue
+		}
+		row := entity.EventUser{UserID: datatypes.UUID(datatypes.BinUUIDFromString(member.UserID))}
+		switch member.Role {
+		case string(entity.OWNER), string(entity.MANAGER):
+			row.Role = entity.MANAGER
+		default:
+			row.Role = entity.STAFF
+		}
+		staff = append(staff, row)
+	}
+
+	faculties := []uint8{}
+	for _, facultyNO := range data.Faculties {
+		faculties = append(faculties, uint8(facultyNO))
+	}
+
+	tagIDs := []datatypes.UUID{}
+	for _, tagID := range data.TagIDs {
+		if uuid.Validate(tagID) == nil {
+			tagIDs = append(tagIDs, datatypes.UUID(datatypes.BinUUIDFromString(tagID)))
+		}
+	}
+
+	members := []auditMember{}
+	for _, member := range staff {
+		members = append(members, auditMember{UserID: member.UserID.String(), Role: string(member.Role)})
+	}
+	err := s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Template.CreateEventCopy(&event, agenda, staff, data.Whitelist, faculties, tagIDs, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventCreate,
+			TargetType: auditTargetEvent,
+			TargetID:   event.ID.String(),
+			EventID:    &event.ID,
+			After: map[string]any{
+				"event":     _AuditEvent(&event),
+				"members":   members,
+				"whitelist": map[string]int{"size": len(data.Whitelist)},
+				"faculties": data.Faculties,
+				"tag_ids":   data.TagIDs,
+			},
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return datatypes.UUID{}, _AuditFailedError()
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrConflict,
+			Message: "Some copied users or tags no longer exist",
+			Status:  409,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "TemplateRepository.CreateEventCopy").
+			Msg("Internal DB error")
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return event.ID, nil
+}
+
+func (s *service) _ParseTemplateID(templateIDStr string) (datatypes.UUID, *response.APIError) {
+	if err := uuid.Validate(templateIDStr); err != nil {
+		return datatypes.UUID{}, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "Invalid URL path parameter 'id'",
+			Status:  400,
+		}
+	}
+	return datatypes.UUID(datatypes.BinUUIDFromString(templateIDStr)), nil
+}
+
+func (s *service) _GetTemplate(templateIDStr string, userID datatypes.UUID, ctx context.Context) (*entity.EventTemplate, *response.APIError) {
+	templateID, parseErr := s._ParseTemplateID(templateIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	template, err := s.repo.Template.GetTemplateById(templateID, userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "Template with this id not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("function", "TemplateRepository.GetTemplateById").Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return template, nil
+}
+
+func (s *service) _TemplateDTOFormat(template *entity.EventTemplate) *dtoRes.TemplateRes {
+	data := template.Data.Data()
+	return &dtoRes.TemplateRes{
+		ID:              template.ID.String(),
+		Name:            template.Name,
+		EventName:       data.Name,
+		Organizer:       data.Organizer,
+		Location:        data.Location,
+		DurationMinutes: data.DurationSec / 60,
+		AgendaCount:     len(data.Agenda),
+		WhitelistCount:  len(data.Whitelist),
+		FacultyCount:    len(data.Faculties),
+		StaffCount:      len(data.Staff),
+		TagCount:        len(data.TagIDs),
+		CreatedAt:       template.CreatedAt.UTC(),
+		UpdatedAt:       template.UpdatedAt.UTC(),
+	}
+}
+
+// the organizer an event copied by userID stays linked to, nil when userID is no
+// longer an owner or manager of it, the roles SetEventOrganizer requires
+func (s *service) _TemplateOrganizer(organizerID datatypes.UUID, userID datatypes.UUID, ctx context.Context) (*entity.Organizer, *response.APIError) {
+	role, err := s.repo.Organizer.GetOrganizerUserRole(organizerID, userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerUserRole").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	if role == nil || (*role != string(entity.OWNER) && *role != string(entity.MANAGER)) {
+		return nil, nil
+	}
+
+	organizer, err := s.repo.Organizer.GetOrganizerById(organizerID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("organizer_id", organizerID.String()).
+			Str("function", "OrganizerRepository.GetOrganizerById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+	return organizer, nil
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cunex-club/quickattend-backend/internal/entity"
+	"github.com/cunex-club/quickattend-backend/internal/infrastructure/http/response"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dtoReq "github.com/cunex-club/quickattend-backend/internal/dto/request"
+	dtoRes "github.com/cunex-club/quickattend-backend/internal/dto/response"
+)
+
+// the most hours numeric(5,2) can hold
+const maxActivityHours = 999.99
+
+// categories in the order transcripts list them
+var activityCategories = []string{
+	string(entity.ACTIVITY_ACADEMIC),
+	string(entity.ACTIVITY_VOLUNTEER),
+	string(entity.ACTIVITY_SPORTS),
+	string(entity.ACTIVITY_ARTS_CULTURE),
+	string(entity.ACTIVITY_LEADERSHIP),
+	string(entity.ACTIVITY_OTHER),
+}
+
+type TranscriptService interface {
+	UpdateEventActivityService(eventIDStr string, userIDStr string, req *dtoReq.UpdateEventActivityReq, ctx context.Context) (*dtoRes.EventActivityRes, *response.APIError)
+	GetMyTranscriptService(userIDStr string, ctx context.Context) (*dtoRes.TranscriptRes, *response.APIError)
+	ExportMyTranscriptService(userIDStr string, ctx context.Context) (*dtoRes.TranscriptExportRes, *response.APIError)
+	VerifyTranscriptService(req *dtoReq.VerifyTranscriptReq, ctx context.Context) (*dtoRes.TranscriptRes, *response.APIError)
+}
+
+// sets the hours every confirmed attendee is credited with, null for both removes them
+func (s *service) UpdateEventActivityService(eventIDStr string, userIDStr string, req *dtoReq.UpdateEventActivityReq, ctx context.Context) (*dtoRes.EventActivityRes, *response.APIError) {
+	eventID, parseErr := s._ParseEventID(eventIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if _, roleErr := s._RequireEventRole(eventID, userID, []string{string(entity.OWNER), string(entity.MANAGER)}, ctx); roleErr != nil {
+		return nil, roleErr
+	}
+
+	if (req.ActivityHours == nil) != (req.ActivityCategory == nil) {
+		return nil, &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'activity_hours' and 'activity_category' must be set or removed together",
+			Status:  400,
+		}
+	}
+	var hours *float64
+	if req.ActivityHours != nil {
+		rounded := math.Round(*req.ActivityHours*100) / 100
+		if rounded <= 0 || rounded > maxActivityHours {
+			return nil, &response.APIError{
+				Code:    response.ErrBadRequest,
+				Message: fmt.Sprintf("'activity_hours' must be greater than 0 and at most %.2f", maxActivityHours),
+				Status:  400,
+			}
+		}
+		hours = &rounded
+	}
+	var category *string
+	if req.ActivityCategory != nil {
+		parsed, categoryErr := _ParseActivityCategory(*req.ActivityCategory)
+		if categoryErr != nil {
+			return nil, categoryErr
+		}
+		category = &parsed
+	}
+
+	event, err := s.repo.Participant.GetEventById(eventID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "ParticipantRepository.GetEventById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	err = s.repo.Transaction.WithinTransaction(func(ctx context.Context) error {
+		if err := s.repo.Event.UpdateEventActivity(eventID, hours, category, ctx); err != nil {
+			return err
+		}
+		return s._Audit(auditEntry{
+			Action:     auditEventActivityUpdate,
+			TargetType: auditTargetEvent,
+			TargetID:   eventID.String(),
+			EventID:    &eventID,
+			Before:     map[string]any{"activity_hours": event.ActivityHours, "activity_category": event.ActivityCategory},
+			After:      map[string]any{"activity_hours": hours, "activity_category": category},
+		}, ctx)
+	}, ctx)
+	if errors.Is(err, errAuditFailed) {
+		return nil, _AuditFailedError()
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID.String()).
+			Str("function", "EventRepository.UpdateEventActivity").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.EventActivityRes{
+		EventID:          eventID.String(),
+		ActivityHours:    hours,
+		ActivityCategory: category,
+	}, nil
+}
+
+func (s *service) GetMyTranscriptService(userIDStr string, ctx context.Context) (*dtoRes.TranscriptRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return s._BuildTranscript(userID, time.Now(), ctx)
+}
+
+// the transcript together with a signature the faculty can verify
+func (s *service) ExportMyTranscriptService(userIDStr string, ctx context.Context) (*dtoRes.TranscriptExportRes, *response.APIError) {
+	userID, parseErr := s._ParseUserID(userIDStr)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	transcript, transcriptErr := s._BuildTranscript(userID, time.Now(), ctx)
+	if transcriptErr != nil {
+		return nil, transcriptErr
+	}
+
+	signature, expiresAt, err := s._SignTranscript(transcript)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to sign transcript")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Failed to sign transcript",
+			Status:  500,
+		}
+	}
+
+	return &dtoRes.TranscriptExportRes{
+		Transcript: *transcript,
+		Signature:  signature,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// returns the transcript as it was when signed, to compare with the submitted copy
+func (s *service) VerifyTranscriptService(req *dtoReq.VerifyTranscriptReq, ctx context.Context) (*dtoRes.TranscriptRes, *response.APIError) {
+	transcript, err := s._VerifyTranscript(strings.TrimSpace(req.Signature), time.Now())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, &response.APIError{
+			Code:    ErrInvalidSignature,
+			Message: "Transcript signature has expired, the student has to export the transcript again",
+			Status:  400,
+		}
+	}
+	if err != nil {
+		return nil, &response.APIError{
+			Code:    ErrInvalidSignature,
+			Message: "Transcript signature is invalid",
+			Status:  400,
+		}
+	}
+	return transcript, nil
+}
+
+func (s *service) _BuildTranscript(userID datatypes.UUID, now time.Time, ctx context.Context) (*dtoRes.TranscriptRes, *response.APIError) {
+	user, err := s.repo.Auth.GetUserById(userID, ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &response.APIError{
+			Code:    response.ErrNotFound,
+			Message: "User not found",
+			Status:  404,
+		}
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "AuthRepository.GetUserById").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	entries, err := s.repo.Event.GetTranscript(userID, ctx)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("function", "EventRepository.GetTranscript").
+			Msg("Internal DB error")
+		return nil, &response.APIError{
+			Code:    response.ErrInternalError,
+			Message: "Internal DB error",
+			Status:  500,
+		}
+	}
+
+	res := dtoRes.TranscriptRes{
+		UserID:      userID.String(),
+		RefID:       s.FormatRefIdToStr(user.RefID),
+		TitleTH:     user.TitleTH,
+		FirstnameTH: user.FirstnameTH,
+		SurnameTH:   user.SurnameTH,
+		TitleEN:     user.TitleEN,
+		FirstnameEN: user.FirstnameEN,
+		SurnameEN:   user.SurnameEN,
+		Categories:  []dtoRes.TranscriptCategoryRes{},
+		Events:      []dtoRes.TranscriptEventRes{},
+		GeneratedAt: now.UTC().Truncate(time.Second),
+	}
+
+	byCategory := map[string]*dtoRes.TranscriptCategoryRes{}
+	for _, entry := range entries {
+		res.Events = append(res.Events, dtoRes.TranscriptEventRes{
+			EventID:          entry.EventID.String(),
+			EventName:        entry.EventName,
+			Organizer:        entry.Organizer,
+			StartTime:        entry.StartTime.UTC(),
+			EndTime:          entry.EndTime.UTC(),
+			CheckinTimestamp: entry.CheckinTimestamp.UTC(),
+			ActivityHours:    entry.ActivityHours,
+			ActivityCategory: entry.ActivityCategory,
+		})
+		res.TotalHours += entry.ActivityHours
+
+		category, ok := byCategory[entry.ActivityCategory]
+		if !ok {
+			category = &dtoRes.TranscriptCategoryRes{Category: entry.ActivityCategory}
+			byCategory[entry.ActivityCategory] = category
+		}
+		category.Hours += entry.ActivityHours
+		category.Events++
+	}
+
+	// sums of two decimal hours, rounded to undo float drift
+	res.TotalHours = math.Round(res.TotalHours*100) / 100
+	for _, name := range activityCategories {
+		if category, ok := byCategory[name]; ok {
+			category.Hours = math.Round(category.Hours*100) / 100
+			res.Categories = append(res.Categories, *category)
+		}
+	}
+	return &res, nil
+}
+
+func _ParseActivityCategory(value string) (string, *response.APIError) {
+	switch value {
+	case string(entity.ACTIVITY_ACADEMIC), string(entity.ACTIVITY_VOLUNTEER), string(entity.ACTIVITY_SPORTS),
+		string(entity.ACTIVITY_ARTS_CULTURE), string(entity.ACTIVITY_LEADERSHIP), string(entity.ACTIVITY_OTHER):
+		return value, nil
+	default:
+		return "", &response.APIError{
+			Code:    response.ErrBadRequest,
+			Message: "'activity_category' must be one of " + strings.Join(activityCategories, ", "),
+			Status:  400,
+		}
+	}
+}
+
+// Transcript signatures are HS256 tokens that carry the whole transcript, so
+// verifying one shows exactly what was issued. They expire after
+// TRANSCRIPT_SIGNATURE_TTL, which bounds how long a snapshot outlives later
+// corrections to the hours it lists.
+const transcriptType = "transcript"
+
+type transcriptClaims struct {
+	Type       string               `json:"typ"`
+	Transcript dtoRes.TranscriptRes `json:"transcript"`
+	jwt.RegisteredClaims
+}
+
+func (s *service) _SignTranscript(transcript *dtoRes.TranscriptRes) (string, time.Time, error) {
+	if s.cfg.TranscriptConfig.SigningKey == "" {
+		return "", time.Time{}, errors.New("transcript signing key not configured")
+	}
+
+	expiresAt := transcript.GeneratedAt.Add(s.cfg.TranscriptConfig.SignatureTTL)
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, transcriptClaims{
+		Type:       transcriptType,
+		Transcript: *transcript,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   transcript.UserID,
+			IssuedAt:  jwt.NewNumericDate(transcript.GeneratedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	signature, err := t.SignedString([]byte(s.cfg.TranscriptConfig.SigningKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signature, expiresAt, nil
+}
+
+func (s *service) _VerifyTranscript(signature string, now time.Time) (*dtoRes.TranscriptRes, error) {
+	claims := transcriptClaims{}
+	token, err := jwt.ParseWithClaims(
+		signature,
+		&claims,
+		func(t *jwt.Token) (any, error) {
+			return []byte(s.cfg.TranscriptConfig.SigningKey), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || token == nil || !token.Valid {
+		return nil, fmt.Errorf("invalid transcript signature: %w", err)
+	}
+
+	if claims.Type != transcriptType {
+		return nil, errors.New("token is not a transcript signature")
+	}
+	if claims.Subject != claims.Transcript.UserID {
+		return nil, errors.New("transcript signature subject does not match")
+	}
+	return &claims.Transcript, nil
+}
@@ -3,6 +3,15 @@ CREATE EXTENSION IF NOT EXISTS pg_trgm;
 CREATE TYPE attendence_type AS ENUM ('WHITELIST', 'FACULTIES', 'ALL');
 CREATE TYPE participant_data AS ENUM ('NAME', 'ORGANIZATION', 'REFID', 'PHOTO');
 CREATE TYPE role AS ENUM ('OWNER', 'STAFF', 'MANAGER');
+CREATE TYPE registration_status AS ENUM ('REGISTERED', 'WAITLISTED', 'CANCELLED');
+CREATE TYPE consent_source AS ENUM ('REGISTRATION', 'SCAN', 'SELF');
+CREATE TYPE checkin_method AS ENUM ('SCAN', 'MANUAL', 'SELF');
+CREATE TYPE fraud_rule AS ENUM ('RAPID_SCANS', 'REMOTE_LOCATION', 'DISTANT_SCANS', 'LATE_CHECKIN');
+CREATE TYPE activity_category AS ENUM ('ACADEMIC', 'VOLUNTEER', 'SPORTS', 'ARTS_CULTURE', 'LEADERSHIP', 'OTHER');
+CREATE TYPE notification_kind AS ENUM ('EVENT_REMINDER_24H', 'EVENT_REMINDER_1H', 'EVALUATION_OPEN', 'STAFF_ASSIGNED', 'ANNOUNCEMENT', 'WAITLIST_PROMOTED');
+CREATE TYPE notification_status AS ENUM ('PENDING', 'SENT', 'FAILED', 'SKIPPED');
+CREATE TYPE notification_channel AS ENUM ('CUNEX', 'EMAIL', 'LINE');
+CREATE TYPE announcement_audience AS ENUM ('REGISTERED', 'CHECKED_IN', 'STAFF');
 
 CREATE TABLE users (
   id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
@@ -12,7 +21,62 @@ CREATE TABLE users (
   title_th text NOT NULL,
   firstname_en text NOT NULL,
   surname_en text NOT NULL,
-  title_en text NOT NULL
+  title_en text NOT NULL,
+  is_admin boolean NOT NULL DEFAULT false,
+  anonymised_at timestamptz
+);
+
+-- ref ids given to anonymised users, far outside the 8 and 10 digit CU ref ids
+CREATE SEQUENCE anonymised_ref_id_seq START WITH 1000000000000;
+
+CREATE TABLE event_series (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  name text NOT NULL,
+  organizer text NOT NULL,
+  description text,
+  location text NOT NULL,
+  rrule text NOT NULL,
+  first_start_time timestamptz NOT NULL,
+  first_end_time timestamptz NOT NULL,
+  attendence_type attendence_type NOT NULL,
+  allow_all_to_scan boolean NOT NULL,
+  evaluation_form text,
+  revealed_fields participant_data[] NOT NULL,
+  capacity integer CHECK (capacity > 0),
+  created_at timestamptz NOT NULL
+);
+
+CREATE TABLE event_series_users (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  role role NOT NULL,
+  user_id uuid NOT NULL,
+  series_id uuid NOT NULL,
+  CONSTRAINT unique_user_and_series UNIQUE (user_id, series_id),
+  CONSTRAINT fk_event_series_users_series
+    FOREIGN KEY (series_id) REFERENCES event_series (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_event_series_users_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- a club or unit that runs events, Name is what events show as their organizer
+CREATE TABLE organizers (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  name text NOT NULL,
+  description text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT unique_organizer_name UNIQUE (name)
+);
+
+CREATE TABLE organizer_members (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  role role NOT NULL,
+  user_id uuid NOT NULL,
+  organizer_id uuid NOT NULL,
+  CONSTRAINT unique_user_and_organizer UNIQUE (user_id, organizer_id),
+  CONSTRAINT fk_organizer_members_organizer
+    FOREIGN KEY (organizer_id) REFERENCES organizers (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_organizer_members_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
 );
 
 CREATE TABLE events (
@@ -26,7 +90,35 @@ CREATE TABLE events (
   attendence_type attendence_type NOT NULL,
   allow_all_to_scan boolean NOT NULL,
   evaluation_form text,
-  revealed_fields participant_data[] NOT NULL
+  revealed_fields participant_data[] NOT NULL,
+  capacity integer CHECK (capacity > 0),
+  series_id uuid,
+  series_detached boolean NOT NULL DEFAULT false,
+  organizer_id uuid,
+  privacy_notice text,
+  retention_days integer CHECK (retention_days > 0),
+  retention_purged_at timestamptz,
+  self_checkin_enabled boolean NOT NULL DEFAULT false,
+  self_checkin_center point,
+  self_checkin_radius_meters integer CHECK (self_checkin_radius_meters > 0),
+  self_checkin_rotation_seconds integer NOT NULL DEFAULT 30 CHECK (self_checkin_rotation_seconds > 0),
+  activity_hours numeric(5,2) CHECK (activity_hours > 0),
+  activity_category activity_category,
+  -- 'simple' config: no stemming, so Thai runs and English words are kept as typed
+  search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') ||
+    setweight(to_tsvector('simple', organizer), 'B') ||
+    setweight(to_tsvector('simple', location), 'C') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'D')
+  ) STORED,
+  CONSTRAINT self_checkin_geofence
+    CHECK (NOT self_checkin_enabled OR (self_checkin_center IS NOT NULL AND self_checkin_radius_meters IS NOT NULL)),
+  CONSTRAINT activity_hours_category
+    CHECK ((activity_hours IS NULL) = (activity_category IS NULL)),
+  CONSTRAINT fk_events_series
+    FOREIGN KEY (series_id) REFERENCES event_series (id) ON UPDATE CASCADE ON DELETE SET NULL,
+  CONSTRAINT fk_events_organizer
+    FOREIGN KEY (organizer_id) REFERENCES organizers (id) ON UPDATE CASCADE ON DELETE SET NULL
 );
 
 CREATE TABLE event_whitelists (
@@ -68,13 +160,17 @@ CREATE TABLE event_participants (
   comment text,
   participant_id uuid NOT NULL,
   organization text NOT NULL,
-  scanned_location point NOT NULL,
+  -- null for manual check-ins, which have no scanner device
+  scanned_location point,
   scanner_id uuid NULL,
+  idempotency_key text NULL,
+  method checkin_method NOT NULL DEFAULT 'SCAN',
   CONSTRAINT unique_event_and_participant UNIQUE (event_id, participant_id),
+  CONSTRAINT unique_event_and_idempotency_key UNIQUE (event_id, idempotency_key),
   CONSTRAINT fk_event_participants_event
     FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
   CONSTRAINT fk_event_participants_participant
-    FOREIGN KEY (participant_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (participant_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE RESTRICT,
   CONSTRAINT fk_event_participants_scanner
     FOREIGN KEY (scanner_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL
 );
@@ -91,8 +187,246 @@ CREATE TABLE event_users (
     FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
 );
 
+CREATE TABLE event_registrations (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  event_id uuid NOT NULL,
+  user_id uuid NOT NULL,
+  status registration_status NOT NULL,
+  registered_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL,
+  CONSTRAINT unique_event_and_user_registration UNIQUE (event_id, user_id),
+  CONSTRAINT fk_event_registrations_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_event_registrations_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE TABLE tags (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  slug text NOT NULL UNIQUE,
+  name_th text NOT NULL,
+  name_en text NOT NULL
+);
+
+CREATE TABLE event_tags (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  event_id uuid NOT NULL,
+  tag_id uuid NOT NULL,
+  CONSTRAINT unique_event_and_tag UNIQUE (event_id, tag_id),
+  CONSTRAINT fk_event_tags_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_event_tags_tag
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE event_templates (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  owner_id uuid NOT NULL,
+  name text NOT NULL,
+  data jsonb NOT NULL,
+  created_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL,
+  CONSTRAINT fk_event_templates_owner
+    FOREIGN KEY (owner_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE calendar_tokens (
+  user_id uuid PRIMARY KEY,
+  token_hash text NOT NULL UNIQUE,
+  created_at timestamptz NOT NULL,
+  CONSTRAINT fk_calendar_tokens_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE user_photos (
+  user_id uuid PRIMARY KEY,
+  object_key text NOT NULL,
+  width integer NOT NULL,
+  height integer NOT NULL,
+  updated_at timestamptz NOT NULL,
+  CONSTRAINT fk_user_photos_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE event_consents (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  event_id uuid NOT NULL,
+  user_id uuid NOT NULL,
+  notice_hash text NOT NULL,
+  accepted_via consent_source NOT NULL,
+  accepted_at timestamptz NOT NULL,
+  CONSTRAINT unique_event_and_user_consent UNIQUE (event_id, user_id),
+  CONSTRAINT fk_event_consents_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_event_consents_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- one row per event whose check-ins were anonymised, kept after the event is deleted
+CREATE TABLE retention_purges (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  event_id uuid,
+  event_name text NOT NULL,
+  event_end_time timestamptz NOT NULL,
+  retention_days integer NOT NULL,
+  participants bigint NOT NULL,
+  registrations bigint NOT NULL,
+  consents bigint NOT NULL,
+  purged_at timestamptz NOT NULL,
+  CONSTRAINT fk_retention_purges_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+-- a participant's own rating of an event they attended
+CREATE TABLE event_evaluations (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  event_id uuid NOT NULL,
+  user_id uuid NOT NULL,
+  rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  comment text,
+  submitted_at timestamptz NOT NULL,
+  CONSTRAINT unique_event_and_user_evaluation UNIQUE (event_id, user_id),
+  CONSTRAINT fk_event_evaluations_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_event_evaluations_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- rules without a row are enabled with their default threshold
+CREATE TABLE event_fraud_rules (
+  event_id uuid NOT NULL,
+  rule fraud_rule NOT NULL,
+  enabled boolean NOT NULL,
+  threshold double precision NOT NULL CHECK (threshold >= 0),
+  PRIMARY KEY (event_id, rule),
+  CONSTRAINT fk_event_fraud_rules_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- append-only, no foreign keys so entries outlive the events and users they mention
+CREATE TABLE audit_logs (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  actor_id uuid,
+  request_id text,
+  action text NOT NULL,
+  target_type text NOT NULL,
+  target_id text,
+  reason text,
+  event_id uuid,
+  series_id uuid,
+  before jsonb,
+  after jsonb,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- audit_logs is append-only, rows can be inserted but never changed or removed.
+-- Payloads therefore record people by user UUID and whitelists as counts, so
+-- erasure requests and retention purges have no ref IDs to remove here.
+CREATE FUNCTION audit_logs_append_only() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END
+$$;
+
+CREATE TRIGGER audit_logs_append_only
+  BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+  FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+
+-- author_id is kept when the author leaves the event's staff
+CREATE TABLE event_announcements (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  event_id uuid NOT NULL,
+  author_id uuid,
+  audience announcement_audience NOT NULL,
+  message text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_event_announcements_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_event_announcements_author
+    FOREIGN KEY (author_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+-- The outbox, at most one notification of each kind per user and event.
+-- Announcements are told apart by announcement_id, null for every other kind.
+CREATE TABLE notifications (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  user_id uuid NOT NULL,
+  event_id uuid NOT NULL,
+  kind notification_kind NOT NULL,
+  status notification_status NOT NULL DEFAULT 'PENDING',
+  attempts integer NOT NULL DEFAULT 0,
+  delivered_channels notification_channel[] NOT NULL DEFAULT '{}',
+  last_error text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  sent_at timestamptz,
+  announcement_id uuid,
+  CONSTRAINT unique_user_event_and_notification_kind UNIQUE NULLS NOT DISTINCT (user_id, event_id, kind, announcement_id),
+  CONSTRAINT fk_notifications_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_notifications_event
+    FOREIGN KEY (event_id) REFERENCES events (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_notifications_announcement
+    FOREIGN KEY (announcement_id) REFERENCES event_announcements (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- users without a row get every notification on NOTIFY_DEFAULT_CHANNELS
+CREATE TABLE notification_preferences (
+  user_id uuid PRIMARY KEY,
+  channels notification_channel[] NOT NULL,
+  email text,
+  line_user_id text,
+  event_reminders boolean NOT NULL DEFAULT true,
+  evaluation_notices boolean NOT NULL DEFAULT true,
+  staff_notices boolean NOT NULL DEFAULT true,
+  updated_at timestamptz NOT NULL,
+  CONSTRAINT fk_notification_preferences_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE idempotency_keys (
+  id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+  user_id uuid NOT NULL,
+  key text NOT NULL,
+  request_hash text NOT NULL,
+  response_status int,
+  response_body jsonb,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  expires_at timestamptz NOT NULL,
+  CONSTRAINT unique_user_and_idempotency_key UNIQUE (user_id, key),
+  CONSTRAINT fk_idempotency_keys_user
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
 CREATE INDEX idx_events_name_trgm ON events USING GIN (name gin_trgm_ops);
 CREATE INDEX idx_events_organizer_trgm ON events USING GIN (organizer gin_trgm_ops);
 CREATE INDEX idx_events_description_trgm ON events USING GIN (description gin_trgm_ops);
 CREATE INDEX idx_events_location_trgm ON events USING GIN (location gin_trgm_ops);
-CREATE INDEX idx_events_evaluation_form_trgm ON events USING GIN (evaluation_form gin_trgm_ops);
\ No newline at end of file
+CREATE INDEX idx_events_evaluation_form_trgm ON events USING GIN (evaluation_form gin_trgm_ops);
+CREATE INDEX idx_event_registrations_event_status_time ON event_registrations (event_id, status, registered_at);
+CREATE INDEX idx_tags_name_th_trgm ON tags USING GIN (name_th gin_trgm_ops);
+CREATE INDEX idx_tags_name_en_trgm ON tags USING GIN (name_en gin_trgm_ops);
+CREATE INDEX idx_event_tags_tag_id ON event_tags (tag_id);
+CREATE INDEX idx_events_search_vector ON events USING GIN (search_vector);
+CREATE INDEX idx_events_start_time_id ON events (start_time, id);
+CREATE INDEX idx_events_series_id_start_time ON events (series_id, start_time);
+CREATE INDEX idx_events_organizer_id_start_time ON events (organizer_id, start_time);
+CREATE INDEX idx_organizer_members_organizer_id ON organizer_members (organizer_id);
+CREATE INDEX idx_event_templates_owner_id ON event_templates (owner_id);
+-- events still holding personal data, checked by the retention job
+CREATE INDEX idx_events_retention_due ON events (end_time)
+  WHERE retention_days IS NOT NULL AND retention_purged_at IS NULL;
+CREATE INDEX idx_retention_purges_purged_at ON retention_purges (purged_at);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX idx_audit_logs_event_id_created_at ON audit_logs (event_id, created_at);
+CREATE INDEX idx_audit_logs_series_id_created_at ON audit_logs (series_id, created_at);
+CREATE INDEX idx_audit_logs_actor_id_created_at ON audit_logs (actor_id, created_at);
+-- a participant's check-ins across events, for the DISTANT_SCANS fraud rule
+CREATE INDEX idx_event_participants_participant_id_scanned_timestamp ON event_participants (participant_id, scanned_timestamp);
+-- recently ended events, for evaluation notices
+CREATE INDEX idx_events_end_time ON events (end_time);
+CREATE INDEX idx_notifications_pending ON notifications (next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_notifications_event_id ON notifications (event_id);
+-- an event's announcement feed and the rate limit count
+CREATE INDEX idx_event_announcements_event_id_created_at ON event_announcements (event_id, created_at);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);